@@ -0,0 +1,190 @@
+package main
+
+import (
+	"database/sql"
+)
+
+/**
+posts the question as the user, returns it with the similar questions it may duplicate.
+the duplicates are loaded first, so the question is not posted when they fail
+ */
+func PostQuestionAs(user User, text string, tags []string) (Question, []SearchHit, error) {
+	hits, err := SearchIdx.SimilarQuestions(text, 0, duplicateThreshold, 5)
+	if err != nil {
+		return Question{}, nil, err
+	}
+
+	duplicates, err := LoadSearchHits(hits)
+	if err != nil {
+		return Question{}, nil, err
+	}
+
+	var question Question = NewQuestion(text, user, tags)
+	err = question.Save()
+	if err != nil {
+		return question, nil, err
+	}
+
+	return question, duplicates, nil
+}
+
+/**
+answers the question if it accepts answers
+ */
+func PostAnswerAs(user User, questionId int64, text string) (Answer, error) {
+	var question Question
+
+	if question.Load(questionId) != nil {
+		return Answer{}, ActionError{Code: CodeQuestionNotFound, Field: "question_id"}
+	}
+
+	var answer Answer = NewAnswer(text, user, question)
+	err := Transaction(func(uow *UnitOfWork) error {
+		err := question.LoadForUpdate(uow, question.Id)
+		if err != nil {
+			return err
+		}
+
+		err = question.CheckWritable()
+		if err != nil {
+			return err
+		}
+
+		return answer.SaveIn(uow)
+	})
+
+	answer.User = &user
+
+	return answer, err
+}
+
+/**
+rates the answer by 1, once per user, if its question accepts rates
+ */
+func RateAnswerAs(user User, answerId int64) (Answer, error) {
+	var question Question
+	var answer Answer
+	var answerRate AnswerRate
+
+	if answer.Load(answerId) != nil {
+		return answer, ActionError{Code: CodeAnswerNotFound, Field: "answer_id"}
+	}
+
+	err := answerRate.LoadByAnswerAndUser(answer, user)
+	if err == nil {
+		return answer, ActionError{Code: CodeRateExists}
+	} else if err != sql.ErrNoRows {
+		return answer, err
+	}
+
+	answerRate = NewAnswerRate(1, user, answer)
+	err = Transaction(func(uow *UnitOfWork) error {
+		err := question.LoadForUpdate(uow, answer.QuestionId)
+		if err != nil {
+			return err
+		}
+
+		err = question.CheckWritable()
+		if err != nil {
+			return err
+		}
+
+		return answerRate.SaveIn(uow)
+	})
+
+	if err == ErrConflict {
+		return answer, ActionError{Code: CodeRateExists}
+	} else if err == sql.ErrNoRows {
+		return answer, ActionError{Code: CodeQuestionNotFound}
+	} else if err != nil {
+		return answer, err
+	}
+
+	answer.Score += answerRate.Rate
+
+	return answer, nil
+}
+
+/**
+accepts the answer of a question of the user
+ */
+func AcceptAnswerAs(user User, answerId int64) (Answer, error) {
+	var question Question
+	var answer Answer
+
+	if answer.Load(answerId) != nil || question.Load(answer.QuestionId) != nil {
+		return answer, ActionError{Code: CodeAnswerNotFound, Field: "answer_id"}
+	}
+
+	if question.UserId != user.Id {
+		return answer, ActionError{Code: CodeNotOwner}
+	}
+
+	return answer, question.AcceptAnswer(answer, user)
+}
+
+/**
+votes to close the question, duplicateOf is the canonical question of a duplicate vote
+ */
+func CloseVoteAs(user User, questionId int64, reason string, duplicateOf *int64) (Question, error) {
+	var duplicateOfId int64
+	var question Question
+	var vote QuestionVote
+
+	if question.Load(questionId) != nil {
+		return question, ActionError{Code: CodeQuestionNotFound, Field: "question_id"}
+	}
+
+	if duplicateOf != nil {
+		var canonical Question
+		if canonical.Load(*duplicateOf) != nil || canonical.Id == question.Id {
+			return question, ActionError{Code: CodeInvalidDuplicate, Field: "duplicate_of"}
+		}
+		duplicateOfId = canonical.Id
+		if canonical.DuplicateOfId != 0 {
+			duplicateOfId = canonical.DuplicateOfId
+		}
+	}
+
+	err := vote.LoadByQuestionAndUser(question, user, QuestionVoteClose)
+	if err == nil {
+		return question, ActionError{Code: CodeVoteExists}
+	} else if err != sql.ErrNoRows {
+		return question, err
+	}
+
+	vote = NewQuestionVote(QuestionVoteClose, reason, duplicateOfId, user, question)
+	_, err = question.AddVote(vote, user)
+	if err == ErrConflict {
+		return question, ActionError{Code: CodeVoteExists}
+	}
+
+	return question, err
+}
+
+/**
+votes to reopen the closed question
+ */
+func ReopenVoteAs(user User, questionId int64) (Question, error) {
+	var question Question
+	var vote QuestionVote
+
+	if question.Load(questionId) != nil {
+		return question, ActionError{Code: CodeQuestionNotFound, Field: "question_id"}
+	}
+
+	err := vote.LoadByQuestionAndUser(question, user, QuestionVoteReopen)
+	if err == nil {
+		return question, ActionError{Code: CodeVoteExists}
+	} else if err != sql.ErrNoRows {
+		return question, err
+	}
+
+	vote = NewQuestionVote(QuestionVoteReopen, "", 0, user, question)
+	_, err = question.AddVote(vote, user)
+	if err == ErrConflict {
+		return question, ActionError{Code: CodeVoteExists}
+	}
+
+	return question, err
+}
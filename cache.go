@@ -0,0 +1,264 @@
+package main
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	cacheCapacity = 10000
+
+	// lifetimes of the cached entries, writes invalidate them earlier
+	authCacheTTL     = time.Minute
+	topUsersCacheTTL = time.Minute * 5
+	listingCacheTTL  = time.Second * 30
+
+	// key prefixes of the cached entries, invalidation drops a whole prefix
+	cacheAuthPrefix     = "auth:"
+	cacheTopUsersPrefix = "top-users:"
+	cacheListingPrefix  = "questions:"
+)
+
+/**
+cache of the hot reads, values must be treated as read only by the callers
+ */
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration)
+	Delete(key string)
+	DeletePrefix(prefix string)
+	Clear()
+	Stats() CacheStats
+}
+
+type CacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Loads     int64 `json:"loads"`
+	Evictions int64 `json:"evictions"`
+	Size      int   `json:"size"`
+}
+
+var AppCache Cache = NewMemoryCache(cacheCapacity)
+
+// what the callers waiting for a load get when the load panics
+var ErrCacheLoadPanicked = errors.New("cache load panicked")
+
+type cacheEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+/**
+in-process cache evicting the least recently used entry once the capacity is reached,
+entries expire after their ttl
+ */
+type MemoryCache struct {
+	mu        sync.Mutex
+	capacity  int
+	entries   map[string]*list.Element
+	lru       *list.List
+	hits      int64
+	misses    int64
+	loads     int64
+	evictions int64
+}
+
+func NewMemoryCache(capacity int) *MemoryCache {
+	return &MemoryCache{capacity: capacity, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+func (c *MemoryCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	var entry *cacheEntry = el.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.removeElement(el)
+		c.misses++
+		return nil, false
+	}
+
+	c.lru.MoveToFront(el)
+	c.hits++
+
+	return entry.value, true
+}
+
+func (c *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loads++
+
+	if el, ok := c.entries[key]; ok {
+		el.Value = &cacheEntry{key: key, value: value, expiresAt: time.Now().Add(ttl)}
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, value: value, expiresAt: time.Now().Add(ttl)})
+
+	for c.lru.Len() > c.capacity {
+		c.removeElement(c.lru.Back())
+		c.evictions++
+	}
+}
+
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+func (c *MemoryCache) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(el)
+		}
+	}
+}
+
+func (c *MemoryCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+func (c *MemoryCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Loads:     c.loads,
+		Evictions: c.evictions,
+		Size:      c.lru.Len(),
+	}
+}
+
+func (c *MemoryCache) removeElement(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).key)
+}
+
+type cacheCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+var cacheCallsMu sync.Mutex
+var cacheCalls map[string]*cacheCall = make(map[string]*cacheCall)
+
+/**
+read-through: returns the cached value of the key or loads and caches it. concurrent misses of
+the same key wait for a single load instead of all hitting the database (stampede protection),
+failed loads are not cached
+ */
+func cached(key string, ttl time.Duration, load func() (interface{}, error)) (interface{}, error) {
+	if value, ok := AppCache.Get(key); ok {
+		return value, nil
+	}
+
+	cacheCallsMu.Lock()
+	if call, ok := cacheCalls[key]; ok {
+		cacheCallsMu.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+
+	var call *cacheCall = &cacheCall{done: make(chan struct{})}
+	cacheCalls[key] = call
+	cacheCallsMu.Unlock()
+
+	// the waiters are released even when load panics
+	defer func() {
+		cacheCallsMu.Lock()
+		delete(cacheCalls, key)
+		cacheCallsMu.Unlock()
+		close(call.done)
+	}()
+
+	call.err = ErrCacheLoadPanicked
+	call.value, call.err = load()
+	if call.err == nil {
+		AppCache.Set(key, call.value, ttl)
+	}
+
+	return call.value, call.err
+}
+
+/**
+drops the cached entries depending on a user: its sessions of the tokens, the top users and the listings embedding authors
+ */
+func invalidateUserCache(tokens ...string) {
+	for _, token := range tokens {
+		if token != "" {
+			AppCache.Delete(cacheAuthPrefix + token)
+		}
+	}
+
+	AppCache.DeletePrefix(cacheTopUsersPrefix)
+	AppCache.DeletePrefix(cacheListingPrefix)
+}
+
+/**
+drops the cached entries depending on questions and answers, views are left to expire with the ttl
+ */
+func invalidateListingCache() {
+	AppCache.DeletePrefix(cacheListingPrefix)
+}
+
+func invalidateAnswerCache() {
+	AppCache.DeletePrefix(cacheTopUsersPrefix)
+	AppCache.DeletePrefix(cacheListingPrefix)
+}
+
+/**
+key of a first page listing, only the first pages are cached since the deeper ones are rarely shared
+ */
+func listingCacheKey(filter QuestionFilter) string {
+	data, _ := json.Marshal(filter)
+	return cacheListingPrefix + string(data)
+}
+
+type cachedListing struct {
+	questions []Question
+	info      PageInfo
+}
+
+/**
+drops the cached entries the saved questions, answers and rates change
+ */
+func subscribeCache(bus *EventBus) {
+	bus.Subscribe("cache", func(e DomainEvent) error {
+		invalidateListingCache()
+		return nil
+	}, EventQuestionPosted, EventQuestionUpdated, EventAnswerRated)
+
+	bus.Subscribe("cache", func(e DomainEvent) error {
+		invalidateAnswerCache()
+		return nil
+	}, EventAnswerPosted, EventAnswerUpdated)
+}
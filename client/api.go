@@ -0,0 +1,348 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func (c *Client) CreateUser(ctx context.Context, email string, name string, password string) error {
+	var body map[string]string = map[string]string{"email": email, "name": name, "password": password}
+	return c.send(ctx, http.MethodPost, "/users", nil, body, nil, "")
+}
+
+/**
+top 5 users by answer count
+ */
+func (c *Client) TopUsers(ctx context.Context) ([]User, error) {
+	var users []User
+	err := c.do(ctx, http.MethodGet, "/users/top", nil, nil, &users)
+	return users, err
+}
+
+/**
+soft deletes the user, the logged in user itself or any user by a moderator
+ */
+func (c *Client) DeleteUser(ctx context.Context, email string) error {
+	return c.do(ctx, http.MethodDelete, "/users/"+url.PathEscape(email), nil, nil, nil)
+}
+
+func (c *Client) RestoreUser(ctx context.Context, email string) error {
+	return c.do(ctx, http.MethodPost, "/users/"+url.PathEscape(email)+"/restore", nil, nil, nil)
+}
+
+func (c *Client) DeletedUsers(ctx context.Context, limit int) *UserIterator {
+	return &UserIterator{pageIterator: newPageIterator(ctx, c, "/users/deleted", nil, limit)}
+}
+
+func (c *Client) Questions(ctx context.Context, filter QuestionFilter) *QuestionIterator {
+	var query url.Values = url.Values{}
+
+	setQuery(query, "sort", filter.Sort)
+	setQuery(query, "order", filter.Order)
+	setQuery(query, "tag", filter.Tag)
+	setQuery(query, "author", filter.Author)
+	setQuery(query, "from", filter.From)
+	setQuery(query, "to", filter.To)
+	if filter.Answered != nil {
+		query.Set("answered", strconv.FormatBool(*filter.Answered))
+	}
+	if filter.Accepted != nil {
+		query.Set("accepted", strconv.FormatBool(*filter.Accepted))
+	}
+
+	return &QuestionIterator{pageIterator: newPageIterator(ctx, c, "/questions", query, filter.Limit)}
+}
+
+func (c *Client) PostQuestion(ctx context.Context, question string, tags []string) (PostedQuestion, error) {
+	var posted PostedQuestion
+	var body map[string]interface{} = map[string]interface{}{"question": question, "tags": tags}
+
+	err := c.do(ctx, http.MethodPost, "/questions", nil, body, &posted)
+	return posted, err
+}
+
+func (c *Client) DeletedQuestions(ctx context.Context, limit int) *QuestionIterator {
+	return &QuestionIterator{pageIterator: newPageIterator(ctx, c, "/questions/deleted", nil, limit)}
+}
+
+func (c *Client) DeleteQuestion(ctx context.Context, questionId int64) error {
+	return c.do(ctx, http.MethodDelete, questionPath(questionId, ""), nil, nil, nil)
+}
+
+func (c *Client) RestoreQuestion(ctx context.Context, questionId int64) error {
+	return c.do(ctx, http.MethodPost, questionPath(questionId, "/restore"), nil, nil, nil)
+}
+
+func (c *Client) SimilarQuestions(ctx context.Context, questionId int64) ([]SearchHit, error) {
+	var hits []SearchHit
+	err := c.do(ctx, http.MethodGet, questionPath(questionId, "/similar"), nil, nil, &hits)
+	return hits, err
+}
+
+/**
+closes the question as a duplicate of duplicateOf, 0 reopens it
+ */
+func (c *Client) MarkDuplicate(ctx context.Context, questionId int64, duplicateOf int64) error {
+	var body map[string]interface{} = map[string]interface{}{"duplicate_of": duplicateOf}
+	return c.do(ctx, http.MethodPut, questionPath(questionId, "/duplicate"), nil, body, nil)
+}
+
+/**
+sets the state of the question, duplicateOf is only used closing it as a duplicate
+ */
+func (c *Client) ChangeQuestionState(ctx context.Context, questionId int64, state string, reason string, duplicateOf int64) (Question, error) {
+	var question Question
+	var body map[string]interface{} = map[string]interface{}{"state": state, "reason": reason}
+	if duplicateOf != 0 {
+		body["duplicate_of"] = duplicateOf
+	}
+
+	err := c.do(ctx, http.MethodPut, questionPath(questionId, "/state"), nil, body, &question)
+	return question, err
+}
+
+func (c *Client) CloseVote(ctx context.Context, questionId int64, reason string, duplicateOf int64) (Question, error) {
+	var question Question
+	var body map[string]interface{} = map[string]interface{}{"reason": reason}
+	if duplicateOf != 0 {
+		body["duplicate_of"] = duplicateOf
+	}
+
+	err := c.do(ctx, http.MethodPost, questionPath(questionId, "/close-votes"), nil, body, &question)
+	return question, err
+}
+
+func (c *Client) ReopenVote(ctx context.Context, questionId int64) (Question, error) {
+	var question Question
+	err := c.do(ctx, http.MethodPost, questionPath(questionId, "/reopen-votes"), nil, nil, &question)
+	return question, err
+}
+
+/**
+answers of the question by rate
+ */
+func (c *Client) Answers(ctx context.Context, questionId int64, limit int) *AnswerIterator {
+	return &AnswerIterator{pageIterator: newPageIterator(ctx, c, questionPath(questionId, "/answers"), nil, limit)}
+}
+
+/**
+answers the question, returns the id of the answer
+ */
+func (c *Client) PostAnswer(ctx context.Context, questionId int64, answer string) (int64, error) {
+	var created struct {
+		Id int64 `json:"id"`
+	}
+	var body map[string]interface{} = map[string]interface{}{"answer": answer}
+
+	err := c.do(ctx, http.MethodPost, questionPath(questionId, "/answers"), nil, body, &created)
+	return created.Id, err
+}
+
+func (c *Client) DeletedAnswers(ctx context.Context, limit int) *AnswerIterator {
+	return &AnswerIterator{pageIterator: newPageIterator(ctx, c, "/answers/deleted", nil, limit)}
+}
+
+func (c *Client) DeleteAnswer(ctx context.Context, answerId int64) error {
+	return c.do(ctx, http.MethodDelete, answerPath(answerId, ""), nil, nil, nil)
+}
+
+func (c *Client) RestoreAnswer(ctx context.Context, answerId int64) error {
+	return c.do(ctx, http.MethodPost, answerPath(answerId, "/restore"), nil, nil, nil)
+}
+
+/**
+rates the answer by 1, once per user
+ */
+func (c *Client) RateAnswer(ctx context.Context, answerId int64) error {
+	return c.do(ctx, http.MethodPost, answerPath(answerId, "/votes"), nil, nil, nil)
+}
+
+func (c *Client) AcceptAnswer(ctx context.Context, answerId int64) error {
+	return c.do(ctx, http.MethodPost, answerPath(answerId, "/accept"), nil, nil, nil)
+}
+
+func (c *Client) Search(ctx context.Context, filter SearchFilter) *SearchHitIterator {
+	var query url.Values = url.Values{}
+
+	setQuery(query, "query", filter.Query)
+	setQuery(query, "type", filter.Type)
+	setQuery(query, "author", filter.Author)
+	for _, tag := range filter.Tags {
+		query.Add("tags", tag)
+	}
+
+	return &SearchHitIterator{pageIterator: newPageIterator(ctx, c, "/search", query, filter.Limit)}
+}
+
+func (c *Client) ReindexSearch(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/search/reindex", nil, nil, nil)
+}
+
+func (c *Client) CacheStats(ctx context.Context) (CacheStats, error) {
+	var stats CacheStats
+	err := c.do(ctx, http.MethodGet, "/cache/stats", nil, nil, &stats)
+	return stats, err
+}
+
+/**
+registers a webhook for the events, on questions in the tags or on every question without tags.
+an empty secret lets the server generate one, it is in the returned webhook
+ */
+func (c *Client) CreateWebhook(ctx context.Context, endpoint string, events []string, tags []string, secret string) (Webhook, error) {
+	var webhook Webhook
+	var body map[string]interface{} = map[string]interface{}{"url": endpoint, "events": events, "tags": tags}
+	if secret != "" {
+		body["secret"] = secret
+	}
+
+	err := c.do(ctx, http.MethodPost, "/webhooks", nil, body, &webhook)
+	return webhook, err
+}
+
+func (c *Client) Webhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := c.do(ctx, http.MethodGet, "/webhooks", nil, nil, &webhooks)
+	return webhooks, err
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, webhookId int64) error {
+	return c.do(ctx, http.MethodDelete, webhookPath(webhookId, ""), nil, nil, nil)
+}
+
+func (c *Client) WebhookDeliveries(ctx context.Context, webhookId int64, limit int) *DeliveryIterator {
+	return &DeliveryIterator{pageIterator: newPageIterator(ctx, c, webhookPath(webhookId, "/deliveries"), nil, limit)}
+}
+
+/**
+delivers the payload of the delivery again, returns the new delivery after its first attempt
+ */
+func (c *Client) ReplayDelivery(ctx context.Context, webhookId int64, deliveryId int64) (WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	err := c.do(ctx, http.MethodPost, webhookPath(webhookId, fmt.Sprintf("/deliveries/%d/replay", deliveryId)), nil, nil, &delivery)
+	return delivery, err
+}
+
+func (c *Client) FollowQuestion(ctx context.Context, questionId int64) (Follow, error) {
+	var follow Follow
+	err := c.do(ctx, http.MethodPut, questionPath(questionId, "/follow"), nil, nil, &follow)
+	return follow, err
+}
+
+func (c *Client) UnfollowQuestion(ctx context.Context, questionId int64) error {
+	return c.do(ctx, http.MethodDelete, questionPath(questionId, "/follow"), nil, nil, nil)
+}
+
+func (c *Client) FollowTag(ctx context.Context, tag string) (Follow, error) {
+	var follow Follow
+	err := c.do(ctx, http.MethodPut, "/tags/"+url.PathEscape(tag)+"/follow", nil, nil, &follow)
+	return follow, err
+}
+
+func (c *Client) UnfollowTag(ctx context.Context, tag string) error {
+	return c.do(ctx, http.MethodDelete, "/tags/"+url.PathEscape(tag)+"/follow", nil, nil, nil)
+}
+
+func (c *Client) FollowUser(ctx context.Context, email string) (Follow, error) {
+	var follow Follow
+	err := c.do(ctx, http.MethodPut, "/users/"+url.PathEscape(email)+"/follow", nil, nil, &follow)
+	return follow, err
+}
+
+func (c *Client) UnfollowUser(ctx context.Context, email string) error {
+	return c.do(ctx, http.MethodDelete, "/users/"+url.PathEscape(email)+"/follow", nil, nil, nil)
+}
+
+func (c *Client) Follows(ctx context.Context, limit int) *FollowIterator {
+	return &FollowIterator{pageIterator: newPageIterator(ctx, c, "/follows", nil, limit)}
+}
+
+/**
+new questions and answers of everything the logged in user follows, latest first
+ */
+func (c *Client) Feed(ctx context.Context, limit int) *FeedIterator {
+	return &FeedIterator{pageIterator: newPageIterator(ctx, c, "/feed", nil, limit)}
+}
+
+/**
+notifications of the user, latest first, only the unread ones when unread is set
+ */
+func (c *Client) Notifications(ctx context.Context, unread bool, limit int) *NotificationIterator {
+	var query url.Values = url.Values{}
+	if unread {
+		query.Set("unread", "true")
+	}
+
+	return &NotificationIterator{pageIterator: newPageIterator(ctx, c, "/notifications", query, limit)}
+}
+
+func (c *Client) UnreadNotifications(ctx context.Context) (UnreadCount, error) {
+	var count UnreadCount
+	err := c.do(ctx, http.MethodGet, "/notifications/unread-count", nil, nil, &count)
+	return count, err
+}
+
+func (c *Client) MarkAllNotificationsRead(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/notifications/read", nil, nil, nil)
+}
+
+func (c *Client) MarkNotification(ctx context.Context, notificationId int64, read bool) (Notification, error) {
+	var notification Notification
+	var body map[string]interface{} = map[string]interface{}{"read": read}
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/notifications/%d", notificationId), nil, body, &notification)
+	return notification, err
+}
+
+/**
+notification types of the user, answer, vote, accept and mention, with whether they are on
+ */
+func (c *Client) NotificationPreferences(ctx context.Context) (map[string]bool, error) {
+	var preferences map[string]bool
+	err := c.do(ctx, http.MethodGet, "/notifications/preferences", nil, nil, &preferences)
+	return preferences, err
+}
+
+/**
+turns the notification types in changes on or off, the others are left as they are
+ */
+func (c *Client) SetNotificationPreferences(ctx context.Context, changes map[string]bool) (map[string]bool, error) {
+	var preferences map[string]bool
+	err := c.do(ctx, http.MethodPut, "/notifications/preferences", nil, changes, &preferences)
+	return preferences, err
+}
+
+/**
+how often the logged in user gets the email digest, one of DigestOff, DigestDaily and DigestWeekly
+ */
+func (c *Client) DigestFrequency(ctx context.Context) (string, error) {
+	var settings struct {
+		Frequency string `json:"frequency"`
+	}
+	err := c.do(ctx, http.MethodGet, "/digests/settings", nil, nil, &settings)
+	return settings.Frequency, err
+}
+
+func (c *Client) SetDigestFrequency(ctx context.Context, frequency string) error {
+	var body map[string]interface{} = map[string]interface{}{"frequency": frequency}
+	return c.do(ctx, http.MethodPut, "/digests/settings", nil, body, nil)
+}
+
+func questionPath(questionId int64, sub string) string {
+	return fmt.Sprintf("/questions/%d%s", questionId, sub)
+}
+
+func answerPath(answerId int64, sub string) string {
+	return fmt.Sprintf("/answers/%d%s", answerId, sub)
+}
+
+func webhookPath(webhookId int64, sub string) string {
+	return fmt.Sprintf("/webhooks/%d%s", webhookId, sub)
+}
+
+func setQuery(query url.Values, key string, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
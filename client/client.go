@@ -0,0 +1,202 @@
+/**
+go client of the questions api (v1). the client logs in by the credentials given to Login and
+logs in again when the token expires, errors of the api are returned as *APIError
+ */
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	apiVersion = "v1"
+
+	// format of the token expiration in the login response, in the time zone of the server
+	expirationFormat = "2006-01-02 15:04:05"
+
+	// the token is renewed this long before its expiration
+	expirationMargin = time.Minute
+)
+
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+
+	mu         sync.Mutex
+	email      string
+	password   string
+	token      string
+	expiration time.Time
+}
+
+/**
+client of the api at baseURL, http://localhost:8080 for example
+ */
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), HTTPClient: http.DefaultClient}
+}
+
+/**
+logs in and keeps the credentials to log in again when the token expires or is rejected
+ */
+func (c *Client) Login(ctx context.Context, email string, password string) error {
+	c.mu.Lock()
+	c.email, c.password = email, password
+	c.mu.Unlock()
+
+	return c.login(ctx)
+}
+
+/**
+uses the token instead of logging in, the token is not renewed
+ */
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.email, c.password = "", ""
+	c.token, c.expiration = token, time.Time{}
+}
+
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.token
+}
+
+func (c *Client) login(ctx context.Context) error {
+	c.mu.Lock()
+	var body map[string]string = map[string]string{"email": c.email, "password": c.password}
+	c.mu.Unlock()
+
+	var resp struct {
+		Token      string `json:"token"`
+		Expiration string `json:"expiration"`
+	}
+
+	err := c.send(ctx, http.MethodPost, "/sessions", nil, body, &resp, "")
+	if err != nil {
+		return err
+	}
+
+	// the expiration is only a hint as the server time zone is not known, a rejected token is renewed too
+	expiration, err := time.ParseInLocation(expirationFormat, resp.Expiration, time.Local)
+	if err != nil {
+		expiration = time.Time{}
+	}
+
+	c.mu.Lock()
+	c.token, c.expiration = resp.Token, expiration
+	c.mu.Unlock()
+
+	return nil
+}
+
+/**
+token of the next request, logs in first when the token is missing or about to expire
+ */
+func (c *Client) currentToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	var canLogin bool = c.email != ""
+	var expired bool = c.token == "" || (!c.expiration.IsZero() && time.Now().Add(expirationMargin).After(c.expiration))
+	var token string = c.token
+	c.mu.Unlock()
+
+	if !canLogin || !expired {
+		return token, nil
+	}
+
+	err := c.login(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return c.Token(), nil
+}
+
+/**
+sends an authenticated request, a rejected token is renewed and the request sent once more
+ */
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}) error {
+	token, err := c.currentToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = c.send(ctx, method, path, query, body, out, token)
+
+	c.mu.Lock()
+	var canLogin bool = c.email != ""
+	c.mu.Unlock()
+
+	if IsCode(err, CodeAccessForbidden) && canLogin {
+		err = c.login(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.send(ctx, method, path, query, body, out, c.Token())
+	}
+
+	return err
+}
+
+func (c *Client) send(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}, token string) error {
+	var reader io.Reader
+	var u string = c.BaseURL + "/" + apiVersion + path
+
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("access-token", token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return decodeError(resp)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(out)
+	if err != nil {
+		return fmt.Errorf("decoding the response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
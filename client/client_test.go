@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+/**
+api of a single user whose tokens are valid until they are revoked or replaced by the next login
+ */
+type sessionServer struct {
+	mu       sync.Mutex
+	logins   int
+	token    string
+	lifetime time.Duration
+	requests []string
+}
+
+func (s *sessionServer) revoke() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = ""
+}
+
+func (s *sessionServer) seen() (int, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.logins, append([]string{}, s.requests...)
+}
+
+func (s *sessionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Method == http.MethodPost && r.URL.Path == "/v1/sessions" {
+		var body map[string]string
+		if json.NewDecoder(r.Body).Decode(&body) != nil || body["password"] != "secret" {
+			writeProblem(w, http.StatusUnauthorized, CodeInvalidCredentials)
+			return
+		}
+
+		s.logins++
+		s.token = fmt.Sprintf("token-%d", s.logins)
+		json.NewEncoder(w).Encode(map[string]string{"token": s.token, "expiration": time.Now().Add(s.lifetime).Format(expirationFormat)})
+		return
+	}
+
+	s.requests = append(s.requests, r.Header.Get("access-token"))
+	if s.token == "" || r.Header.Get("access-token") != s.token {
+		writeProblem(w, http.StatusForbidden, CodeAccessForbidden)
+		return
+	}
+
+	json.NewEncoder(w).Encode([]User{{Name: "ada", Email: "ada@example.com"}})
+}
+
+func writeProblem(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(APIError{Status: status, Code: code, Title: code})
+}
+
+func newSessionClient(t *testing.T, lifetime time.Duration) (*Client, *sessionServer) {
+	var server *sessionServer = &sessionServer{lifetime: lifetime}
+	var ts *httptest.Server = httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	var c *Client = New(ts.URL)
+	err := c.Login(context.Background(), "ada@example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c, server
+}
+
+func TestRejectedTokenIsRenewed(t *testing.T) {
+	c, server := newSessionClient(t, time.Hour)
+	server.revoke()
+
+	users, err := c.TopUsers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logins, requests := server.seen()
+	if len(users) != 1 || logins != 2 {
+		t.Fatalf("got %d users after %d logins, want 1 after 2", len(users), logins)
+	}
+	if len(requests) != 2 || requests[0] != "token-1" || requests[1] != "token-2" {
+		t.Fatalf("requests were sent with %v, want [token-1 token-2]", requests)
+	}
+}
+
+func TestExpiringTokenIsRenewedBeforeTheRequest(t *testing.T) {
+	c, server := newSessionClient(t, expirationMargin/2)
+
+	_, err := c.TopUsers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logins, requests := server.seen()
+	if logins != 2 || len(requests) != 1 || requests[0] != "token-2" {
+		t.Fatalf("%d logins, requests sent with %v, want 2 logins and [token-2]", logins, requests)
+	}
+}
+
+func TestSetTokenIsNotRenewed(t *testing.T) {
+	var server *sessionServer = &sessionServer{lifetime: time.Hour}
+	var ts *httptest.Server = httptest.NewServer(server)
+	defer ts.Close()
+
+	var c *Client = New(ts.URL)
+	c.SetToken("stale")
+
+	_, err := c.TopUsers(context.Background())
+	if !errors.Is(err, ErrAccessForbidden) {
+		t.Fatalf("got %v, want %v", err, ErrAccessForbidden)
+	}
+	if logins, _ := server.seen(); logins != 0 {
+		t.Fatalf("%d logins, want none", logins)
+	}
+}
+
+func TestFailedLoginIsInvalidCredentials(t *testing.T) {
+	var ts *httptest.Server = httptest.NewServer(&sessionServer{lifetime: time.Hour})
+	defer ts.Close()
+
+	err := New(ts.URL).Login(context.Background(), "ada@example.com", "wrong")
+	if !errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrAccessForbidden) {
+		t.Fatalf("got %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+func TestProblemIsDecoded(t *testing.T) {
+	var ts *httptest.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": http.StatusUnprocessableEntity,
+			"code":   CodeValidationFailed,
+			"title":  "validation failed",
+			"errors": []FieldError{{Field: "tags[0]", Rule: "max", Message: "must be at most 64 characters"}},
+		})
+	}))
+	defer ts.Close()
+
+	var c *Client = New(ts.URL)
+	c.SetToken("token")
+
+	_, err := c.PostQuestion(context.Background(), "why?", []string{"tag"})
+	if !errors.Is(err, ErrValidationFailed) || !IsCode(err, CodeValidationFailed) {
+		t.Fatalf("got %v, want %v", err, ErrValidationFailed)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %T, want *APIError", err)
+	}
+	if apiErr.Status != http.StatusUnprocessableEntity || apiErr.RequestId != "req-1" || len(apiErr.Errors) != 1 || apiErr.Errors[0].Field != "tags[0]" {
+		t.Fatalf("decoded %+v", apiErr)
+	}
+}
+
+func TestResponseWithoutProblemGetsTheStatusText(t *testing.T) {
+	var ts *httptest.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream down", http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	var c *Client = New(ts.URL)
+	c.SetToken("token")
+
+	err := c.DeleteQuestion(context.Background(), 1)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want *APIError", err)
+	}
+	if apiErr.Status != http.StatusBadGateway || apiErr.Title != http.StatusText(http.StatusBadGateway) || apiErr.Code != "" {
+		t.Fatalf("decoded %+v", apiErr)
+	}
+	if errors.Is(err, ErrQuestionNotFound) {
+		t.Fatalf("%v matches %v", err, ErrQuestionNotFound)
+	}
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+)
+
+// codes of the error catalog of the api
+const (
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeRouteNotFound        = "route_not_found"
+	CodeInternal             = "internal_error"
+	CodeMalformedBody        = "malformed_body"
+	CodeBodyTooLarge         = "body_too_large"
+	CodeValidationFailed     = "validation_failed"
+	CodeAccessForbidden      = "access_forbidden"
+	CodeModeratorRequired    = "moderator_required"
+	CodeNotOwner             = "not_owner"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeEmailTaken           = "email_taken"
+	CodeRateExists           = "rate_exists"
+	CodeVoteExists           = "vote_exists"
+	CodeConflict             = "conflict"
+	CodeUserNotFound         = "user_not_found"
+	CodeQuestionNotFound     = "question_not_found"
+	CodeAnswerNotFound       = "answer_not_found"
+	CodeInvalidCursor        = "invalid_cursor"
+	CodeInvalidDuplicate     = "invalid_duplicate"
+	CodeInvalidTransition    = "invalid_transition"
+	CodeInvalidCloseReason   = "invalid_close_reason"
+	CodeMissingDuplicate     = "missing_duplicate"
+	CodeQuestionNotWritable  = "question_not_writable"
+	CodeWebhookNotFound      = "webhook_not_found"
+	CodeDeliveryNotFound     = "delivery_not_found"
+	CodeNotificationNotFound = "notification_not_found"
+	CodeInvalidUnsubscribe   = "invalid_unsubscribe_token"
+)
+
+// errors to compare by errors.Is, an *APIError matches the one of its code
+var (
+	ErrAccessForbidden      = &APIError{Code: CodeAccessForbidden}
+	ErrModeratorRequired    = &APIError{Code: CodeModeratorRequired}
+	ErrNotOwner             = &APIError{Code: CodeNotOwner}
+	ErrInvalidCredentials   = &APIError{Code: CodeInvalidCredentials}
+	ErrValidationFailed     = &APIError{Code: CodeValidationFailed}
+	ErrEmailTaken           = &APIError{Code: CodeEmailTaken}
+	ErrRateExists           = &APIError{Code: CodeRateExists}
+	ErrVoteExists           = &APIError{Code: CodeVoteExists}
+	ErrUserNotFound         = &APIError{Code: CodeUserNotFound}
+	ErrQuestionNotFound     = &APIError{Code: CodeQuestionNotFound}
+	ErrAnswerNotFound       = &APIError{Code: CodeAnswerNotFound}
+	ErrInvalidTransition    = &APIError{Code: CodeInvalidTransition}
+	ErrQuestionNotWritable  = &APIError{Code: CodeQuestionNotWritable}
+	ErrWebhookNotFound      = &APIError{Code: CodeWebhookNotFound}
+	ErrDeliveryNotFound     = &APIError{Code: CodeDeliveryNotFound}
+	ErrNotificationNotFound = &APIError{Code: CodeNotificationNotFound}
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+/**
+problem details of a failed request, Code is one of the Code constants
+ */
+type APIError struct {
+	Status    int          `json:"status"`
+	Code      string       `json:"code"`
+	Title     string       `json:"title"`
+	Detail    string       `json:"detail,omitempty"`
+	Field     string       `json:"field,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	RequestId string       `json:"request_id,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	var msg string = e.Code + ": " + e.Title
+
+	if e.Detail != "" {
+		msg += ", " + e.Detail
+	}
+	if e.Field != "" {
+		msg += " (" + e.Field + ")"
+	}
+	for _, fe := range e.Errors {
+		msg += ", " + fe.Field + " " + fe.Message
+	}
+
+	return msg
+}
+
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	return ok && t.Code == e.Code
+}
+
+/**
+tells whether err is an api error of the code
+ */
+func IsCode(err error, code string) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+/**
+api error of the response, one without problem details gets the http status text as title
+ */
+func decodeError(resp *http.Response) error {
+	var apiErr *APIError = &APIError{}
+
+	data, _ := ioutil.ReadAll(resp.Body)
+	if json.Unmarshal(data, apiErr) != nil || apiErr.Code == "" {
+		apiErr = &APIError{Title: http.StatusText(resp.StatusCode)}
+	}
+
+	apiErr.Status = resp.StatusCode
+	if apiErr.RequestId == "" {
+		apiErr.RequestId = resp.Header.Get("X-Request-Id")
+	}
+
+	return apiErr
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type page struct {
+	Items []json.RawMessage `json:"items"`
+	Next  string            `json:"next"`
+	Total *int64            `json:"total"`
+}
+
+/**
+walks a listing page by page following the next cursors, the next page is fetched
+when the items of the current one have been used up
+ */
+type pageIterator struct {
+	ctx     context.Context
+	client  *Client
+	path    string
+	query   url.Values
+	cursor  string
+	items   []json.RawMessage
+	current json.RawMessage
+	total   *int64
+	started bool
+	done    bool
+	err     error
+}
+
+func newPageIterator(ctx context.Context, c *Client, path string, query url.Values, limit int) pageIterator {
+	if query == nil {
+		query = url.Values{}
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	return pageIterator{ctx: ctx, client: c, path: path, query: query}
+}
+
+func (it *pageIterator) next(dst interface{}) bool {
+	for len(it.items) == 0 {
+		if it.err != nil || (it.started && it.cursor == "") {
+			return false
+		}
+
+		if it.cursor != "" {
+			it.query.Set("cursor", it.cursor)
+		}
+
+		var p page
+		it.err = it.client.do(it.ctx, http.MethodGet, it.path, it.query, nil, &p)
+		if it.err != nil {
+			return false
+		}
+
+		it.started = true
+		it.items, it.cursor = p.Items, p.Next
+		if p.Total != nil {
+			it.total = p.Total
+		}
+	}
+
+	it.current, it.items = it.items[0], it.items[1:]
+	it.err = json.Unmarshal(it.current, dst)
+
+	return it.err == nil
+}
+
+/**
+error that stopped the iteration, nil when the listing has been walked to the end
+ */
+func (it *pageIterator) Err() error {
+	return it.err
+}
+
+/**
+total count of the listing when the server returns it
+ */
+func (it *pageIterator) Total() (int64, bool) {
+	if it.total == nil {
+		return 0, false
+	}
+
+	return *it.total, true
+}
+
+/**
+	it := c.Questions(ctx, client.QuestionFilter{Sort: "score"})
+	for it.Next() {
+		q := it.Question()
+	}
+	if it.Err() != nil { ... }
+ */
+type QuestionIterator struct {
+	pageIterator
+	question Question
+}
+
+func (it *QuestionIterator) Next() bool {
+	it.question = Question{}
+	return it.next(&it.question)
+}
+
+func (it *QuestionIterator) Question() Question {
+	return it.question
+}
+
+type AnswerIterator struct {
+	pageIterator
+	answer Answer
+}
+
+func (it *AnswerIterator) Next() bool {
+	it.answer = Answer{}
+	return it.next(&it.answer)
+}
+
+func (it *AnswerIterator) Answer() Answer {
+	return it.answer
+}
+
+type UserIterator struct {
+	pageIterator
+	user User
+}
+
+func (it *UserIterator) Next() bool {
+	it.user = User{}
+	return it.next(&it.user)
+}
+
+func (it *UserIterator) User() User {
+	return it.user
+}
+
+type SearchHitIterator struct {
+	pageIterator
+	hit SearchHit
+}
+
+func (it *SearchHitIterator) Next() bool {
+	it.hit = SearchHit{}
+	return it.next(&it.hit)
+}
+
+func (it *SearchHitIterator) Hit() SearchHit {
+	return it.hit
+}
+
+type DeliveryIterator struct {
+	pageIterator
+	delivery WebhookDelivery
+}
+
+func (it *DeliveryIterator) Next() bool {
+	it.delivery = WebhookDelivery{}
+	return it.next(&it.delivery)
+}
+
+func (it *DeliveryIterator) Delivery() WebhookDelivery {
+	return it.delivery
+}
+
+type FollowIterator struct {
+	pageIterator
+	follow Follow
+}
+
+func (it *FollowIterator) Next() bool {
+	it.follow = Follow{}
+	return it.next(&it.follow)
+}
+
+func (it *FollowIterator) Follow() Follow {
+	return it.follow
+}
+
+type FeedIterator struct {
+	pageIterator
+	item FeedItem
+}
+
+func (it *FeedIterator) Next() bool {
+	it.item = FeedItem{}
+	return it.next(&it.item)
+}
+
+func (it *FeedIterator) Item() FeedItem {
+	return it.item
+}
+
+type NotificationIterator struct {
+	pageIterator
+	notification Notification
+}
+
+func (it *NotificationIterator) Next() bool {
+	it.notification = Notification{}
+	return it.next(&it.notification)
+}
+
+func (it *NotificationIterator) Notification() Notification {
+	return it.notification
+}
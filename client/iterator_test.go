@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+/**
+listing of the questions 1 to count in pages cut by the limit, the cursor is the offset.
+failAt makes the request of the page at the offset fail
+ */
+type listingServer struct {
+	mu      sync.Mutex
+	count   int
+	failAt  int
+	queries []string
+}
+
+func (s *listingServer) seen() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.queries...)
+}
+
+func (s *listingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.queries = append(s.queries, r.URL.RawQuery)
+	s.mu.Unlock()
+
+	if r.URL.Path != "/v1/questions" {
+		writeProblem(w, http.StatusNotFound, CodeRouteNotFound)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+
+	if s.failAt > 0 && offset == s.failAt {
+		writeProblem(w, http.StatusInternalServerError, CodeInternal)
+		return
+	}
+
+	var p map[string]interface{} = map[string]interface{}{"total": s.count}
+	var items []Question = []Question{}
+	for id := offset + 1; id <= s.count && id <= offset+limit; id++ {
+		items = append(items, Question{Id: int64(id), Question: "question " + strconv.Itoa(id)})
+	}
+	p["items"] = items
+
+	if offset+limit < s.count {
+		p["next"] = strconv.Itoa(offset + limit)
+	}
+
+	json.NewEncoder(w).Encode(p)
+}
+
+func newListingClient(t *testing.T, server *listingServer) *Client {
+	var ts *httptest.Server = httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	var c *Client = New(ts.URL)
+	c.SetToken("token")
+
+	return c
+}
+
+func TestIteratorFollowsTheCursors(t *testing.T) {
+	var server *listingServer = &listingServer{count: 5}
+	var it *QuestionIterator = newListingClient(t, server).Questions(context.Background(), QuestionFilter{Sort: "score", Limit: 2})
+
+	var ids []int64
+	for it.Next() {
+		ids = append(ids, it.Question().Id)
+	}
+
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if len(ids) != 5 || ids[0] != 1 || ids[4] != 5 {
+		t.Fatalf("iterated %v, want 1 to 5", ids)
+	}
+
+	var queries []string = server.seen()
+	if len(queries) != 3 || queries[0] != "limit=2&sort=score" || queries[2] != "cursor=4&limit=2&sort=score" {
+		t.Fatalf("requested %q, want 3 pages with the limit and the sort", queries)
+	}
+
+	total, ok := it.Total()
+	if !ok || total != 5 {
+		t.Fatalf("total %d %v, want 5", total, ok)
+	}
+}
+
+func TestIteratorOfAnEmptyListing(t *testing.T) {
+	var server *listingServer = &listingServer{}
+	var it *QuestionIterator = newListingClient(t, server).Questions(context.Background(), QuestionFilter{Limit: 10})
+
+	if it.Next() || it.Next() {
+		t.Fatalf("iterated %+v of an empty listing", it.Question())
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if queries := server.seen(); len(queries) != 1 {
+		t.Fatalf("requested %q, want a single page", queries)
+	}
+}
+
+func TestIteratorStopsAtAFailedPage(t *testing.T) {
+	var server *listingServer = &listingServer{count: 5, failAt: 2}
+	var it *QuestionIterator = newListingClient(t, server).Questions(context.Background(), QuestionFilter{Limit: 2})
+
+	var count int
+	for it.Next() {
+		count++
+	}
+
+	if count != 2 {
+		t.Fatalf("iterated %d questions, want the 2 of the first page", count)
+	}
+	if !IsCode(it.Err(), CodeInternal) {
+		t.Fatalf("got %v, want %s", it.Err(), CodeInternal)
+	}
+	if it.Next() {
+		t.Fatal("iterated on after the error")
+	}
+	if queries := server.seen(); len(queries) != 2 {
+		t.Fatalf("requested %q, want 2 pages", queries)
+	}
+}
+
+func TestIteratorDecodesEveryItemType(t *testing.T) {
+	var ts *httptest.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": []map[string]interface{}{{"id": 7, "type": "answer", "question_id": 3}}})
+	}))
+	defer ts.Close()
+
+	var c *Client = New(ts.URL)
+	c.SetToken("token")
+
+	var feed *FeedIterator = c.Feed(context.Background(), 1)
+	if !feed.Next() || feed.Item().Id != 7 || feed.Item().QuestionId != 3 {
+		t.Fatalf("feed item %+v, %v", feed.Item(), feed.Err())
+	}
+	if feed.Next() || feed.Err() != nil {
+		t.Fatalf("iterated on or failed: %v", feed.Err())
+	}
+
+	var answers *AnswerIterator = c.Answers(context.Background(), 3, 1)
+	if !answers.Next() || answers.Answer().Id != 7 {
+		t.Fatalf("answer %+v, %v", answers.Answer(), answers.Err())
+	}
+
+	var broken *httptest.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items": [`))
+	}))
+	defer broken.Close()
+
+	c = New(broken.URL)
+	c.SetToken("token")
+
+	var users *UserIterator = c.DeletedUsers(context.Background(), 1)
+	if users.Next() || users.Err() == nil || IsCode(users.Err(), CodeInternal) {
+		t.Fatalf("iterated a broken page: %v", users.Err())
+	}
+}
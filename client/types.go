@@ -0,0 +1,185 @@
+package client
+
+import (
+	"time"
+)
+
+const (
+	QuestionOpen     = "open"
+	QuestionClosed   = "closed"
+	QuestionLocked   = "locked"
+	QuestionArchived = "archived"
+
+	CloseReasonDuplicate    = "duplicate"
+	CloseReasonOffTopic     = "off-topic"
+	CloseReasonUnclear      = "unclear"
+	CloseReasonTooBroad     = "too-broad"
+	CloseReasonOpinionBased = "opinion-based"
+
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+type User struct {
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	AnswerCount int64      `json:"answer_count"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+type Question struct {
+	Id               int64      `json:"id"`
+	Question         string     `json:"question"`
+	DuplicateOfId    int64      `json:"duplicate_of_id,omitempty"`
+	AcceptedAnswerId int64      `json:"accepted_answer_id,omitempty"`
+	Views            int64      `json:"views"`
+	AnswerCount      int64      `json:"answer_count"`
+	Score            int64      `json:"score"`
+	CreatedAt        time.Time  `json:"created_at"`
+	LastActivityAt   time.Time  `json:"last_activity_at"`
+	State            string     `json:"state"`
+	CloseReason      string     `json:"close_reason,omitempty"`
+	StateChangedAt   *time.Time `json:"state_changed_at,omitempty"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+	User             *User      `json:"user"`
+	Answers          []Answer   `json:"answers"`
+	Tags             []string   `json:"tags"`
+}
+
+type Answer struct {
+	Id        int64      `json:"Id"`
+	Answer    string     `json:"answer"`
+	Score     int64      `json:"score"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	User      *User      `json:"user"`
+}
+
+/**
+search result, Kind is question or answer and tells which of Question and Answer is set
+ */
+type SearchHit struct {
+	Kind       string    `json:"type"`
+	Id         int64     `json:"id"`
+	QuestionId int64     `json:"question_id"`
+	Score      float64   `json:"score"`
+	Question   *Question `json:"question,omitempty"`
+	Answer     *Answer   `json:"answer,omitempty"`
+}
+
+/**
+created question with the similar questions it may duplicate
+ */
+type PostedQuestion struct {
+	Id         int64       `json:"id"`
+	Duplicates []SearchHit `json:"duplicates"`
+}
+
+type CacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Loads     int64 `json:"loads"`
+	Evictions int64 `json:"evictions"`
+	Size      int   `json:"size"`
+}
+
+/**
+registered webhook, Secret is only set in the response of CreateWebhook
+ */
+type Webhook struct {
+	Id        int64     `json:"id"`
+	Url       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Tags      []string  `json:"tags"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+/**
+logged delivery of an event to a webhook, Status is pending, succeeded or failed
+ */
+type WebhookDelivery struct {
+	Id            int64      `json:"id"`
+	WebhookId     int64      `json:"webhook_id"`
+	Event         string     `json:"event"`
+	Payload       string     `json:"payload"`
+	Status        string     `json:"status"`
+	Attempts      int64      `json:"attempts"`
+	StatusCode    int64      `json:"status_code,omitempty"`
+	Response      string     `json:"response,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+/**
+inbox entry, the notifications of one type on one question are grouped while unread,
+Count tells how many and AnswerId is the answer of the latest
+ */
+type Notification struct {
+	Id         int64      `json:"id"`
+	Type       string     `json:"type"`
+	QuestionId int64      `json:"question_id"`
+	AnswerId   int64      `json:"answer_id,omitempty"`
+	Count      int64      `json:"count"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+/**
+question, tag or user the logged in user follows, Type tells which of the fields is set
+ */
+type Follow struct {
+	Id         int64     `json:"id"`
+	Type       string    `json:"type"`
+	QuestionId int64     `json:"question_id,omitempty"`
+	Tag        string    `json:"tag,omitempty"`
+	Email      string    `json:"email,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+/**
+question or answer of the feed, Question is the question of an answer too
+ */
+type FeedItem struct {
+	Kind       string    `json:"type"`
+	Id         int64     `json:"id"`
+	QuestionId int64     `json:"question_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	Question   *Question `json:"question,omitempty"`
+	Answer     *Answer   `json:"answer,omitempty"`
+}
+
+type UnreadCount struct {
+	Unread int64            `json:"unread"`
+	ByType map[string]int64 `json:"by_type"`
+}
+
+/**
+filters and order of Questions, the zero values are left to the server defaults
+ */
+type QuestionFilter struct {
+	Sort     string
+	Order    string
+	Tag      string
+	Author   string
+	Answered *bool
+	Accepted *bool
+	From     string
+	To       string
+	Limit    int
+}
+
+/**
+query and filters of Search, Type is question or answer
+ */
+type SearchFilter struct {
+	Query  string
+	Type   string
+	Tags   []string
+	Author string
+	Limit  int
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"questions/client"
+)
+
+/**
+logged in client of a new user of the api at url
+ */
+func newAPIClient(t *testing.T, url string, email string, name string) *client.Client {
+	var c *client.Client = client.New(url)
+
+	if err := c.CreateUser(context.Background(), email, name, "secret password"); err != nil {
+		t.Fatalf("creating %s: %v", email, err)
+	}
+	if err := c.Login(context.Background(), email, "secret password"); err != nil {
+		t.Fatalf("logging in %s: %v", email, err)
+	}
+
+	return c
+}
+
+/**
+the client against the routes of the api, so a path, verb, query or field the two do not agree on fails
+ */
+func TestClientAgainstAPI(t *testing.T) {
+	useTestDatabase(t)
+	truncateTables(t, "notification", "notification_setting", "follow", "digest_setting", "answer_rate", "answer", "question_vote", "question_tag", "question", "user")
+
+	var server *httptest.Server = httptest.NewServer(WithRequestId(NewAPIRouter()))
+	defer server.Close()
+
+	var ctx context.Context = context.Background()
+	var asker *client.Client = newAPIClient(t, server.URL, "asker@example.com", "asker")
+	var answerer *client.Client = newAPIClient(t, server.URL, "answerer@example.com", "answerer")
+
+	err := asker.CreateUser(ctx, "asker@example.com", "again", "secret password")
+	if !errors.Is(err, client.ErrEmailTaken) {
+		t.Fatalf("creating a user twice: got %v, want %v", err, client.ErrEmailTaken)
+	}
+
+	if err := client.New(server.URL).Login(ctx, "asker@example.com", "wrong"); !errors.Is(err, client.ErrInvalidCredentials) {
+		t.Fatalf("logging in by a wrong password: got %v, want %v", err, client.ErrInvalidCredentials)
+	}
+
+	_, err = asker.PostQuestion(ctx, "", nil)
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != client.CodeValidationFailed || len(apiErr.Errors) == 0 || apiErr.Errors[0].Field != "question" || apiErr.RequestId == "" {
+		t.Fatalf("posting an empty question: got %#v", err)
+	}
+
+	posted, err := asker.PostQuestion(ctx, "how do the client and the api stay in sync?", []string{"go"})
+	if err != nil || posted.Id == 0 {
+		t.Fatalf("posted %+v, %v", posted, err)
+	}
+
+	var questions *client.QuestionIterator = answerer.Questions(ctx, client.QuestionFilter{Tag: "go", Author: "asker@example.com", Limit: 1})
+	if !questions.Next() {
+		t.Fatalf("question not listed: %v", questions.Err())
+	}
+	if q := questions.Question(); q.Id != posted.Id || q.State != client.QuestionOpen || len(q.Tags) != 1 || q.Tags[0] != "go" || q.User == nil || q.User.Name != "asker" {
+		t.Fatalf("listed %+v", q)
+	}
+
+	answerId, err := answerer.PostAnswer(ctx, posted.Id, "by testing one against the other")
+	if err != nil || answerId == 0 {
+		t.Fatalf("answered %d, %v", answerId, err)
+	}
+
+	if err := asker.RateAnswer(ctx, answerId); err != nil {
+		t.Fatal(err)
+	}
+	if err := asker.RateAnswer(ctx, answerId); !errors.Is(err, client.ErrRateExists) {
+		t.Fatalf("rating twice: got %v, want %v", err, client.ErrRateExists)
+	}
+	if err := answerer.AcceptAnswer(ctx, answerId); !errors.Is(err, client.ErrNotOwner) {
+		t.Fatalf("accepting the answer of another asker: got %v, want %v", err, client.ErrNotOwner)
+	}
+	if err := asker.AcceptAnswer(ctx, answerId); err != nil {
+		t.Fatal(err)
+	}
+
+	var answers *client.AnswerIterator = asker.Answers(ctx, posted.Id, 10)
+	if !answers.Next() {
+		t.Fatalf("answer not listed: %v", answers.Err())
+	}
+	if a := answers.Answer(); a.Id != answerId || a.Score != 1 || a.User == nil || a.User.Name != "answerer" {
+		t.Fatalf("listed %+v", a)
+	}
+	if answers.Next() || answers.Err() != nil {
+		t.Fatalf("listed more than the answer: %v", answers.Err())
+	}
+
+	follow, err := answerer.FollowTag(ctx, "go")
+	if err != nil || follow.Tag != "go" {
+		t.Fatalf("followed %+v, %v", follow, err)
+	}
+
+	var follows *client.FollowIterator = answerer.Follows(ctx, 10)
+	if !follows.Next() || follows.Follow().Id != follow.Id {
+		t.Fatalf("follow not listed: %v", follows.Err())
+	}
+
+	if err := asker.SetDigestFrequency(ctx, client.DigestWeekly); err != nil {
+		t.Fatal(err)
+	}
+	if frequency, err := asker.DigestFrequency(ctx); err != nil || frequency != client.DigestWeekly {
+		t.Fatalf("digest frequency %s, %v, want %s", frequency, err, client.DigestWeekly)
+	}
+
+	if err := answerer.DeleteQuestion(ctx, posted.Id); !errors.Is(err, client.ErrNotOwner) {
+		t.Fatalf("deleting the question of another user: got %v, want %v", err, client.ErrNotOwner)
+	}
+	if err := asker.DeleteQuestion(ctx, posted.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := answerer.PostAnswer(ctx, posted.Id, "too late"); !errors.Is(err, client.ErrQuestionNotFound) {
+		t.Fatalf("answering a deleted question: got %v, want %v", err, client.ErrQuestionNotFound)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"github.com/go-gorp/gorp"
+)
+
+/**
+counter columns are only changed by relative updates (answer_count = answer_count + 1),
+so saving a model loaded earlier must not write them back
+ */
+var counterColumns map[string]bool = map[string]bool{
+	"answer_count": true,
+	"score":        true,
+	"views":        true,
+}
+
+func withoutCounters(col *gorp.ColumnMap) bool {
+	return !counterColumns[col.ColumnName]
+}
+
+/**
+recomputes every denormalized counter from the rows it counts and repairs the ones which
+drifted away, returns the number of repaired rows
+ */
+func ReconcileCounters() (int64, error) {
+	var repaired int64
+	var queries []string = []string{
+		"UPDATE question SET answer_count = (SELECT COUNT(*) FROM answer WHERE question_id = question.id AND deleted_at IS NULL) WHERE answer_count <> (SELECT COUNT(*) FROM answer WHERE question_id = question.id AND deleted_at IS NULL)",
+		"UPDATE question SET score = (SELECT COALESCE(SUM(rate), 0) FROM answer_rate WHERE question_id = question.id) WHERE score <> (SELECT COALESCE(SUM(rate), 0) FROM answer_rate WHERE question_id = question.id)",
+		"UPDATE answer SET score = (SELECT COALESCE(SUM(rate), 0) FROM answer_rate WHERE answer_id = answer.id) WHERE score <> (SELECT COALESCE(SUM(rate), 0) FROM answer_rate WHERE answer_id = answer.id)",
+		"UPDATE user SET answer_count = (SELECT COUNT(*) FROM answer WHERE user_id = user.id AND deleted_at IS NULL) WHERE answer_count <> (SELECT COUNT(*) FROM answer WHERE user_id = user.id AND deleted_at IS NULL)",
+	}
+
+	for _, query := range queries {
+		result, err := DBMap.Exec(query)
+		if err != nil {
+			return repaired, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return repaired, err
+		}
+
+		repaired += affected
+	}
+
+	AppCache.Clear()
+
+	return repaired, nil
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sortKindTime  = "time"
+	sortKindInt   = "int"
+	sortKindFloat = "float"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+/**
+position of a listing between two rows: the sort key and id of the last row seen,
+Before marks a cursor pointing backwards (to the previous page)
+ */
+type Cursor struct {
+	Key    string `json:"k"`
+	Id     int64  `json:"i"`
+	Kind   string `json:"t,omitempty"`
+	Before bool   `json:"b,omitempty"`
+}
+
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(token string) (Cursor, error) {
+	var c Cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+/**
+cursors around a page of a listing and the count of all rows when it is cheap to tell
+ */
+type PageInfo struct {
+	Next  string
+	Prev  string
+	Total *int64
+}
+
+/**
+response envelope of the listings
+ */
+type Page struct {
+	Items interface{} `json:"items"`
+	Next  string      `json:"next,omitempty"`
+	Prev  string      `json:"prev,omitempty"`
+	Total *int64      `json:"total,omitempty"`
+}
+
+func NewPage(items interface{}, info PageInfo) Page {
+	return Page{Items: items, Next: info.Next, Prev: info.Prev, Total: info.Total}
+}
+
+type keysetRow struct {
+	Id      int64  `db:"id"`
+	SortKey string `db:"sort_key"`
+}
+
+/**
+keyset pagination over (sort expression, id) of a table: the rows are selected after
+(or before) the cursor instead of skipping an offset, so deep pages are as cheap as the
+first one and inserted rows do not shift the pages
+ */
+type keysetQuery struct {
+	Table      string
+	SortExpr   string
+	SortKind   string
+	Conditions []string
+	Args       []interface{}
+	Desc       bool
+	Cursor     string
+	Limit      int
+	Count      bool
+}
+
+/**
+returns the ids of the page in listing order with the cursors around it
+ */
+func (kq keysetQuery) Ids() ([]int64, PageInfo, error) {
+	var info PageInfo
+	var rows []keysetRow
+	var ids []int64 = []int64{}
+	var cursor Cursor
+	var err error
+
+	var conditions []string = append([]string{}, kq.Conditions...)
+	var args []interface{} = append([]interface{}{}, kq.Args...)
+
+	if kq.Count {
+		var where string = ""
+		if len(kq.Conditions) > 0 {
+			where = " WHERE " + strings.Join(kq.Conditions, " AND ")
+		}
+		total, err := DBMap.SelectInt(fmt.Sprintf("SELECT COUNT(*) FROM %s%s", kq.Table, where), kq.Args...)
+		if err != nil {
+			return ids, info, err
+		}
+		info.Total = &total
+	}
+
+	if kq.Cursor != "" {
+		cursor, err = DecodeCursor(kq.Cursor)
+		if err != nil {
+			return ids, info, err
+		}
+
+		key, err := parseSortKey(cursor.Key, kq.SortKind)
+		if err != nil {
+			return ids, info, err
+		}
+
+		var cmp string = ">"
+		if kq.Desc != cursor.Before {
+			cmp = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND %[3]s.id %[2]s ?))", kq.SortExpr, cmp, kq.Table))
+		args = append(args, key, key, cursor.Id)
+	}
+
+	var order string = "ASC"
+	if kq.Desc != cursor.Before {
+		order = "DESC"
+	}
+
+	var where string = ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf("SELECT %[1]s.id AS id, %[2]s AS sort_key FROM %[1]s%[3]s ORDER BY %[2]s %[4]s, %[1]s.id %[4]s LIMIT %[5]d", kq.Table, kq.SortExpr, where, order, kq.Limit+1)
+
+	_, err = DBMap.Select(&rows, query, args...)
+	if err != nil {
+		return ids, info, err
+	}
+
+	var more bool = len(rows) > kq.Limit
+	if more {
+		rows = rows[:kq.Limit]
+	}
+
+	if cursor.Before {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	for _, row := range rows {
+		ids = append(ids, row.Id)
+	}
+
+	if len(rows) == 0 {
+		return ids, info, nil
+	}
+
+	first, last := rows[0], rows[len(rows)-1]
+	if (!cursor.Before && more) || cursor.Before {
+		info.Next = EncodeCursor(Cursor{Key: last.SortKey, Id: last.Id})
+	}
+	if (cursor.Before && more) || (!cursor.Before && kq.Cursor != "") {
+		info.Prev = EncodeCursor(Cursor{Key: first.SortKey, Id: first.Id, Before: true})
+	}
+
+	return ids, info, nil
+}
+
+func parseSortKey(key string, kind string) (interface{}, error) {
+	switch kind {
+	case sortKindTime:
+		t, err := time.Parse(time.RFC3339Nano, key)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return t, nil
+	case sortKindFloat:
+		f, err := strconv.ParseFloat(key, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return f, nil
+	}
+
+	i, err := strconv.ParseInt(key, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return i, nil
+}
+
+/**
+placeholders and arguments of an IN (...) condition of ids
+ */
+func inIds(ids []int64) (string, []interface{}) {
+	var placeholders []string
+	var args []interface{}
+
+	for _, id := range ids {
+		placeholders = append(placeholders, "?")
+		args = append(args, id)
+	}
+
+	return strings.Join(placeholders, ","), args
+}
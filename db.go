@@ -16,34 +16,52 @@ const (
 	port     = 3306
 	user     = "root"
 	password = ""
-	dbName   = "questions"
 	encoding = "UTF8"
 	salt     = "CwQaBVVCcDrvb2dJ"
+
+	dateFormat = "2006-01-02"
+
+	// page size bounds of the listings
+	defaultPageSize = 5
+	maxPageSize     = 50
+
+	// the retention job looks for soft deleted rows older than -deleted-retention this often
+	retentionInterval = time.Hour
 )
 
 var DBMap *gorp.DbMap
 
+// the tests connect to a database of their own
+var dbName string = "questions"
+
 type User struct {
-	Id              int64     `db:"id, primarykey, autoincrement" json:"-"`
-	Name            string    `db:"name, size:255" json:"name"`
-	Email           string    `db:"email, size:255, notnull" json:"email"`
-	Password        string    `db:"password, notnull" json:"-"`
-	AccessToken     string    `db:"access_token, size:64" json:"-"`
-	TokenExpiration time.Time `db:"token_expiration, size:64" json:"-"`
+	Id              int64      `db:"id, primarykey, autoincrement" json:"-"`
+	Name            string     `db:"name, size:255" json:"name"`
+	Email           string     `db:"email, size:255, notnull" json:"email"`
+	Password        string     `db:"password, notnull" json:"-"`
+	AccessToken     string     `db:"access_token, size:64" json:"-"`
+	TokenExpiration time.Time  `db:"token_expiration, size:64" json:"-"`
+	Moderator       bool       `db:"moderator, notnull" json:"-"`
+	AnswerCount     int64      `db:"answer_count, notnull" json:"answer_count"`
+	DeletedAt       *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	DeletedBy       int64      `db:"deleted_by" json:"-"`
 }
 
 func (u *User) Load(id int64) error {
-	err := DBMap.SelectOne(u, "SELECT * FROM user WHERE id = ?", id)
+	err := DBMap.SelectOne(u, "SELECT * FROM user WHERE id = ? AND deleted_at IS NULL", id)
 
 	return err
 }
 
 func (u *User) LoadByEmailPass(email, password string) error {
-	err := DBMap.SelectOne(u, "SELECT * FROM user WHERE email = ? AND password = ?", html.EscapeString(email), u.GetPasswordHash(password))
+	err := DBMap.SelectOne(u, "SELECT * FROM user WHERE email = ? AND password = ? AND deleted_at IS NULL", html.EscapeString(email), u.GetPasswordHash(password))
 
 	return err
 }
 
+/**
+loads deleted users as well, so an email of a deleted user can not be registered again
+ */
 func (u *User) LoadByEmail(email string) error {
 	err := DBMap.SelectOne(u, "SELECT * FROM user WHERE email = ?", html.EscapeString(email))
 
@@ -51,45 +69,127 @@ func (u *User) LoadByEmail(email string) error {
 }
 
 func (u *User) LoadByAccessToken(accesstoken string) error {
-	err := DBMap.SelectOne(u, "SELECT * FROM user WHERE access_token = ?", html.EscapeString(accesstoken))
+	err := DBMap.SelectOne(u, "SELECT * FROM user WHERE access_token = ? AND deleted_at IS NULL", html.EscapeString(accesstoken))
 
 	return err
 }
 
+/**
+LoadByAccessToken read through the cache, the session user is looked up by every authorized request
+ */
+func (u *User) LoadCachedByAccessToken(accesstoken string) error {
+	value, err := cached(cacheAuthPrefix+accesstoken, authCacheTTL, func() (interface{}, error) {
+		var user User
+		err := user.LoadByAccessToken(accesstoken)
+		return user, err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	*u = value.(User)
+
+	return nil
+}
+
 func (u User) GetTopUsers(limit int) ([]User, error) {
-	var query string
-	var err error
-	var users []User
+	value, err := cached(fmt.Sprintf("%s%d", cacheTopUsersPrefix, limit), topUsersCacheTTL, func() (interface{}, error) {
+		var users []User
+		_, err := DBMap.Select(&users, fmt.Sprintf("SELECT * FROM user WHERE deleted_at IS NULL ORDER BY answer_count DESC, id DESC LIMIT %d", limit))
+		return users, err
+	})
 
-	query = fmt.Sprintf("SELECT * FROM user ORDER BY (SELECT COUNT(*) FROM answer WHERE user_id = user.id) DESC LIMIT %d", limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return value.([]User), nil
+}
+
+func (u User) GetDeleted(cursor string, limit int) ([]User, PageInfo, error) {
+	var users []User = []User{}
+
+	ids, info, err := keysetQuery{Table: "user", SortExpr: "user.deleted_at", SortKind: sortKindTime, Conditions: []string{"deleted_at IS NOT NULL"}, Desc: true, Cursor: cursor, Limit: limit, Count: true}.Ids()
+	if err != nil || len(ids) == 0 {
+		return users, info, err
+	}
+
+	placeholders, args := inIds(ids)
+	_, err = DBMap.Select(&users, fmt.Sprintf("SELECT * FROM user WHERE id IN (%s) ORDER BY FIELD(id, %s)", placeholders, placeholders), append(args, args...)...)
+
+	return users, info, err
+}
+
+func (u *User) Delete(by User) error {
+	var now time.Time = time.Now()
+	u.DeletedAt = &now
+	u.DeletedBy = by.Id
+
+	return u.Save()
+}
+
+func (u *User) Undelete() error {
+	u.DeletedAt = nil
+	u.DeletedBy = 0
+
+	return u.Save()
+}
 
-	_, err = DBMap.Select(&users, query)
+/**
+grants or revokes the moderator rights of the user
+ */
+func (u *User) SetModerator(moderator bool) error {
+	u.Moderator = moderator
 
-	return users, err
+	return u.Save()
 }
 
 func (u *User) GetPasswordHash(password string) string {
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join([]string{password, salt}, ":"))))
 }
 
+/**
+replaces the token of the user, the session of the replaced token is dropped from the cache
+ */
 func (u *User) GenerateAccessToken() error {
+	var previous string = u.AccessToken
+
 	u.AccessToken = fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join([]string{u.Email, time.Now().Format("2006-01-02 15:04:05")}, ":"))))
 	u.TokenExpiration = time.Now().Add(time.Minute * 10)
 
 	//fmt.Println(u.AccessToken, time.Now().Format("2006-01-02 15:04:05"),u.TokenExpiration.Format("2006-01-02 15:04:05"))
-	return u.Save()
+	err := u.Save()
+	if err == nil {
+		AppCache.Delete(cacheAuthPrefix + previous)
+	}
+
+	return err
 }
 
 func (u *User) Save() error {
+	return Transaction(u.SaveIn)
+}
+
+func (u *User) SaveIn(uow *UnitOfWork) error {
 	var err error
 	if u.Id == 0 {
 		u.Password = u.GetPasswordHash(u.Password)
-		err = DBMap.Insert(u)
+		err = uow.Executor().Insert(u)
 	} else {
-		_, err = DBMap.Update(u)
+		_, err = uow.Executor().UpdateColumns(withoutCounters, u)
 	}
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	var token string = u.AccessToken
+	uow.AfterCommit(func() {
+		invalidateUserCache(token)
+	})
+
+	return nil
 }
 
 func NewUser(name, email, password string) User {
@@ -98,73 +198,292 @@ func NewUser(name, email, password string) User {
 }
 
 type Question struct {
-	Id       int64    `db:"id, primarykey, autoincrement" json:"id"`
-	Question string   `db:"question, size:255, notnull" json:"question"`
-	UserId   int64    `db:"user_id, notnull" json:"-"`
-	User     *User    `db:"-" json:"user"`
-	Answers  []Answer `db:"-" json:"answers"`
+	Id               int64      `db:"id, primarykey, autoincrement" json:"id"`
+	Question         string     `db:"question, size:255, notnull" json:"question"`
+	UserId           int64      `db:"user_id, notnull" json:"-"`
+	DuplicateOfId    int64      `db:"duplicate_of_id" json:"duplicate_of_id,omitempty"`
+	AcceptedAnswerId int64      `db:"accepted_answer_id" json:"accepted_answer_id,omitempty"`
+	Views            int64      `db:"views, notnull" json:"views"`
+	AnswerCount      int64      `db:"answer_count, notnull" json:"answer_count"`
+	Score            int64      `db:"score, notnull" json:"score"`
+	CreatedAt        time.Time  `db:"created_at, notnull" json:"created_at"`
+	LastActivityAt   time.Time  `db:"last_activity_at, notnull" json:"last_activity_at"`
+	State            string     `db:"state, size:16, notnull" json:"state"`
+	CloseReason      string     `db:"close_reason, size:32" json:"close_reason,omitempty"`
+	StateChangedAt   *time.Time `db:"state_changed_at" json:"state_changed_at,omitempty"`
+	StateChangedBy   int64      `db:"state_changed_by" json:"-"`
+	DeletedAt        *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	DeletedBy        int64      `db:"deleted_by" json:"-"`
+	User             *User      `db:"-" json:"user"`
+	Answers          []Answer   `db:"-" json:"answers"`
+	Tags             []string   `db:"-" json:"tags"`
 }
 
-func (q Question) GetByAnswersCount(page int, limit int, desc bool) ([]Question, error) {
-	var query string
-	var err error
-	var questions []Question
-	var sOrderDir string = "ASC"
-	var sLimit string = fmt.Sprintf("%d,%d", (page-1)*limit, limit)
+func (q Question) GetByAnswersCount(cursor string, limit int, desc bool) ([]Question, PageInfo, error) {
+	return q.GetList(QuestionFilter{Sort: QuestionSortAnswers, Desc: desc, Cursor: cursor, Limit: limit})
+}
+
+const (
+	QuestionSortNewest   = "newest"
+	QuestionSortActivity = "activity"
+	QuestionSortAnswers  = "answers"
+	QuestionSortScore    = "score"
+	QuestionSortViews    = "views"
+)
+
+var questionSortExpressions map[string]string = map[string]string{
+	QuestionSortNewest:   "question.created_at",
+	QuestionSortActivity: "question.last_activity_at",
+	QuestionSortAnswers:  "question.answer_count",
+	QuestionSortScore:    "question.score",
+	QuestionSortViews:    "question.views",
+}
+
+var questionSortKinds map[string]string = map[string]string{
+	QuestionSortNewest:   sortKindTime,
+	QuestionSortActivity: sortKindTime,
+	QuestionSortAnswers:  sortKindInt,
+	QuestionSortScore:    sortKindInt,
+	QuestionSortViews:    sortKindInt,
+}
+
+/**
+listing options of GetList, nil Answered and HasAccepted and zero From and To do not filter
+ */
+type QuestionFilter struct {
+	Sort        string
+	Desc        bool
+	Tag         string
+	UserId      int64
+	Answered    *bool
+	HasAccepted *bool
+	From        time.Time
+	To          time.Time
+	Cursor      string
+	Limit       int
+}
+
+func IsQuestionSort(sort string) bool {
+	_, ok := questionSortExpressions[sort]
+	return ok
+}
+
+/**
+first pages are read through the cache, the deeper ones go to the database
+ */
+func (q Question) GetList(filter QuestionFilter) ([]Question, PageInfo, error) {
+	if filter.Cursor != "" {
+		return q.getList(filter)
+	}
 
-	if desc {
-		sOrderDir = "DESC"
+	value, err := cached(listingCacheKey(filter), listingCacheTTL, func() (interface{}, error) {
+		questions, info, err := q.getList(filter)
+		return cachedListing{questions, info}, err
+	})
+
+	if err != nil {
+		return []Question{}, PageInfo{}, err
+	}
+
+	return value.(cachedListing).questions, value.(cachedListing).info, nil
+}
+
+func (q Question) getList(filter QuestionFilter) ([]Question, PageInfo, error) {
+	var conditions []string = []string{"deleted_at IS NULL"}
+	var args []interface{}
+	var sort string = filter.Sort
+
+	if !IsQuestionSort(sort) {
+		sort = QuestionSortNewest
+	}
+
+	if filter.Tag != "" {
+		conditions = append(conditions, "id IN (SELECT question_id FROM question_tag WHERE tag = ?)")
+		args = append(args, filter.Tag)
+	}
+
+	if filter.UserId != 0 {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.UserId)
+	}
+
+	if filter.Answered != nil {
+		var exists string = "EXISTS"
+		if !*filter.Answered {
+			exists = "NOT EXISTS"
+		}
+		conditions = append(conditions, exists+" (SELECT 1 FROM answer WHERE question_id = question.id AND deleted_at IS NULL)")
 	}
 
-	query = fmt.Sprintf("SELECT * FROM question ORDER BY (SELECT COUNT(*) FROM answer WHERE question_id = question.id) %s LIMIT %s", sOrderDir, sLimit)
+	if filter.HasAccepted != nil && *filter.HasAccepted {
+		conditions = append(conditions, "accepted_answer_id <> 0")
+	} else if filter.HasAccepted != nil {
+		conditions = append(conditions, "accepted_answer_id = 0")
+	}
 
-	_, err = DBMap.Select(&questions, query)
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.From)
+	}
 
-	for k, _ := range questions {
-		questions[k].AddUserData()
-		questions[k].AddAnswersData()
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.To)
 	}
 
-	return questions, err
+	ids, info, err := keysetQuery{Table: "question", SortExpr: questionSortExpressions[sort], SortKind: questionSortKinds[sort], Conditions: conditions, Args: args, Desc: filter.Desc, Cursor: filter.Cursor, Limit: filter.Limit, Count: true}.Ids()
+	if err != nil {
+		return []Question{}, info, err
+	}
+
+	questions, err := loadQuestions(ids)
+
+	return questions, info, err
 }
 
-func (q Question) GetAnswersByRate(page int, limit int, desc bool) ([]Answer, error) {
-	var query string
-	var err error
-	var answers []Answer
-	var sOrderDir string = "ASC"
-	var sLimit string = fmt.Sprintf("%d,%d", (page-1)*limit, limit)
+func (q Question) GetAnswersByRate(cursor string, limit int, desc bool) ([]Answer, PageInfo, error) {
+	var answers []Answer = []Answer{}
 
-	if desc {
-		sOrderDir = "DESC"
+	ids, info, err := keysetQuery{Table: "answer", SortExpr: "answer.score", SortKind: sortKindInt, Conditions: []string{"question_id = ?", "deleted_at IS NULL"}, Args: []interface{}{q.Id}, Desc: desc, Cursor: cursor, Limit: limit, Count: true}.Ids()
+	if err != nil {
+		return answers, info, err
 	}
 
-	query = fmt.Sprintf("SELECT * FROM answer WHERE question_id = ? ORDER BY (SELECT COUNT(*) FROM answer_rate WHERE answer_id = answer.id) %s LIMIT %s", sOrderDir, sLimit)
+	answers, err = loadAnswers(ids)
+
+	return answers, info, err
+}
 
-	_, err = DBMap.Select(&answers, query, q.Id)
+/**
+answers of the question newer than the answer of the id, oldest first
+ */
+func (q Question) GetAnswersAfter(id int64) ([]Answer, error) {
+	var ids []int64
 
-	for k, _ := range answers {
-		answers[k].AddUserData()
+	_, err := DBMap.Select(&ids, "SELECT id FROM answer WHERE question_id = ? AND id > ? AND deleted_at IS NULL ORDER BY id", q.Id, id)
+	if err != nil {
+		return []Answer{}, err
 	}
 
-	return answers, err
+	return loadAnswers(ids)
+}
+
+/**
+id of the latest answer of the question, 0 without answers
+ */
+func (q Question) LastAnswerId() (int64, error) {
+	return DBMap.SelectInt("SELECT COALESCE(MAX(id), 0) FROM answer WHERE question_id = ?", q.Id)
 }
 
 func (q *Question) Save() error {
+	return Transaction(q.SaveIn)
+}
+
+/**
+saves the question with its tags, recording QuestionPosted for a new question and QuestionUpdated otherwise
+ */
+func (q *Question) SaveIn(uow *UnitOfWork) error {
 	var err error
-	if q.Id == 0 {
-		err = DBMap.Insert(q)
+	var inserted bool = q.Id == 0
+
+	if inserted {
+		err = uow.Executor().Insert(q)
 	} else {
-		_, err = DBMap.Update(q)
+		_, err = uow.Executor().UpdateColumns(withoutCounters, q)
 	}
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	err = q.saveTags(uow)
+
+	if err != nil {
+		return err
+	}
+
+	if inserted {
+		return uow.Record(QuestionPosted{QuestionId: q.Id, UserId: q.UserId})
+	}
+
+	return uow.Record(QuestionUpdated{QuestionId: q.Id, Deleted: q.DeletedAt != nil})
+}
+
+func (q *Question) saveTags(uow *UnitOfWork) error {
+	_, err := uow.Executor().Exec("DELETE FROM question_tag WHERE question_id = ?", q.Id)
+
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range q.Tags {
+		var qt QuestionTag = QuestionTag{QuestionId: q.Id, Tag: tag}
+		err = uow.Executor().Insert(&qt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (q *Question) Load(id int64) error {
+	err := DBMap.SelectOne(q, "SELECT * FROM question WHERE id = ? AND deleted_at IS NULL", id)
+
+	if err != nil {
+		return err
+	}
+
+	return q.AddTagsData()
+}
+
+/**
+loads the question in the unit of work, locking its row until the unit of work ends
+ */
+func (q *Question) LoadForUpdate(uow *UnitOfWork, id int64) error {
+	err := uow.Executor().SelectOne(q, "SELECT * FROM question WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id)
+
+	if err != nil {
+		return err
+	}
+
+	return q.AddTagsData()
+}
+
+func (q *Question) LoadWithDeleted(id int64) error {
 	err := DBMap.SelectOne(q, "SELECT * FROM question WHERE id = ?", id)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return q.AddTagsData()
+}
+
+func (q Question) GetDeleted(cursor string, limit int) ([]Question, PageInfo, error) {
+	ids, info, err := keysetQuery{Table: "question", SortExpr: "question.deleted_at", SortKind: sortKindTime, Conditions: []string{"deleted_at IS NOT NULL"}, Desc: true, Cursor: cursor, Limit: limit, Count: true}.Ids()
+	if err != nil {
+		return []Question{}, info, err
+	}
+
+	questions, err := loadQuestions(ids)
+
+	return questions, info, err
+}
+
+func (q *Question) Delete(by User) error {
+	var now time.Time = time.Now()
+	q.DeletedAt = &now
+	q.DeletedBy = by.Id
+
+	return q.Save()
+}
+
+func (q *Question) Undelete() error {
+	q.DeletedAt = nil
+	q.DeletedBy = 0
+
+	return q.Save()
 }
 
 func (q *Question) AddUserData() error {
@@ -181,42 +500,185 @@ func (q *Question) AddUserData() error {
 	return nil
 }
 
+/**
+closes the question as the duplicate of the canonical one, duplicates of duplicates point to the canonical question
+ */
+func (q *Question) MarkDuplicateOf(canonical Question, by User) error {
+	if canonical.DuplicateOfId != 0 {
+		q.DuplicateOfId = canonical.DuplicateOfId
+	} else {
+		q.DuplicateOfId = canonical.Id
+	}
+
+	return q.Transition(QuestionClosed, CloseReasonDuplicate, by)
+}
+
+func (q *Question) AcceptAnswer(answer Answer, by User) error {
+	q.AcceptedAnswerId = answer.Id
+
+	return Transaction(func(uow *UnitOfWork) error {
+		err := q.SaveIn(uow)
+		if err != nil {
+			return err
+		}
+
+		return uow.Record(AnswerAccepted{AnswerId: answer.Id, QuestionId: q.Id, ByUserId: by.Id})
+	})
+}
+
+func (q *Question) AddView() error {
+	_, err := DBMap.Exec("UPDATE question SET views = views + 1 WHERE id = ?", q.Id)
+
+	return err
+}
+
+func (q *Question) AddTagsData() error {
+	q.Tags = []string{}
+	_, err := DBMap.Select(&q.Tags, "SELECT tag FROM question_tag WHERE question_id = ? ORDER BY tag", q.Id)
+
+	return err
+}
+
 func (q *Question) AddAnswersData() error {
-	_, err := DBMap.Select(&q.Answers, "SELECT * FROM answer WHERE question_id = ? ORDER BY id DESC", q.Id)
+	_, err := DBMap.Select(&q.Answers, "SELECT * FROM answer WHERE question_id = ? AND deleted_at IS NULL ORDER BY id DESC", q.Id)
 
 	return err
 }
 
-func NewQuestion(question string, user User) Question {
-	var q Question = Question{Question: question, UserId: user.Id}
+func NewQuestion(question string, user User, tags []string) Question {
+	var now time.Time = time.Now()
+	var q Question = Question{Question: question, UserId: user.Id, State: QuestionOpen, CreatedAt: now, LastActivityAt: now, Tags: NormalizeTags(tags)}
 	return q
 }
 
-type Answer struct {
-	Id         int64  `db:"id, primarykey, autoincrement"`
-	Answer     string `db:"answer, notnull" json:"answer"`
+type QuestionTag struct {
+	Id         int64  `db:"id, primarykey, autoincrement" json:"-"`
 	QuestionId int64  `db:"question_id, notnull" json:"-"`
-	UserId     int64  `db:"user_id, notnull" json:"-"`
-	User       *User  `db:"-" json:"user"`
+	Tag        string `db:"tag, size:64, notnull" json:"tag"`
+}
+
+/**
+lowercases and trims tags, drops the empty and repeated ones
+ */
+func NormalizeTags(tags []string) []string {
+	var normalized []string = []string{}
+	var seen map[string]bool = make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+type Answer struct {
+	Id         int64      `db:"id, primarykey, autoincrement"`
+	Answer     string     `db:"answer, notnull" json:"answer"`
+	QuestionId int64      `db:"question_id, notnull" json:"-"`
+	UserId     int64      `db:"user_id, notnull" json:"-"`
+	Score      int64      `db:"score, notnull" json:"score"`
+	CreatedAt  time.Time  `db:"created_at, notnull" json:"created_at"`
+	DeletedAt  *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	DeletedBy  int64      `db:"deleted_by" json:"-"`
+	User       *User      `db:"-" json:"user"`
 }
 
 func (a *Answer) Save() error {
+	return Transaction(a.SaveIn)
+}
+
+/**
+saves the answer and keeps the answer counters of its question and author in step in the same
+transaction: a new answer counts, deleting it uncounts it and undeleting counts it again.
+records AnswerPosted for a new answer and AnswerUpdated otherwise
+ */
+func (a *Answer) SaveIn(uow *UnitOfWork) error {
 	var err error
-	if a.Id == 0 {
-		err = DBMap.Insert(a)
+	var delta int64
+	var tx *gorp.Transaction = uow.Executor()
+	var inserted bool = a.Id == 0
+
+	if inserted {
+		err = tx.Insert(a)
+		delta = 1
+		if err == nil {
+			_, err = tx.Exec("UPDATE question SET last_activity_at = ? WHERE id = ?", a.CreatedAt, a.QuestionId)
+		}
 	} else {
-		_, err = DBMap.Update(a)
+		var counted int64
+		counted, err = tx.SelectInt("SELECT COUNT(*) FROM answer WHERE id = ? AND deleted_at IS NULL FOR UPDATE", a.Id)
+		if a.DeletedAt == nil {
+			delta = 1 - counted
+		} else {
+			delta = -counted
+		}
+		if err == nil {
+			_, err = tx.UpdateColumns(withoutCounters, a)
+		}
 	}
 
-	return err
+	if err == nil && delta != 0 {
+		_, err = tx.Exec("UPDATE question SET answer_count = answer_count + ? WHERE id = ?", delta, a.QuestionId)
+	}
+
+	if err == nil && delta != 0 {
+		_, err = tx.Exec("UPDATE user SET answer_count = answer_count + ? WHERE id = ?", delta, a.UserId)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if inserted {
+		return uow.Record(AnswerPosted{AnswerId: a.Id, QuestionId: a.QuestionId, UserId: a.UserId})
+	}
+
+	return uow.Record(AnswerUpdated{AnswerId: a.Id, QuestionId: a.QuestionId, Deleted: a.DeletedAt != nil})
 }
 
 func (a *Answer) Load(id int64) error {
+	err := DBMap.SelectOne(a, "SELECT * FROM answer WHERE id = ? AND deleted_at IS NULL", id)
+
+	return err
+}
+
+func (a *Answer) LoadWithDeleted(id int64) error {
 	err := DBMap.SelectOne(a, "SELECT * FROM answer WHERE id = ?", id)
 
 	return err
 }
 
+func (a Answer) GetDeleted(cursor string, limit int) ([]Answer, PageInfo, error) {
+	ids, info, err := keysetQuery{Table: "answer", SortExpr: "answer.deleted_at", SortKind: sortKindTime, Conditions: []string{"deleted_at IS NOT NULL"}, Desc: true, Cursor: cursor, Limit: limit, Count: true}.Ids()
+	if err != nil {
+		return []Answer{}, info, err
+	}
+
+	answers, err := loadAnswers(ids)
+
+	return answers, info, err
+}
+
+func (a *Answer) Delete(by User) error {
+	var now time.Time = time.Now()
+	a.DeletedAt = &now
+	a.DeletedBy = by.Id
+
+	return a.Save()
+}
+
+func (a *Answer) Undelete() error {
+	a.DeletedAt = nil
+	a.DeletedBy = 0
+
+	return a.Save()
+}
+
 func (a *Answer) AddUserData() error {
 	var u User
 
@@ -232,7 +694,7 @@ func (a *Answer) AddUserData() error {
 }
 
 func NewAnswer(answer string, user User, question Question) Answer {
-	var a Answer = Answer{Answer: answer, QuestionId: question.Id, UserId: user.Id}
+	var a Answer = Answer{Answer: answer, QuestionId: question.Id, UserId: user.Id, CreatedAt: time.Now()}
 	return a
 }
 
@@ -266,14 +728,41 @@ func (ar *AnswerRate) LoadByAnswerAndUser(answer Answer, user User) error {
 }
 
 func (ar *AnswerRate) Save() error {
+	return Transaction(ar.SaveIn)
+}
+
+/**
+saves the rate and adds it to the score of the answer and of its question in the same transaction
+ */
+func (ar *AnswerRate) SaveIn(uow *UnitOfWork) error {
 	var err error
+	var delta int64 = ar.Rate
+	var tx *gorp.Transaction = uow.Executor()
+
 	if ar.Id == 0 {
-		err = DBMap.Insert(ar)
+		err = tx.Insert(ar)
 	} else {
-		_, err = DBMap.Update(ar)
+		var previous int64
+		previous, err = tx.SelectInt("SELECT rate FROM answer_rate WHERE id = ? FOR UPDATE", ar.Id)
+		delta -= previous
+		if err == nil {
+			_, err = tx.Update(ar)
+		}
 	}
 
-	return err
+	if err == nil && delta != 0 {
+		_, err = tx.Exec("UPDATE answer SET score = score + ? WHERE id = ?", delta, ar.AnswerId)
+	}
+
+	if err == nil && delta != 0 {
+		_, err = tx.Exec("UPDATE question SET score = score + ? WHERE id = ?", delta, ar.QuestionId)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return uow.Record(AnswerRated{AnswerId: ar.AnswerId, QuestionId: ar.QuestionId, UserId: ar.UserId, Rate: ar.Rate})
 }
 
 func NewAnswerRate(rate int64, user User, answer Answer) AnswerRate {
@@ -281,8 +770,45 @@ func NewAnswerRate(rate int64, user User, answer Answer) AnswerRate {
 	return ar
 }
 
-func init() {
-	estabilishConnection(true)
+/**
+hard deletes every row which has been soft deleted before the given time, together with the rows depending on them
+ */
+func PurgeDeleted(before time.Time) error {
+	var queries []string = []string{
+		"UPDATE user SET answer_count = answer_count - (SELECT COUNT(*) FROM answer WHERE user_id = user.id AND deleted_at IS NULL AND question_id IN (SELECT id FROM question WHERE deleted_at IS NOT NULL AND deleted_at < ?))",
+		"DELETE FROM answer_rate WHERE question_id IN (SELECT id FROM question WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM answer_rate WHERE answer_id IN (SELECT id FROM answer WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM answer WHERE question_id IN (SELECT id FROM question WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM question_tag WHERE question_id IN (SELECT id FROM question WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM question_vote WHERE question_id IN (SELECT id FROM question WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM follow WHERE question_id IN (SELECT id FROM question WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM follow WHERE followee_id IN (SELECT id FROM user WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM follow WHERE user_id IN (SELECT id FROM user WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM notification WHERE question_id IN (SELECT id FROM question WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM notification WHERE user_id IN (SELECT id FROM user WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM notification_setting WHERE user_id IN (SELECT id FROM user WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM webhook_delivery WHERE webhook_id IN (SELECT id FROM webhook WHERE user_id IN (SELECT id FROM user WHERE deleted_at IS NOT NULL AND deleted_at < ?))",
+		"DELETE FROM webhook WHERE user_id IN (SELECT id FROM user WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM digest_setting WHERE user_id IN (SELECT id FROM user WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM digest_log WHERE user_id IN (SELECT id FROM user WHERE deleted_at IS NOT NULL AND deleted_at < ?)",
+		"DELETE FROM answer WHERE deleted_at IS NOT NULL AND deleted_at < ?",
+		"DELETE FROM question WHERE deleted_at IS NOT NULL AND deleted_at < ?",
+		"DELETE FROM user WHERE deleted_at IS NOT NULL AND deleted_at < ?",
+		"DELETE FROM event_outbox WHERE dispatched_at IS NOT NULL AND dispatched_at < ?",
+	}
+
+	return Transaction(func(uow *UnitOfWork) error {
+		for _, query := range queries {
+			_, err := uow.Executor().Exec(query, before)
+			if err != nil {
+				return err
+			}
+		}
+
+		uow.AfterCommit(AppCache.Clear)
+
+		return nil
+	})
 }
 
 func getSqlinfo(withDb bool) string {
@@ -331,10 +857,86 @@ func estabilishConnection(build bool) {
 	DBMap.AddTableWithName(Answer{}, "answer").SetKeys(true, "id")
 	DBMap.AddTableWithName(AnswerRate{}, "answer_rate").SetKeys(true, "id")
 	DBMap.AddTableWithName(User{}, "user").SetKeys(true, "id")
+	DBMap.AddTableWithName(QuestionTag{}, "question_tag").SetKeys(true, "id")
+	DBMap.AddTableWithName(QuestionVote{}, "question_vote").SetKeys(true, "id")
+	DBMap.AddTableWithName(OutboxEvent{}, "event_outbox").SetKeys(true, "id")
+	DBMap.AddTableWithName(Webhook{}, "webhook").SetKeys(true, "id")
+	DBMap.AddTableWithName(WebhookDelivery{}, "webhook_delivery").SetKeys(true, "id")
+	DBMap.AddTableWithName(Notification{}, "notification").SetKeys(true, "id")
+	DBMap.AddTableWithName(NotificationSetting{}, "notification_setting").SetKeys(true, "id")
+	DBMap.AddTableWithName(Follow{}, "follow").SetKeys(true, "id")
+	DBMap.AddTableWithName(DigestSetting{}, "digest_setting").SetKeys(true, "id")
+	DBMap.AddTableWithName(DigestLog{}, "digest_log").SetKeys(true, "id")
+	DBMap.AddTableWithName(SchemaMigration{}, "schema_migration").SetKeys(false, "version")
 
 	err = DBMap.CreateTablesIfNotExists()
 
 	if err != nil {
 		panic(err)
 	}
+
+	// the indexes may be on columns the migrations add
+	err = Migrate()
+
+	if err != nil {
+		panic(err)
+	}
+
+	for _, index := range indexes {
+		err = ensureIndex(index)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+type tableIndex struct {
+	table   string
+	name    string
+	unique  bool
+	columns []string
+}
+
+var indexes []tableIndex = []tableIndex{
+	{"question", "idx_question_answer_count", false, []string{"answer_count"}},
+	{"question", "idx_question_score", false, []string{"score"}},
+	{"question", "idx_question_created_at", false, []string{"created_at"}},
+	{"question", "idx_question_last_activity_at", false, []string{"last_activity_at"}},
+	{"answer", "idx_answer_question_score", false, []string{"question_id", "score"}},
+	{"user", "idx_user_answer_count", false, []string{"answer_count"}},
+	{"user", "uniq_user_email", true, []string{"email"}},
+	{"answer_rate", "uniq_answer_rate_answer_user", true, []string{"answer_id", "user_id"}},
+	{"question_vote", "uniq_question_vote_question_user_kind", true, []string{"question_id", "user_id", "kind"}},
+	{"question_tag", "uniq_question_tag_question_tag", true, []string{"question_id", "tag"}},
+	{"event_outbox", "idx_event_outbox_dispatched_at", false, []string{"dispatched_at"}},
+	{"webhook", "idx_webhook_user", false, []string{"user_id"}},
+	{"webhook_delivery", "idx_webhook_delivery_webhook", false, []string{"webhook_id"}},
+	{"webhook_delivery", "idx_webhook_delivery_status_next_attempt_at", false, []string{"status", "next_attempt_at"}},
+	{"notification", "idx_notification_user_updated_at", false, []string{"user_id", "updated_at"}},
+	{"notification", "idx_notification_user_type_question", false, []string{"user_id", "type", "question_id"}},
+	{"notification_setting", "uniq_notification_setting_user_type", true, []string{"user_id", "type"}},
+	{"follow", "uniq_follow_user_target", true, []string{"user_id", "kind", "question_id", "tag", "followee_id"}},
+	{"follow", "idx_follow_kind_followee", false, []string{"kind", "followee_id"}},
+	{"digest_setting", "uniq_digest_setting_user", true, []string{"user_id"}},
+	{"digest_setting", "idx_digest_setting_frequency", false, []string{"frequency"}},
+	{"digest_log", "uniq_digest_log_user_frequency_period", true, []string{"user_id", "frequency", "period"}},
+}
+
+/**
+creates the index unless the table has it already
+ */
+func ensureIndex(index tableIndex) error {
+	count, err := DBMap.SelectInt("SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?", index.table, index.name)
+	if err != nil || count > 0 {
+		return err
+	}
+
+	var sUnique string = ""
+	if index.unique {
+		sUnique = "UNIQUE "
+	}
+
+	_, err = DBMap.Exec(fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", sUnique, index.name, index.table, strings.Join(index.columns, ", ")))
+
+	return err
 }
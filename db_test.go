@@ -0,0 +1,121 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testDatabaseOnce sync.Once
+var testDatabaseErr error
+
+/**
+connects DBMap to the questions_test database, built on the first use. the tests needing
+the database are skipped when no mysql server is reachable
+ */
+func useTestDatabase(tb testing.TB) {
+	testDatabaseOnce.Do(func() {
+		db, err := sql.Open("mysql", getSqlinfo(false))
+		if err == nil {
+			err = db.Ping()
+			db.Close()
+		}
+		if err != nil {
+			testDatabaseErr = err
+			return
+		}
+
+		dbName = "questions_test"
+		estabilishConnection(true)
+	})
+
+	if testDatabaseErr != nil {
+		tb.Skipf("no database: %v", testDatabaseErr)
+	}
+}
+
+/**
+empties the tables, the tests start from an empty database
+ */
+func truncateTables(tb testing.TB, tables ...string) {
+	for _, table := range tables {
+		_, err := DBMap.Exec(fmt.Sprintf("DELETE FROM `%s`", table))
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+/**
+logger of gorp counting the statements instead of printing them, set by DBMap.TraceOn
+ */
+type queryCounter struct {
+	count int64
+}
+
+func (c *queryCounter) Printf(format string, v ...interface{}) {
+	atomic.AddInt64(&c.count, 1)
+}
+
+/**
+statements run on DBMap while f runs
+ */
+func countQueries(tb testing.TB, f func() error) int64 {
+	var counter *queryCounter = &queryCounter{}
+
+	DBMap.TraceOn("", counter)
+	defer DBMap.TraceOff()
+
+	err := f()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return atomic.LoadInt64(&counter.count)
+}
+
+/**
+inserts questions with tags and answers of different authors, straight into the tables so
+no events are published
+ */
+func seedQuestions(tb testing.TB, questions int, answers int) []Question {
+	var seeded []Question
+	var authors []User
+	var now time.Time = time.Now()
+
+	for i := 0; i <= answers; i++ {
+		var u User = User{Name: fmt.Sprintf("user %d", i), Email: fmt.Sprintf("user%d@example.com", i), Password: "-", TokenExpiration: now}
+		if err := DBMap.Insert(&u); err != nil {
+			tb.Fatal(err)
+		}
+		authors = append(authors, u)
+	}
+
+	for i := 0; i < questions; i++ {
+		var created time.Time = now.Add(-time.Duration(i) * time.Minute)
+		var q Question = Question{Question: fmt.Sprintf("question %d", i), UserId: authors[0].Id, State: QuestionOpen, AnswerCount: int64(answers), CreatedAt: created, LastActivityAt: created}
+		if err := DBMap.Insert(&q); err != nil {
+			tb.Fatal(err)
+		}
+
+		for _, tag := range []string{"go", fmt.Sprintf("tag%d", i%3)} {
+			if err := DBMap.Insert(&QuestionTag{QuestionId: q.Id, Tag: tag}); err != nil {
+				tb.Fatal(err)
+			}
+		}
+
+		for k := 1; k <= answers; k++ {
+			var a Answer = Answer{Answer: fmt.Sprintf("answer %d", k), QuestionId: q.Id, UserId: authors[k].Id, Score: int64(k), CreatedAt: created.Add(time.Duration(k) * time.Second)}
+			if err := DBMap.Insert(&a); err != nil {
+				tb.Fatal(err)
+			}
+		}
+
+		seeded = append(seeded, q)
+	}
+
+	return seeded
+}
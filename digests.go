@@ -0,0 +1,459 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	htmltemplate "html/template"
+	"log"
+	"mime"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+
+	DigestSent   = "sent"
+	DigestEmpty  = "empty"
+	DigestFailed = "failed"
+
+	// the digests are sent from this hour of the day on, the weekly ones on monday
+	digestHour = 8
+
+	// due digests are looked for this often
+	digestInterval = 15 * time.Minute
+
+	digestSectionSize = 10
+	digestTopAnswers  = 5
+
+	// environment variables of the digests: the key signing the unsubscribe links, the url the links
+	// of the mails point to, the smtp server and the sender of the mails
+	digestSigningKeyEnv = "QUESTIONS_DIGEST_KEY"
+	publicURLEnv        = "QUESTIONS_PUBLIC_URL"
+	smtpAddrEnv         = "QUESTIONS_SMTP_ADDR"
+	mailFromEnv         = "QUESTIONS_MAIL_FROM"
+
+	defaultSMTPAddr = "localhost:25"
+	defaultMailFrom = "questions@localhost"
+)
+
+// set by LoadDigestConfig, no unsubscribe token is valid without the key
+var digestSigningKey string
+var publicURL string
+
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+/**
+how often a user gets the digest, the users without a setting get none
+ */
+type DigestSetting struct {
+	Id        int64     `db:"id, primarykey, autoincrement" json:"-"`
+	UserId    int64     `db:"user_id, notnull" json:"-"`
+	Frequency string    `db:"frequency, size:16, notnull" json:"frequency"`
+	UpdatedAt time.Time `db:"updated_at, notnull" json:"updated_at"`
+}
+
+func DigestFrequency(userId int64) (string, error) {
+	var setting DigestSetting
+
+	err := DBMap.SelectOne(&setting, "SELECT * FROM digest_setting WHERE user_id = ?", userId)
+	if err == sql.ErrNoRows {
+		return DigestOff, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return setting.Frequency, nil
+}
+
+func SetDigestFrequency(userId int64, frequency string) error {
+	_, err := DBMap.Exec("INSERT INTO digest_setting (user_id, frequency, updated_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE frequency = VALUES(frequency), updated_at = VALUES(updated_at)", userId, frequency, time.Now())
+
+	return err
+}
+
+/**
+digest of a user for a period, inserted before the digest is sent. the unique (user, frequency, period)
+makes the digest claimed by one sender only, so a restarted or second process does not send it again.
+a digest failing to send is not retried, a user rather misses one than gets it twice
+ */
+type DigestLog struct {
+	Id        int64     `db:"id, primarykey, autoincrement" json:"id"`
+	UserId    int64     `db:"user_id, notnull" json:"-"`
+	Frequency string    `db:"frequency, size:16, notnull" json:"frequency"`
+	Period    string    `db:"period, size:16, notnull" json:"period"`
+	Status    string    `db:"status, size:16" json:"status,omitempty"`
+	Error     string    `db:"error, size:255" json:"error,omitempty"`
+	CreatedAt time.Time `db:"created_at, notnull" json:"created_at"`
+}
+
+/**
+inserts the log of the digest, false when it has been claimed already
+ */
+func claimDigest(userId int64, frequency string, period string) (DigestLog, bool, error) {
+	var digestLog DigestLog = DigestLog{UserId: userId, Frequency: frequency, Period: period, CreatedAt: time.Now()}
+
+	result, err := DBMap.Exec("INSERT IGNORE INTO digest_log (user_id, frequency, period, status, error, created_at) VALUES (?, ?, ?, '', '', ?)", userId, frequency, period, digestLog.CreatedAt)
+	if err != nil {
+		return digestLog, false, err
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil || claimed == 0 {
+		return digestLog, false, err
+	}
+
+	digestLog.Id, err = result.LastInsertId()
+
+	return digestLog, true, err
+}
+
+func (dl DigestLog) finish(status string, cause error) {
+	var message string
+
+	if cause != nil {
+		message = cause.Error()
+		if len(message) > 255 {
+			message = message[:255]
+		}
+	}
+
+	_, err := DBMap.Exec("UPDATE digest_log SET status = ?, error = ? WHERE id = ?", status, message, dl.Id)
+	if err != nil {
+		log.Printf("logging digest %d failed: %v", dl.Id, err)
+	}
+}
+
+/**
+period of the frequency at the time with the time span its digest covers, due is false until the
+digest of the period may be sent. the span ends when the period starts, at digestHour of the day or
+of the monday of the week, and starts where the span of the period before ends. so the digests of
+consecutive periods neither miss nor repeat an item whenever they are sent
+ */
+func digestPeriod(frequency string, now time.Time) (string, time.Time, time.Time, bool) {
+	var until time.Time = time.Date(now.Year(), now.Month(), now.Day(), digestHour, 0, 0, 0, now.Location())
+
+	if frequency == DigestWeekly {
+		year, week := now.ISOWeek()
+		until = until.AddDate(0, 0, -((int(now.Weekday()) + 6) % 7))
+		return fmt.Sprintf("%d-W%02d", year, week), until.AddDate(0, 0, -7), until, !now.Before(until)
+	}
+
+	return now.Format(dateFormat), until.AddDate(0, 0, -1), until, !now.Before(until)
+}
+
+/**
+content of a digest: the unanswered questions in the followed tags and the new answers on the followed
+questions from Since to Until, with the top rated answers of the week before Until
+ */
+type Digest struct {
+	User           User
+	Frequency      string
+	Since          time.Time
+	Until          time.Time
+	Unanswered     []Question
+	NewAnswers     []FeedItem
+	TopAnswers     []FeedItem
+	UnsubscribeURL string
+}
+
+func BuildDigest(user User, frequency string, since time.Time, until time.Time) (Digest, error) {
+	var digest Digest = Digest{User: user, Frequency: frequency, Since: since, Until: until, UnsubscribeURL: UnsubscribeURL(user.Id)}
+	var newAnswers []feedRow
+	var topAnswers []feedRow
+
+	_, err := DBMap.Select(&digest.Unanswered, fmt.Sprintf(`SELECT * FROM question WHERE deleted_at IS NULL AND state = ? AND answer_count = 0 AND created_at >= ? AND created_at < ? AND user_id <> ?
+		AND id IN (SELECT question_id FROM question_tag WHERE tag IN (SELECT tag FROM follow WHERE user_id = ? AND kind = 'tag'))
+		ORDER BY created_at DESC LIMIT %d`, digestSectionSize), QuestionOpen, since, until, user.Id, user.Id)
+	if err != nil {
+		return digest, err
+	}
+
+	_, err = DBMap.Select(&newAnswers, fmt.Sprintf(`SELECT 'answer' AS kind, answer.id AS id, answer.question_id AS question_id, answer.created_at AS created_at FROM answer
+		JOIN question ON question.id = answer.question_id AND question.deleted_at IS NULL
+		WHERE answer.deleted_at IS NULL AND answer.created_at >= ? AND answer.created_at < ? AND answer.user_id <> ?
+		AND answer.question_id IN (SELECT question_id FROM follow WHERE user_id = ? AND kind = 'question')
+		ORDER BY answer.created_at DESC LIMIT %d`, digestSectionSize), since, until, user.Id, user.Id)
+	if err != nil {
+		return digest, err
+	}
+
+	_, err = DBMap.Select(&topAnswers, fmt.Sprintf(`SELECT 'answer' AS kind, answer.id AS id, answer.question_id AS question_id, answer.created_at AS created_at FROM answer
+		JOIN question ON question.id = answer.question_id AND question.deleted_at IS NULL
+		WHERE answer.deleted_at IS NULL AND answer.created_at >= ? AND answer.created_at < ? AND answer.score > 0
+		ORDER BY answer.score DESC, answer.id DESC LIMIT %d`, digestTopAnswers), until.AddDate(0, 0, -7), until)
+	if err != nil {
+		return digest, err
+	}
+
+	digest.NewAnswers, err = loadFeedItems(newAnswers)
+	if err != nil {
+		return digest, err
+	}
+
+	digest.TopAnswers, err = loadFeedItems(topAnswers)
+
+	return digest, err
+}
+
+/**
+a digest without unanswered questions and new answers is not sent, the top answers alone are no news to the user
+ */
+func (d Digest) Empty() bool {
+	return len(d.Unanswered) == 0 && len(d.NewAnswers) == 0
+}
+
+var digestSubject *template.Template = template.Must(template.New("subject").Parse(
+	`Your {{.Frequency}} questions digest: {{len .Unanswered}} unanswered, {{len .NewAnswers}} new answers`))
+
+var digestBody *template.Template = template.Must(template.New("body").Parse(`Hello {{.User.Name}},
+{{if .Unanswered}}
+Unanswered questions in the tags you follow:{{range .Unanswered}}
+  * {{.Question}}{{end}}
+{{end}}{{if .NewAnswers}}
+New answers on the questions you watch:{{range .NewAnswers}}{{if .Question}}
+  * {{.Question.Question}}
+    {{.Answer.Answer}}{{end}}{{end}}
+{{end}}{{if .TopAnswers}}
+Top rated answers of the week:{{range .TopAnswers}}{{if .Question}}
+  * {{.Question.Question}} ({{.Answer.Score}})
+    {{.Answer.Answer}}{{end}}{{end}}
+{{end}}
+You get this digest {{.Frequency}}. To stop it, open {{.UnsubscribeURL}}
+`))
+
+func (d Digest) Render() (string, string, error) {
+	var subject, body bytes.Buffer
+
+	err := digestSubject.Execute(&subject, d)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = digestBody.Execute(&body, d)
+
+	return subject.String(), body.String(), err
+}
+
+/**
+plain text mail, Unsubscribe is sent in the List-Unsubscribe header when set
+ */
+type Mail struct {
+	To          string
+	Subject     string
+	Body        string
+	Unsubscribe string
+}
+
+type Mailer interface {
+	Send(m Mail) error
+}
+
+/**
+sends the mails through the smtp server at Addr
+ */
+type SMTPMailer struct {
+	Addr string
+	From string
+}
+
+var DigestMailer Mailer = SMTPMailer{Addr: defaultSMTPAddr, From: defaultMailFrom}
+
+func (sm SMTPMailer) Send(m Mail) error {
+	var msg bytes.Buffer
+
+	fmt.Fprintf(&msg, "From: %s\r\n", sm.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", m.To)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	if m.Unsubscribe != "" {
+		fmt.Fprintf(&msg, "List-Unsubscribe: <%s>\r\n", m.Unsubscribe)
+		fmt.Fprint(&msg, "List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+	}
+	fmt.Fprint(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprint(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.Replace(m.Body, "\n", "\r\n", -1))
+
+	return smtp.SendMail(sm.Addr, nil, sm.From, []string{m.To}, msg.Bytes())
+}
+
+/**
+token of the unsubscribe link of a user, the user id signed by digestSigningKey
+ */
+func UnsubscribeToken(userId int64) string {
+	var id string = strconv.FormatInt(userId, 10)
+
+	return id + "." + signUnsubscribe(id)
+}
+
+func ParseUnsubscribeToken(token string) (int64, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if digestSigningKey == "" || len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(signUnsubscribe(parts[0]))) {
+		return 0, ErrInvalidUnsubscribeToken
+	}
+
+	userId, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidUnsubscribeToken
+	}
+
+	return userId, nil
+}
+
+func signUnsubscribe(id string) string {
+	var mac hash.Hash = hmac.New(sha256.New, []byte(digestSigningKey))
+	mac.Write([]byte("unsubscribe." + id))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func UnsubscribeURL(userId int64) string {
+	return publicURL + "/v1/digests/unsubscribe?token=" + url.QueryEscape(UnsubscribeToken(userId))
+}
+
+/**
+reads the configuration of the digests from the environment, the server does not start without the
+signing key and the public url. the smtp server and the sender default to the local ones
+ */
+func LoadDigestConfig() error {
+	var key string = os.Getenv(digestSigningKeyEnv)
+	if len(key) < 16 {
+		return fmt.Errorf("%s must be set to a key of at least 16 characters", digestSigningKeyEnv)
+	}
+
+	var public string = strings.TrimRight(os.Getenv(publicURLEnv), "/")
+	parsed, err := url.Parse(public)
+	if public == "" || err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%s must be set to the http or https url the api is reached at", publicURLEnv)
+	}
+
+	digestSigningKey = key
+	publicURL = public
+	DigestMailer = SMTPMailer{Addr: getenvOr(smtpAddrEnv, defaultSMTPAddr), From: getenvOr(mailFromEnv, defaultMailFrom)}
+
+	return nil
+}
+
+func getenvOr(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+/**
+page of the unsubscribe link: the link only asks for the confirmation, which posts the form back to the
+link. Done is the page after the post
+ */
+var unsubscribePage *htmltemplate.Template = htmltemplate.Must(htmltemplate.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>questions digest</title></head>
+<body>
+{{if .Done}}<p>You will not get the questions digest anymore, it can be turned on again in the settings.</p>
+{{else}}<form method="post" action="{{.Action}}">
+<p>Stop the questions digest?</p>
+<button type="submit">Unsubscribe</button>
+</form>
+{{end}}</body>
+</html>
+`))
+
+type unsubscribePageData struct {
+	Action string
+	Done   bool
+}
+
+/**
+sends the digests due at the time which have not been claimed yet, returns how many were sent
+ */
+func SendDueDigests(now time.Time) (int, error) {
+	var sent int
+
+	for _, frequency := range []string{DigestDaily, DigestWeekly} {
+		var userIds []int64
+
+		period, since, until, due := digestPeriod(frequency, now)
+		if !due {
+			continue
+		}
+
+		_, err := DBMap.Select(&userIds, "SELECT user_id FROM digest_setting WHERE frequency = ? AND user_id NOT IN (SELECT user_id FROM digest_log WHERE frequency = ? AND period = ?)", frequency, frequency, period)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, userId := range userIds {
+			ok, err := sendDigest(userId, frequency, period, since, until)
+			if err != nil {
+				log.Printf("sending the %s digest of user %d failed: %v", frequency, userId, err)
+			} else if ok {
+				sent++
+			}
+		}
+	}
+
+	return sent, nil
+}
+
+func sendDigest(userId int64, frequency string, period string, since time.Time, until time.Time) (bool, error) {
+	var user User
+
+	err := user.Load(userId)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	digestLog, claimed, err := claimDigest(userId, frequency, period)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	digest, err := BuildDigest(user, frequency, since, until)
+	if err != nil {
+		digestLog.finish(DigestFailed, err)
+		return false, err
+	}
+
+	if digest.Empty() {
+		digestLog.finish(DigestEmpty, nil)
+		return false, nil
+	}
+
+	subject, body, err := digest.Render()
+	if err == nil {
+		err = DigestMailer.Send(Mail{To: user.Email, Subject: subject, Body: body, Unsubscribe: digest.UnsubscribeURL})
+	}
+	if err != nil {
+		digestLog.finish(DigestFailed, err)
+		return false, err
+	}
+
+	digestLog.finish(DigestSent, nil)
+
+	return true, nil
+}
+
+func sendDigestsPeriodically(interval time.Duration) {
+	for range time.Tick(interval) {
+		_, err := SendDueDigests(time.Now())
+		if err != nil {
+			log.Println("sending digests failed:", err)
+		}
+	}
+}
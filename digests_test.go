@@ -0,0 +1,288 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/**
+environment of the digest configuration for the test, the configuration of before is restored after it
+ */
+func useDigestConfig(t *testing.T, key string, public string) {
+	var previousKey, previousURL, previousMailer = digestSigningKey, publicURL, DigestMailer
+	t.Cleanup(func() { digestSigningKey, publicURL, DigestMailer = previousKey, previousURL, previousMailer })
+
+	t.Setenv(digestSigningKeyEnv, key)
+	t.Setenv(publicURLEnv, public)
+	t.Setenv(smtpAddrEnv, "")
+	t.Setenv(mailFromEnv, "")
+	digestSigningKey, publicURL = "", ""
+}
+
+func useDigestSigningKey(t *testing.T, key string) {
+	useDigestConfig(t, key, "https://questions.example.com")
+}
+
+func TestDigestSigningKeyIsRequired(t *testing.T) {
+	for _, key := range []string{"", "too short"} {
+		useDigestSigningKey(t, key)
+
+		if err := LoadDigestConfig(); err == nil {
+			t.Errorf("key %q accepted", key)
+		}
+		if _, err := ParseUnsubscribeToken("1.0123"); err != ErrInvalidUnsubscribeToken {
+			t.Errorf("token parsed without a key: %v", err)
+		}
+	}
+}
+
+func TestPublicURLIsRequired(t *testing.T) {
+	for _, public := range []string{"", "questions.example.com", "ftp://questions.example.com", "https://"} {
+		useDigestConfig(t, "0123456789abcdef", public)
+
+		if err := LoadDigestConfig(); err == nil {
+			t.Errorf("public url %q accepted", public)
+		}
+	}
+}
+
+func TestDigestConfigIsReadFromTheEnvironment(t *testing.T) {
+	useDigestConfig(t, "0123456789abcdef", "https://questions.example.com/")
+	if err := LoadDigestConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	if mailer := DigestMailer.(SMTPMailer); mailer.Addr != defaultSMTPAddr || mailer.From != defaultMailFrom {
+		t.Errorf("mailer %+v, want the local defaults", mailer)
+	}
+	if link := UnsubscribeURL(42); !strings.HasPrefix(link, "https://questions.example.com/v1/digests/unsubscribe?token=42.") {
+		t.Errorf("unsubscribe link %s, want it on the public url", link)
+	}
+
+	t.Setenv(smtpAddrEnv, "mail.example.com:587")
+	t.Setenv(mailFromEnv, "digest@example.com")
+	if err := LoadDigestConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	if mailer := DigestMailer.(SMTPMailer); mailer.Addr != "mail.example.com:587" || mailer.From != "digest@example.com" {
+		t.Errorf("mailer %+v, want the one of the environment", mailer)
+	}
+}
+
+func TestUnsubscribeToken(t *testing.T) {
+	useDigestSigningKey(t, "0123456789abcdef")
+	if err := LoadDigestConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	var token string = UnsubscribeToken(42)
+
+	userId, err := ParseUnsubscribeToken(token)
+	if err != nil || userId != 42 {
+		t.Fatalf("parsed %d, %v, want 42", userId, err)
+	}
+
+	for _, forged := range []string{"43" + token[2:], token + "0", "42", ""} {
+		if _, err := ParseUnsubscribeToken(forged); err != ErrInvalidUnsubscribeToken {
+			t.Errorf("%q: got %v, want %v", forged, err, ErrInvalidUnsubscribeToken)
+		}
+	}
+}
+
+func TestUnsubscribeLinkOnlyAsksToConfirm(t *testing.T) {
+	useDigestSigningKey(t, "0123456789abcdef")
+	if err := LoadDigestConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	var target string = "/v1/digests/unsubscribe?token=" + UnsubscribeToken(42)
+	var w *httptest.ResponseRecorder = httptest.NewRecorder()
+
+	// no database is connected here, the page is rendered without touching the setting
+	ConfirmUnsubscribeDigests(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("got %d %s, want the confirmation page", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), `<form method="post" action="`+target) {
+		t.Errorf("page %s, want a form posting to the link", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	ConfirmUnsubscribeDigests(w, httptest.NewRequest(http.MethodGet, "/v1/digests/unsubscribe?token=42.forged", nil))
+
+	if w.Code != CodeInvalidUnsubscribe.Status {
+		t.Errorf("forged token got %d, want %d", w.Code, CodeInvalidUnsubscribe.Status)
+	}
+}
+
+func TestUnsubscribeIsPosted(t *testing.T) {
+	useTestDatabase(t)
+	truncateTables(t, "digest_setting")
+	useDigestSigningKey(t, "0123456789abcdef")
+	if err := LoadDigestConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetDigestFrequency(42, DigestDaily); err != nil {
+		t.Fatal(err)
+	}
+
+	var target string = "/v1/digests/unsubscribe?token=" + UnsubscribeToken(42)
+	ConfirmUnsubscribeDigests(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+
+	if frequency, err := DigestFrequency(42); err != nil || frequency != DigestDaily {
+		t.Fatalf("frequency %s, %v after the link was opened, want %s", frequency, err, DigestDaily)
+	}
+
+	var r *http.Request = httptest.NewRequest(http.MethodPost, target, nil)
+	r.Header.Set("Accept", "text/html")
+	var w *httptest.ResponseRecorder = httptest.NewRecorder()
+	UnsubscribeDigests(w, r)
+
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("got %d %s, want the done page", w.Code, w.Header().Get("Content-Type"))
+	}
+	if frequency, err := DigestFrequency(42); err != nil || frequency != DigestOff {
+		t.Fatalf("frequency %s, %v after the post, want %s", frequency, err, DigestOff)
+	}
+}
+
+func TestDigestPeriodsFollowEachOther(t *testing.T) {
+	var day = func(d int, hour int, min int) time.Time {
+		return time.Date(2026, time.October, d, hour, min, 0, 0, time.Local)
+	}
+
+	// monday 19 to wednesday 21, the digest of the 20th sent late in the day
+	period, since, until, due := digestPeriod(DigestDaily, day(19, 8, 0))
+	if period != "2026-10-19" || !since.Equal(day(18, 8, 0)) || !until.Equal(day(19, 8, 0)) || !due {
+		t.Errorf("daily at 8:00: %s %v %v %v", period, since, until, due)
+	}
+
+	_, nextSince, _, _ := digestPeriod(DigestDaily, day(20, 8, 14))
+	_, lateSince, lateUntil, _ := digestPeriod(DigestDaily, day(20, 23, 0))
+	if !nextSince.Equal(until) || !lateSince.Equal(until) || !lateUntil.Equal(day(20, 8, 0)) {
+		t.Errorf("the digests of the 19th and the 20th do not follow each other: %v %v %v", nextSince, lateSince, lateUntil)
+	}
+
+	if _, _, _, due := digestPeriod(DigestDaily, day(21, 7, 59)); due {
+		t.Error("daily digest due before 8:00")
+	}
+
+	for _, now := range []time.Time{day(19, 8, 0), day(21, 12, 0), day(25, 23, 0)} {
+		period, since, until, due := digestPeriod(DigestWeekly, now)
+		if period != "2026-W43" || !since.Equal(day(12, 8, 0)) || !until.Equal(day(19, 8, 0)) || !due {
+			t.Errorf("weekly at %v: %s %v %v %v", now, period, since, until, due)
+		}
+	}
+
+	if period, _, _, due := digestPeriod(DigestWeekly, day(26, 7, 0)); period != "2026-W44" || due {
+		t.Errorf("weekly on monday before 8:00: %s due %v", period, due)
+	}
+}
+
+/**
+mailer of the test keeping the mails instead of sending them
+ */
+type mailbox struct {
+	mu    sync.Mutex
+	mails []Mail
+}
+
+func (mb *mailbox) Send(m Mail) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	mb.mails = append(mb.mails, m)
+	return nil
+}
+
+func (mb *mailbox) received() []Mail {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	return append([]Mail{}, mb.mails...)
+}
+
+func TestDueDigestsAreSentOnce(t *testing.T) {
+	useTestDatabase(t)
+	truncateTables(t, "digest_log", "digest_setting", "follow", "answer", "question_tag", "question", "user")
+	useDigestSigningKey(t, "0123456789abcdef")
+	if err := LoadDigestConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	var box *mailbox = &mailbox{}
+	DigestMailer = box
+
+	var day = func(d int, hour int) time.Time { return time.Date(2026, time.October, d, hour, 0, 0, 0, time.Local) }
+
+	var reader User = User{Name: "reader", Email: "reader@example.com", Password: "-", TokenExpiration: day(19, 0)}
+	var author User = User{Name: "author", Email: "author@example.com", Password: "-", TokenExpiration: day(19, 0)}
+	for _, u := range []*User{&reader, &author} {
+		if err := DBMap.Insert(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var question Question = Question{Question: "which answers make the digest?", UserId: author.Id, State: QuestionOpen, CreatedAt: day(18, 10), LastActivityAt: day(18, 10)}
+	if err := DBMap.Insert(&question); err != nil {
+		t.Fatal(err)
+	}
+
+	// one answer in the span of the digest of the 20th, one after it in the span of the 21st
+	for _, a := range []Answer{{Answer: "the one of the 19th", CreatedAt: day(19, 12)}, {Answer: "the one of the 20th", CreatedAt: day(20, 8)}} {
+		a.QuestionId, a.UserId = question.Id, author.Id
+		if err := DBMap.Insert(&a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var follow Follow = NewQuestionFollow(reader, question)
+	if err := follow.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetDigestFrequency(reader.Id, DigestDaily); err != nil {
+		t.Fatal(err)
+	}
+
+	// two schedulers sending at the same time
+	var wg sync.WaitGroup
+	var sent [2]int
+	for i := range sent {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			n, err := SendDueDigests(day(20, 9))
+			if err != nil {
+				t.Error(err)
+			}
+			sent[i] = n
+		}(i)
+	}
+	wg.Wait()
+
+	if n, err := SendDueDigests(day(20, 23)); err != nil || sent[0]+sent[1]+n != 1 {
+		t.Fatalf("sent %v then %d, %v, want a single digest", sent, n, err)
+	}
+
+	if n, err := SendDueDigests(day(21, 8)); err != nil || n != 1 {
+		t.Fatalf("sent %d, %v on the 21st, want its digest", n, err)
+	}
+
+	var mails []Mail = box.received()
+	if len(mails) != 2 {
+		t.Fatalf("mailed %d digests, want 2", len(mails))
+	}
+	for i, expected := range []string{"the one of the 19th", "the one of the 20th"} {
+		var other string = []string{"the one of the 20th", "the one of the 19th"}[i]
+		if mails[i].To != reader.Email || !strings.Contains(mails[i].Body, expected) || strings.Contains(mails[i].Body, other) {
+			t.Errorf("digest %d to %s: %s, want %q only", i, mails[i].To, mails[i].Body, expected)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"regexp"
+)
+
+/**
+entry of the error catalog: Code is stable and meant for the clients to branch on,
+Title is the human message of it
+ */
+type ErrorCode struct {
+	Code   string
+	Status int
+	Title  string
+}
+
+// the error catalog, every error response of the api is one of these
+var (
+	CodeMethodNotAllowed     = ErrorCode{"method_not_allowed", http.StatusMethodNotAllowed, "method is not allowed"}
+	CodeRouteNotFound        = ErrorCode{"route_not_found", http.StatusNotFound, "no resource at this path"}
+	CodeInternal             = ErrorCode{"internal_error", http.StatusInternalServerError, "internal error"}
+	CodeMalformedBody        = ErrorCode{"malformed_body", http.StatusBadRequest, "request body is not a json object"}
+	CodeBodyTooLarge         = ErrorCode{"body_too_large", http.StatusRequestEntityTooLarge, "request body is too large"}
+	CodeValidationFailed     = ErrorCode{"validation_failed", http.StatusUnprocessableEntity, "request fields are invalid"}
+	CodeAccessForbidden      = ErrorCode{"access_forbidden", http.StatusForbidden, "access forbidden"}
+	CodeModeratorRequired    = ErrorCode{"moderator_required", http.StatusForbidden, "only moderators are allowed to do this"}
+	CodeNotOwner             = ErrorCode{"not_owner", http.StatusForbidden, "only the author is allowed to do this"}
+	CodeInvalidCredentials   = ErrorCode{"invalid_credentials", http.StatusExpectationFailed, "email or password is wrong"}
+	CodeEmailTaken           = ErrorCode{"email_taken", http.StatusConflict, "email is already registered"}
+	CodeRateExists           = ErrorCode{"rate_exists", http.StatusConflict, "answer has already been rated"}
+	CodeVoteExists           = ErrorCode{"vote_exists", http.StatusConflict, "question has already been voted"}
+	CodeConflict             = ErrorCode{"conflict", http.StatusConflict, "entity has already been found"}
+	CodeUserNotFound         = ErrorCode{"user_not_found", http.StatusExpectationFailed, "user has not been found"}
+	CodeQuestionNotFound     = ErrorCode{"question_not_found", http.StatusExpectationFailed, "question has not been found"}
+	CodeAnswerNotFound       = ErrorCode{"answer_not_found", http.StatusExpectationFailed, "answer has not been found"}
+	CodeInvalidCursor        = ErrorCode{"invalid_cursor", http.StatusExpectationFailed, "invalid cursor"}
+	CodeInvalidDuplicate     = ErrorCode{"invalid_duplicate", http.StatusExpectationFailed, "question can not be the duplicate of that one"}
+	CodeInvalidTransition    = ErrorCode{"invalid_transition", http.StatusConflict, "question state can not be changed that way"}
+	CodeInvalidCloseReason   = ErrorCode{"invalid_close_reason", http.StatusExpectationFailed, "invalid close reason"}
+	CodeMissingDuplicate     = ErrorCode{"missing_duplicate", http.StatusExpectationFailed, "duplicate close reason requires the canonical question"}
+	CodeQuestionNotWritable  = ErrorCode{"question_not_writable", http.StatusLocked, "question does not accept answers and rates"}
+	CodeInvalidQuery         = ErrorCode{"invalid_query", http.StatusBadRequest, "graphql query is invalid"}
+	CodeWebhookNotFound      = ErrorCode{"webhook_not_found", http.StatusExpectationFailed, "webhook has not been found"}
+	CodeDeliveryNotFound     = ErrorCode{"delivery_not_found", http.StatusExpectationFailed, "webhook delivery has not been found"}
+	CodeNotificationNotFound = ErrorCode{"notification_not_found", http.StatusExpectationFailed, "notification has not been found"}
+	CodeInvalidUnsubscribe   = ErrorCode{"invalid_unsubscribe_token", http.StatusExpectationFailed, "unsubscribe link is invalid"}
+)
+
+/**
+error response body as RFC 7807 problem details, extended by the code of the catalog,
+the path of the failed field and the id of the request
+ */
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	Field     string       `json:"field,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	RequestId string       `json:"request_id,omitempty"`
+}
+
+/**
+error of the actions shared by the apis (PostAnswerAs, RateAnswerAs, ...), tells the catalog entry
+and the failed field so every api reports it the same way
+ */
+type ActionError struct {
+	Code   ErrorCode
+	Field  string
+	Detail string
+}
+
+func (e ActionError) Error() string {
+	if e.Detail != "" {
+		return e.Code.Title + ": " + e.Detail
+	}
+
+	return e.Code.Title
+}
+
+/**
+catalog entry and problem details of an error of the actions or of the question state machine,
+CodeInternal for anything else
+ */
+func problemOf(err error) (ErrorCode, Problem) {
+	switch e := err.(type) {
+	case ActionError:
+		return e.Code, Problem{Field: e.Field, Detail: e.Detail}
+	case QuestionStateError:
+		return CodeQuestionNotWritable, Problem{Detail: e.Error()}
+	}
+
+	switch err {
+	case ErrInvalidTransition:
+		return CodeInvalidTransition, Problem{}
+	case ErrInvalidCloseReason:
+		return CodeInvalidCloseReason, Problem{Field: "reason"}
+	case ErrMissingDuplicate:
+		return CodeMissingDuplicate, Problem{Field: "duplicate_of"}
+	case ErrConflict:
+		return CodeConflict, Problem{}
+	case ErrInvalidCursor:
+		return CodeInvalidCursor, Problem{Field: "cursor"}
+	}
+
+	return CodeInternal, Problem{}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, code ErrorCode) {
+	writeProblem(w, r, code, Problem{})
+}
+
+func writeFieldError(w http.ResponseWriter, r *http.Request, code ErrorCode, field string) {
+	writeProblem(w, r, code, Problem{Field: field})
+}
+
+func writeActionError(w http.ResponseWriter, r *http.Request, err error) {
+	code, problem := problemOf(err)
+	writeProblem(w, r, code, problem)
+}
+
+/**
+completes the problem by the catalog entry and the request and writes it as application/problem+json
+ */
+func writeProblem(w http.ResponseWriter, r *http.Request, code ErrorCode, problem Problem) {
+	problem.Type = "/problems/" + code.Code
+	problem.Title = code.Title
+	problem.Status = code.Status
+	problem.Code = code.Code
+	problem.Instance = r.URL.Path
+	problem.RequestId = RequestIdFrom(r)
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(code.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+const requestIdKey contextKey = "request-id"
+
+var requestIdPattern *regexp.Regexp = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+/**
+tags every request by an id, taken from the X-Request-Id header when the client sent a sane one,
+the id is echoed in the response header and in the problem details
+ */
+func WithRequestId(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var id string = r.Header.Get("X-Request-Id")
+
+		if !requestIdPattern.MatchString(id) {
+			var b []byte = make([]byte, 8)
+			rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+
+		w.Header().Set("X-Request-Id", id)
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIdKey, id)))
+	})
+}
+
+func RequestIdFrom(r *http.Request) string {
+	id, _ := r.Context().Value(requestIdKey).(string)
+	return id
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const (
+	// an outbox event is claimed by the process dispatching it, the relay leaves it alone until the
+	// claim is this old. the claim is renewed while the subscribers of the event are running
+	outboxClaimLease    = time.Minute
+	outboxRelayInterval = time.Minute
+)
+
+const (
+	EventQuestionPosted       = "question.posted"
+	EventQuestionUpdated      = "question.updated"
+	EventQuestionStateChanged = "question.state_changed"
+	EventAnswerPosted         = "answer.posted"
+	EventAnswerUpdated        = "answer.updated"
+	EventAnswerRated          = "answer.rated"
+	EventAnswerAccepted       = "answer.accepted"
+)
+
+/**
+fact about a change of the models, recorded by the unit of work of the change and published
+on Bus once it is committed. the events carry ids, the subscribers load what they need
+ */
+type DomainEvent interface {
+	EventName() string
+}
+
+type QuestionPosted struct {
+	QuestionId int64 `json:"question_id"`
+	UserId     int64 `json:"user_id"`
+}
+
+/**
+any save of an existing question, Deleted tells a soft delete from the other changes
+ */
+type QuestionUpdated struct {
+	QuestionId int64 `json:"question_id"`
+	Deleted    bool  `json:"deleted"`
+}
+
+type QuestionStateChanged struct {
+	QuestionId int64  `json:"question_id"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	ByUserId   int64  `json:"by_user_id"`
+}
+
+type AnswerPosted struct {
+	AnswerId   int64 `json:"answer_id"`
+	QuestionId int64 `json:"question_id"`
+	UserId     int64 `json:"user_id"`
+}
+
+type AnswerUpdated struct {
+	AnswerId   int64 `json:"answer_id"`
+	QuestionId int64 `json:"question_id"`
+	Deleted    bool  `json:"deleted"`
+}
+
+type AnswerRated struct {
+	AnswerId   int64 `json:"answer_id"`
+	QuestionId int64 `json:"question_id"`
+	UserId     int64 `json:"user_id"`
+	Rate       int64 `json:"rate"`
+}
+
+type AnswerAccepted struct {
+	AnswerId   int64 `json:"answer_id"`
+	QuestionId int64 `json:"question_id"`
+	ByUserId   int64 `json:"by_user_id"`
+}
+
+func (e QuestionPosted) EventName() string       { return EventQuestionPosted }
+func (e QuestionUpdated) EventName() string      { return EventQuestionUpdated }
+func (e QuestionStateChanged) EventName() string { return EventQuestionStateChanged }
+func (e AnswerPosted) EventName() string         { return EventAnswerPosted }
+func (e AnswerUpdated) EventName() string        { return EventAnswerUpdated }
+func (e AnswerRated) EventName() string          { return EventAnswerRated }
+func (e AnswerAccepted) EventName() string       { return EventAnswerAccepted }
+
+// event types by name, to decode the events of the outbox
+var domainEventTypes map[string]reflect.Type = map[string]reflect.Type{
+	EventQuestionPosted:       reflect.TypeOf(QuestionPosted{}),
+	EventQuestionUpdated:      reflect.TypeOf(QuestionUpdated{}),
+	EventQuestionStateChanged: reflect.TypeOf(QuestionStateChanged{}),
+	EventAnswerPosted:         reflect.TypeOf(AnswerPosted{}),
+	EventAnswerUpdated:        reflect.TypeOf(AnswerUpdated{}),
+	EventAnswerRated:          reflect.TypeOf(AnswerRated{}),
+	EventAnswerAccepted:       reflect.TypeOf(AnswerAccepted{}),
+}
+
+type EventHandler func(e DomainEvent) error
+
+type eventSubscriber struct {
+	name   string
+	handle EventHandler
+}
+
+/**
+in-process publish and subscribe of the domain events. the synchronous subscribers run one after
+the other before Publish returns, the asynchronous ones each on their own goroutine without
+ordering between events. a failing or panicking subscriber is logged and does not affect the others
+ */
+type EventBus struct {
+	mu    sync.RWMutex
+	sync  map[string][]eventSubscriber
+	async map[string][]eventSubscriber
+}
+
+var Bus *EventBus = NewEventBus()
+
+func NewEventBus() *EventBus {
+	return &EventBus{sync: make(map[string][]eventSubscriber), async: make(map[string][]eventSubscriber)}
+}
+
+func (b *EventBus) Subscribe(name string, h EventHandler, events ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, event := range events {
+		b.sync[event] = append(b.sync[event], eventSubscriber{name, h})
+	}
+}
+
+func (b *EventBus) SubscribeAsync(name string, h EventHandler, events ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, event := range events {
+		b.async[event] = append(b.async[event], eventSubscriber{name, h})
+	}
+}
+
+/**
+runs the synchronous subscribers of the event and starts the asynchronous ones,
+the returned group is done once the asynchronous ones have finished
+ */
+func (b *EventBus) Publish(e DomainEvent) *sync.WaitGroup {
+	var wg *sync.WaitGroup = &sync.WaitGroup{}
+
+	b.mu.RLock()
+	var syncSubscribers []eventSubscriber = b.sync[e.EventName()]
+	var asyncSubscribers []eventSubscriber = b.async[e.EventName()]
+	b.mu.RUnlock()
+
+	for _, sub := range syncSubscribers {
+		runSubscriber(sub, e)
+	}
+
+	for _, sub := range asyncSubscribers {
+		wg.Add(1)
+		go func(sub eventSubscriber) {
+			defer wg.Done()
+			runSubscriber(sub, e)
+		}(sub)
+	}
+
+	return wg
+}
+
+func runSubscriber(sub eventSubscriber, e DomainEvent) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("event subscriber %s panicked on %s: %v", sub.name, e.EventName(), p)
+		}
+	}()
+
+	err := sub.handle(e)
+	if err != nil {
+		log.Printf("event subscriber %s failed on %s: %v", sub.name, e.EventName(), err)
+	}
+}
+
+/**
+event recorded in the transaction of the change, so a committed change always has its event.
+ClaimedAt is the last sign of life of the dispatch, DispatchedAt is set once every subscriber
+has handled it. the pending ones whose claim has expired are published again by RelayOutbox
+ */
+type OutboxEvent struct {
+	Id           int64      `db:"id, primarykey, autoincrement" json:"id"`
+	Name         string     `db:"name, size:64, notnull" json:"name"`
+	Payload      string     `db:"payload, size:4096, notnull" json:"payload"`
+	CreatedAt    time.Time  `db:"created_at, notnull" json:"created_at"`
+	ClaimedAt    *time.Time `db:"claimed_at" json:"claimed_at,omitempty"`
+	DispatchedAt *time.Time `db:"dispatched_at" json:"dispatched_at,omitempty"`
+}
+
+func NewOutboxEvent(e DomainEvent) (OutboxEvent, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+
+	// the event is claimed by the unit of work recording it, which dispatches it after the commit
+	var now time.Time = time.Now()
+
+	return OutboxEvent{Name: e.EventName(), Payload: string(payload), CreatedAt: now, ClaimedAt: &now}, nil
+}
+
+func (oe OutboxEvent) Event() (DomainEvent, error) {
+	t, ok := domainEventTypes[oe.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown event %s", oe.Name)
+	}
+
+	var v reflect.Value = reflect.New(t)
+	err := json.Unmarshal([]byte(oe.Payload), v.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Elem().Interface().(DomainEvent), nil
+}
+
+/**
+claims the pending event for a dispatch unless the claim of another one has not expired yet
+ */
+func (oe OutboxEvent) claim() (bool, error) {
+	var now time.Time = time.Now()
+
+	result, err := DBMap.Exec("UPDATE event_outbox SET claimed_at = ? WHERE id = ? AND dispatched_at IS NULL AND (claimed_at IS NULL OR claimed_at < ?)", now, oe.Id, now.Add(-outboxClaimLease))
+	if err != nil {
+		return false, err
+	}
+
+	claimed, err := result.RowsAffected()
+
+	return claimed == 1, err
+}
+
+/**
+keeps the claim of the event from expiring until done is closed
+ */
+func (oe OutboxEvent) renewClaim(done chan struct{}) {
+	var ticker *time.Ticker = time.NewTicker(outboxClaimLease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			_, err := DBMap.Exec("UPDATE event_outbox SET claimed_at = ? WHERE id = ? AND dispatched_at IS NULL", time.Now(), oe.Id)
+			if err != nil {
+				log.Printf("renewing the claim of event %d failed: %v", oe.Id, err)
+			}
+		}
+	}
+}
+
+/**
+publishes the claimed event and marks it dispatched when its subscribers are done, wait blocks until then.
+the claim is renewed meanwhile, so the relay does not publish the event again while the subscribers run
+ */
+func (oe OutboxEvent) dispatch(e DomainEvent, wait bool) {
+	var done chan struct{} = make(chan struct{})
+	go oe.renewClaim(done)
+
+	var wg *sync.WaitGroup = Bus.Publish(e)
+
+	var markDispatched func() = func() {
+		wg.Wait()
+		close(done)
+		_, err := DBMap.Exec("UPDATE event_outbox SET dispatched_at = ? WHERE id = ? AND dispatched_at IS NULL", time.Now(), oe.Id)
+		if err != nil {
+			log.Printf("marking event %d dispatched failed: %v", oe.Id, err)
+		}
+	}
+
+	if wait {
+		markDispatched()
+	} else {
+		go markDispatched()
+	}
+}
+
+/**
+publishes the outbox events not dispatched yet whose claim has expired, the ones of a process which
+stopped between the commit and the end of the dispatch. an event is only published by the relay
+which claims it. returns how many were published
+ */
+func RelayOutbox() (int, error) {
+	var pending []OutboxEvent
+	var published int
+
+	_, err := DBMap.Select(&pending, "SELECT * FROM event_outbox WHERE dispatched_at IS NULL AND (claimed_at IS NULL OR claimed_at < ?) ORDER BY id", time.Now().Add(-outboxClaimLease))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, oe := range pending {
+		e, err := oe.Event()
+		if err != nil {
+			log.Printf("skipping outbox event %d: %v", oe.Id, err)
+			continue
+		}
+
+		claimed, err := oe.claim()
+		if err != nil {
+			return published, err
+		}
+		if !claimed {
+			continue
+		}
+
+		oe.dispatch(e, true)
+		published++
+	}
+
+	return published, nil
+}
+
+func relayOutboxPeriodically(interval time.Duration) {
+	for range time.Tick(interval) {
+		_, err := RelayOutbox()
+		if err != nil {
+			log.Println("relaying outbox events failed:", err)
+		}
+	}
+}
+
+func init() {
+	subscribeSearchIndex(Bus)
+	subscribeCache(Bus)
+	subscribeEventStream(Bus)
+	subscribeWebhooks(Bus)
+	subscribeNotifications(Bus)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func insertOutboxEvent(t *testing.T, claimedAt *time.Time) OutboxEvent {
+	oe, err := NewOutboxEvent(AnswerAccepted{AnswerId: 1, QuestionId: 1, ByUserId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oe.ClaimedAt = claimedAt
+	err = DBMap.Insert(&oe)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return oe
+}
+
+func TestRelayLeavesClaimedEventsAlone(t *testing.T) {
+	useTestDatabase(t)
+	truncateTables(t, "event_outbox")
+
+	var now time.Time = time.Now()
+	var expired time.Time = now.Add(-2 * outboxClaimLease)
+
+	var running OutboxEvent = insertOutboxEvent(t, &now)
+	var abandoned OutboxEvent = insertOutboxEvent(t, &expired)
+	var unclaimed OutboxEvent = insertOutboxEvent(t, nil)
+
+	published, err := RelayOutbox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published != 2 {
+		t.Fatalf("published %d events, want the abandoned and the unclaimed one", published)
+	}
+
+	for _, oe := range []OutboxEvent{running, abandoned, unclaimed} {
+		dispatched, err := DBMap.SelectInt("SELECT COUNT(*) FROM event_outbox WHERE id = ? AND dispatched_at IS NOT NULL", oe.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (dispatched == 1) == (oe.Id == running.Id) {
+			t.Errorf("event %d dispatched: %v", oe.Id, dispatched == 1)
+		}
+	}
+
+	published, err = RelayOutbox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published != 0 {
+		t.Fatalf("published %d events again", published)
+	}
+}
+
+func TestClaimIsTakenOnce(t *testing.T) {
+	useTestDatabase(t)
+	truncateTables(t, "event_outbox")
+
+	var oe OutboxEvent = insertOutboxEvent(t, nil)
+
+	first, err := oe.claim()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := oe.claim()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !first || second {
+		t.Fatalf("claimed %v then %v, want only the first claim to succeed", first, second)
+	}
+}
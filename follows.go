@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	FollowKindQuestion = "question"
+	FollowKindTag      = "tag"
+	FollowKindUser     = "user"
+)
+
+const (
+	FeedKindQuestion = "question"
+	FeedKindAnswer   = "answer"
+)
+
+/**
+question, tag or user followed by a user, only the column of its kind is set.
+Email is the followed user, loaded for the responses
+ */
+type Follow struct {
+	Id         int64     `db:"id, primarykey, autoincrement" json:"id"`
+	UserId     int64     `db:"user_id, notnull" json:"-"`
+	Kind       string    `db:"kind, size:16, notnull" json:"type"`
+	QuestionId int64     `db:"question_id, notnull" json:"question_id,omitempty"`
+	Tag        string    `db:"tag, size:64, notnull" json:"tag,omitempty"`
+	FolloweeId int64     `db:"followee_id, notnull" json:"-"`
+	CreatedAt  time.Time `db:"created_at, notnull" json:"created_at"`
+	Email      string    `db:"-" json:"email,omitempty"`
+}
+
+func NewQuestionFollow(user User, question Question) Follow {
+	return Follow{UserId: user.Id, Kind: FollowKindQuestion, QuestionId: question.Id, CreatedAt: time.Now()}
+}
+
+func NewTagFollow(user User, tag string) Follow {
+	return Follow{UserId: user.Id, Kind: FollowKindTag, Tag: tag, CreatedAt: time.Now()}
+}
+
+func NewUserFollow(user User, followee User) Follow {
+	return Follow{UserId: user.Id, Kind: FollowKindUser, FolloweeId: followee.Id, CreatedAt: time.Now(), Email: followee.Email}
+}
+
+/**
+saves the follow unless the user already follows the same, loads the saved one either way
+ */
+func (f *Follow) Save() error {
+	var email string = f.Email
+
+	_, err := DBMap.Exec("INSERT IGNORE INTO follow (user_id, kind, question_id, tag, followee_id, created_at) VALUES (?, ?, ?, ?, ?, ?)", f.UserId, f.Kind, f.QuestionId, f.Tag, f.FolloweeId, f.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	err = DBMap.SelectOne(f, "SELECT * FROM follow WHERE user_id = ? AND kind = ? AND question_id = ? AND tag = ? AND followee_id = ?", f.UserId, f.Kind, f.QuestionId, f.Tag, f.FolloweeId)
+	f.Email = email
+
+	return err
+}
+
+/**
+removes the follow of the user on the same, whether it existed or not
+ */
+func (f Follow) Delete() error {
+	_, err := DBMap.Exec("DELETE FROM follow WHERE user_id = ? AND kind = ? AND question_id = ? AND tag = ? AND followee_id = ?", f.UserId, f.Kind, f.QuestionId, f.Tag, f.FolloweeId)
+
+	return err
+}
+
+/**
+follows of the user, latest first, with the emails of the followed users
+ */
+func (f Follow) GetByUser(user User, cursor string, limit int) ([]Follow, PageInfo, error) {
+	var follows []Follow = []Follow{}
+	var followeeIds []int64
+
+	ids, info, err := keysetQuery{Table: "follow", SortExpr: "follow.id", SortKind: sortKindInt, Conditions: []string{"user_id = ?"}, Args: []interface{}{user.Id}, Desc: true, Cursor: cursor, Limit: limit, Count: true}.Ids()
+	if err != nil || len(ids) == 0 {
+		return follows, info, err
+	}
+
+	placeholders, args := inIds(ids)
+	_, err = DBMap.Select(&follows, fmt.Sprintf("SELECT * FROM follow WHERE id IN (%s) ORDER BY FIELD(id, %s)", placeholders, placeholders), append(args, args...)...)
+	if err != nil {
+		return follows, info, err
+	}
+
+	for _, follow := range follows {
+		if follow.Kind == FollowKindUser {
+			followeeIds = append(followeeIds, follow.FolloweeId)
+		}
+	}
+
+	users, err := selectUsers(followeeIds)
+	for k := range follows {
+		if users[follows[k].FolloweeId] != nil {
+			follows[k].Email = users[follows[k].FolloweeId].Email
+		}
+	}
+
+	return follows, info, err
+}
+
+/**
+question or answer of a feed, Question is the question of an answer too
+ */
+type FeedItem struct {
+	Kind       string    `json:"type"`
+	Id         int64     `json:"id"`
+	QuestionId int64     `json:"question_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	Question   *Question `json:"question,omitempty"`
+	Answer     *Answer   `json:"answer,omitempty"`
+}
+
+type feedRow struct {
+	Kind       string    `db:"kind"`
+	Id         int64     `db:"id"`
+	QuestionId int64     `db:"question_id"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+/**
+the questions posted in the followed tags and by the followed users and the answers on the followed
+questions, in the followed tags and by the followed users, latest first. the posts of the user
+itself are left out. the pages are cut by (created_at, id, type) over both tables
+ */
+func Feed(user User, token string, limit int) ([]FeedItem, PageInfo, error) {
+	var info PageInfo
+	var rows []feedRow
+	var cursor Cursor
+	var err error
+
+	var followedQuestions string = "SELECT question_id FROM follow WHERE user_id = ? AND kind = 'question'"
+	var followedTags string = "SELECT question_id FROM question_tag WHERE tag IN (SELECT tag FROM follow WHERE user_id = ? AND kind = 'tag')"
+	var followedUsers string = "SELECT followee_id FROM follow WHERE user_id = ? AND kind = 'user'"
+
+	var conditions []string
+	// the user is left out and its follows are looked up in both parts of the union
+	var args []interface{} = []interface{}{user.Id, user.Id, user.Id, user.Id, user.Id, user.Id, user.Id}
+
+	if token != "" {
+		cursor, err = DecodeCursor(token)
+		if err != nil {
+			return []FeedItem{}, info, err
+		}
+
+		key, err := parseSortKey(cursor.Key, sortKindTime)
+		if err != nil {
+			return []FeedItem{}, info, err
+		}
+
+		var cmp string = "<"
+		if cursor.Before {
+			cmp = ">"
+		}
+		conditions = append(conditions, fmt.Sprintf("(created_at %[1]s ? OR (created_at = ? AND (id %[1]s ? OR (id = ? AND kind %[1]s ?))))", cmp))
+		args = append(args, key, key, cursor.Id, cursor.Id, cursor.Kind)
+	}
+
+	var order string = "DESC"
+	if cursor.Before {
+		order = "ASC"
+	}
+
+	var where string = ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`SELECT kind, id, question_id, created_at FROM (
+		SELECT 'question' AS kind, question.id AS id, question.id AS question_id, question.created_at AS created_at FROM question
+			WHERE question.deleted_at IS NULL AND question.user_id <> ? AND (question.id IN (%[1]s) OR question.user_id IN (%[2]s))
+		UNION ALL
+		SELECT 'answer' AS kind, answer.id AS id, answer.question_id AS question_id, answer.created_at AS created_at FROM answer
+			JOIN question ON question.id = answer.question_id AND question.deleted_at IS NULL
+			WHERE answer.deleted_at IS NULL AND answer.user_id <> ? AND (answer.question_id IN (%[3]s) OR answer.question_id IN (%[1]s) OR answer.user_id IN (%[2]s))
+	) AS feed%[4]s ORDER BY created_at %[5]s, id %[5]s, kind %[5]s LIMIT %[6]d`, followedTags, followedUsers, followedQuestions, where, order, limit+1)
+
+	_, err = DBMap.Select(&rows, query, args...)
+	if err != nil {
+		return []FeedItem{}, info, err
+	}
+
+	var more bool = len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+
+	if cursor.Before {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	if len(rows) == 0 {
+		return []FeedItem{}, info, nil
+	}
+
+	first, last := rows[0], rows[len(rows)-1]
+	if (!cursor.Before && more) || cursor.Before {
+		info.Next = EncodeCursor(feedCursor(last, false))
+	}
+	if (cursor.Before && more) || (!cursor.Before && token != "") {
+		info.Prev = EncodeCursor(feedCursor(first, true))
+	}
+
+	items, err := loadFeedItems(rows)
+
+	return items, info, err
+}
+
+func feedCursor(row feedRow, before bool) Cursor {
+	return Cursor{Key: row.CreatedAt.Format(time.RFC3339Nano), Id: row.Id, Kind: row.Kind, Before: before}
+}
+
+/**
+loads the questions and answers of the rows with their authors, the questions with their tags
+ */
+func loadFeedItems(rows []feedRow) ([]FeedItem, error) {
+	var items []FeedItem = []FeedItem{}
+	var questionIds []int64
+	var answerIds []int64
+	var questionsById map[int64]*Question = make(map[int64]*Question)
+	var answersById map[int64]*Answer = make(map[int64]*Answer)
+
+	for _, row := range rows {
+		questionIds = append(questionIds, row.QuestionId)
+		if row.Kind == FeedKindAnswer {
+			answerIds = append(answerIds, row.Id)
+		}
+	}
+
+	questions, err := selectQuestions(uniqueIds(questionIds))
+	if err != nil {
+		return items, err
+	}
+
+	answers, err := selectAnswers(answerIds)
+	if err != nil {
+		return items, err
+	}
+
+	attachQuestionAuthors(questions)
+	attachQuestionTags(questions)
+	attachAnswerAuthors(answers)
+
+	for k := range questions {
+		questionsById[questions[k].Id] = &questions[k]
+	}
+
+	for k := range answers {
+		answersById[answers[k].Id] = &answers[k]
+	}
+
+	for _, row := range rows {
+		var item FeedItem = FeedItem{Kind: row.Kind, Id: row.Id, QuestionId: row.QuestionId, CreatedAt: row.CreatedAt, Question: questionsById[row.QuestionId]}
+
+		if row.Kind == FeedKindAnswer {
+			item.Answer = answersById[row.Id]
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,1069 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// selection sets nested deeper than this are refused
+	graphqlMaxDepth = 8
+
+	// fields a query may resolve at most, list fields count as many times as they may return items
+	graphqlMaxComplexity = 5000
+
+	// items expected from a list field without a first argument, for the complexity
+	graphqlDefaultListSize = 10
+)
+
+/**
+parsed query of the graphql language subset the api speaks: queries and mutations with variables,
+aliases, arguments, named and inline fragments and the @include and @skip directives.
+there is no introspection, the schema is described in graphql_schema.go
+ */
+type gqlDocument struct {
+	operations []*gqlOperation
+	fragments  map[string]*gqlFragment
+}
+
+type gqlOperation struct {
+	kind       string
+	name       string
+	variables  []gqlVariableDef
+	selections []gqlSelection
+}
+
+type gqlVariableDef struct {
+	name         string
+	typ          string
+	defaultValue interface{}
+}
+
+type gqlFragment struct {
+	name          string
+	typeCondition string
+	selections    []gqlSelection
+}
+
+/**
+field, fragment spread (spread set) or inline fragment (inline set) of a selection set
+ */
+type gqlSelection struct {
+	alias      string
+	name       string
+	args       map[string]interface{}
+	directives []gqlDirective
+	selections []gqlSelection
+	spread     string
+	inline     bool
+	typeCond   string
+}
+
+type gqlDirective struct {
+	name string
+	args map[string]interface{}
+}
+
+// variable reference and enum value in the arguments, resolved by coerceValue
+type gqlVariable string
+type gqlEnum string
+
+func (s gqlSelection) responseKey() string {
+	if s.alias != "" {
+		return s.alias
+	}
+
+	return s.name
+}
+
+type gqlToken struct {
+	kind  byte // n name, i int, f float, s string, p punctuator, e end
+	value string
+	pos   int
+}
+
+type gqlParser struct {
+	src string
+	pos int
+	tok gqlToken
+}
+
+/**
+error of the query text or of its validation against the schema
+ */
+type gqlSyntaxError struct {
+	Message string
+}
+
+func (e gqlSyntaxError) Error() string {
+	return e.Message
+}
+
+func parseGraphQL(src string) (doc *gqlDocument, err error) {
+	var p *gqlParser = &gqlParser{src: src}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if se, ok := r.(gqlSyntaxError); ok {
+				doc, err = nil, se
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	doc = &gqlDocument{fragments: make(map[string]*gqlFragment)}
+	p.next()
+
+	for p.tok.kind != 'e' {
+		switch {
+		case p.tok.kind == 'p' && p.tok.value == "{":
+			doc.operations = append(doc.operations, &gqlOperation{kind: "query", selections: p.selectionSet()})
+		case p.tok.kind == 'n' && (p.tok.value == "query" || p.tok.value == "mutation"):
+			doc.operations = append(doc.operations, p.operation())
+		case p.tok.kind == 'n' && p.tok.value == "fragment":
+			var fragment *gqlFragment = p.fragment()
+			doc.fragments[fragment.name] = fragment
+		default:
+			p.fail("unexpected %q", p.tok.value)
+		}
+	}
+
+	if len(doc.operations) == 0 {
+		p.fail("no operation in the document")
+	}
+
+	return doc, nil
+}
+
+func (p *gqlParser) fail(format string, args ...interface{}) {
+	panic(gqlSyntaxError{fmt.Sprintf("syntax error at %d: ", p.tok.pos) + fmt.Sprintf(format, args...)})
+}
+
+func (p *gqlParser) operation() *gqlOperation {
+	var op *gqlOperation = &gqlOperation{kind: p.tok.value}
+	p.next()
+
+	if p.tok.kind == 'n' {
+		op.name = p.tok.value
+		p.next()
+	}
+
+	if p.peek("(") {
+		p.next()
+		for !p.peek(")") {
+			var def gqlVariableDef
+			p.expect("$")
+			def.name = p.name()
+			p.expect(":")
+			def.typ = p.typeRef()
+			if p.peek("=") {
+				p.next()
+				def.defaultValue = p.value(true)
+			}
+			op.variables = append(op.variables, def)
+		}
+		p.next()
+	}
+
+	p.directives()
+	op.selections = p.selectionSet()
+
+	return op
+}
+
+func (p *gqlParser) fragment() *gqlFragment {
+	var fragment *gqlFragment = &gqlFragment{}
+
+	p.next()
+	fragment.name = p.name()
+	if p.tok.value != "on" {
+		p.fail("expected on")
+	}
+	p.next()
+	fragment.typeCondition = p.name()
+	p.directives()
+	fragment.selections = p.selectionSet()
+
+	return fragment
+}
+
+func (p *gqlParser) typeRef() string {
+	var ref string
+
+	if p.peek("[") {
+		p.next()
+		ref = "[" + p.typeRef() + "]"
+		p.expect("]")
+	} else {
+		ref = p.name()
+	}
+
+	if p.peek("!") {
+		p.next()
+		ref += "!"
+	}
+
+	return ref
+}
+
+func (p *gqlParser) selectionSet() []gqlSelection {
+	var selections []gqlSelection
+
+	p.expect("{")
+	for !p.peek("}") {
+		selections = append(selections, p.selection())
+	}
+	p.next()
+
+	if len(selections) == 0 {
+		p.fail("empty selection set")
+	}
+
+	return selections
+}
+
+func (p *gqlParser) selection() gqlSelection {
+	var sel gqlSelection
+
+	if p.peek("...") {
+		p.next()
+		if p.tok.kind == 'n' && p.tok.value != "on" {
+			sel.spread = p.name()
+			sel.directives = p.directives()
+			return sel
+		}
+
+		sel.inline = true
+		if p.tok.value == "on" {
+			p.next()
+			sel.typeCond = p.name()
+		}
+		sel.directives = p.directives()
+		sel.selections = p.selectionSet()
+		return sel
+	}
+
+	sel.name = p.name()
+	if p.peek(":") {
+		p.next()
+		sel.alias, sel.name = sel.name, p.name()
+	}
+
+	sel.args = p.arguments()
+	sel.directives = p.directives()
+
+	if p.peek("{") {
+		sel.selections = p.selectionSet()
+	}
+
+	return sel
+}
+
+func (p *gqlParser) arguments() map[string]interface{} {
+	var args map[string]interface{} = make(map[string]interface{})
+
+	if !p.peek("(") {
+		return args
+	}
+
+	p.next()
+	for !p.peek(")") {
+		var name string = p.name()
+		p.expect(":")
+		args[name] = p.value(false)
+	}
+	p.next()
+
+	return args
+}
+
+func (p *gqlParser) directives() []gqlDirective {
+	var directives []gqlDirective
+
+	for p.peek("@") {
+		p.next()
+		var directive gqlDirective = gqlDirective{name: p.name()}
+		directive.args = p.arguments()
+		directives = append(directives, directive)
+	}
+
+	return directives
+}
+
+func (p *gqlParser) value(constant bool) interface{} {
+	var tok gqlToken = p.tok
+
+	switch {
+	case tok.kind == 'p' && tok.value == "$" && !constant:
+		p.next()
+		return gqlVariable(p.name())
+	case tok.kind == 'i':
+		p.next()
+		i, err := strconv.ParseInt(tok.value, 10, 64)
+		if err != nil {
+			p.fail("invalid int %s", tok.value)
+		}
+		return i
+	case tok.kind == 'f':
+		p.next()
+		f, _ := strconv.ParseFloat(tok.value, 64)
+		return f
+	case tok.kind == 's':
+		p.next()
+		return tok.value
+	case tok.kind == 'n':
+		p.next()
+		switch tok.value {
+		case "true":
+			return true
+		case "false":
+			return false
+		case "null":
+			return nil
+		}
+		return gqlEnum(tok.value)
+	case tok.kind == 'p' && tok.value == "[":
+		var list []interface{} = []interface{}{}
+		p.next()
+		for !p.peek("]") {
+			list = append(list, p.value(constant))
+		}
+		p.next()
+		return list
+	case tok.kind == 'p' && tok.value == "{":
+		var object map[string]interface{} = make(map[string]interface{})
+		p.next()
+		for !p.peek("}") {
+			var name string = p.name()
+			p.expect(":")
+			object[name] = p.value(constant)
+		}
+		p.next()
+		return object
+	}
+
+	p.fail("unexpected %q", tok.value)
+	return nil
+}
+
+func (p *gqlParser) peek(punctuator string) bool {
+	return p.tok.kind == 'p' && p.tok.value == punctuator
+}
+
+func (p *gqlParser) expect(punctuator string) {
+	if !p.peek(punctuator) {
+		p.fail("expected %q, found %q", punctuator, p.tok.value)
+	}
+	p.next()
+}
+
+func (p *gqlParser) name() string {
+	if p.tok.kind != 'n' {
+		p.fail("expected a name, found %q", p.tok.value)
+	}
+
+	var name string = p.tok.value
+	p.next()
+
+	return name
+}
+
+/**
+reads the next token, commas and comments are insignificant
+ */
+func (p *gqlParser) next() {
+	for p.pos < len(p.src) {
+		var c byte = p.src[p.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			p.pos++
+		} else if c == '#' {
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		} else {
+			break
+		}
+	}
+
+	var start int = p.pos
+	if p.pos >= len(p.src) {
+		p.tok = gqlToken{kind: 'e', pos: start}
+		return
+	}
+
+	var c byte = p.src[p.pos]
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "..."):
+		p.pos += 3
+		p.tok = gqlToken{kind: 'p', value: "...", pos: start}
+	case strings.IndexByte("!$():=@[]{}|", c) >= 0:
+		p.pos++
+		p.tok = gqlToken{kind: 'p', value: string(c), pos: start}
+	case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		for p.pos < len(p.src) && isNameChar(p.src[p.pos]) {
+			p.pos++
+		}
+		p.tok = gqlToken{kind: 'n', value: p.src[start:p.pos], pos: start}
+	case c == '-' || (c >= '0' && c <= '9'):
+		var kind byte = 'i'
+		p.pos++
+		for p.pos < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0 {
+			if strings.IndexByte(".eE", p.src[p.pos]) >= 0 {
+				kind = 'f'
+			}
+			p.pos++
+		}
+		p.tok = gqlToken{kind: kind, value: p.src[start:p.pos], pos: start}
+	case c == '"':
+		p.tok = gqlToken{kind: 's', value: p.stringValue(), pos: start}
+	default:
+		p.tok = gqlToken{kind: 'p', value: string(c), pos: start}
+		p.fail("unexpected character %q", c)
+	}
+}
+
+func (p *gqlParser) stringValue() string {
+	var sb strings.Builder
+
+	p.pos++
+	for {
+		if p.pos >= len(p.src) || p.src[p.pos] == '\n' {
+			p.fail("unterminated string")
+		}
+
+		var c byte = p.src[p.pos]
+		if c == '"' {
+			p.pos++
+			return sb.String()
+		}
+
+		if c != '\\' {
+			r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+			sb.WriteRune(r)
+			p.pos += size
+			continue
+		}
+
+		if p.pos+1 >= len(p.src) {
+			p.fail("unterminated string")
+		}
+
+		switch p.src[p.pos+1] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if p.pos+6 > len(p.src) {
+				p.fail("invalid unicode escape")
+			}
+			code, err := strconv.ParseUint(p.src[p.pos+2:p.pos+6], 16, 32)
+			if err != nil {
+				p.fail("invalid unicode escape")
+			}
+			sb.WriteRune(rune(code))
+			p.pos += 4
+		default:
+			sb.WriteByte(p.src[p.pos+1])
+		}
+		p.pos += 2
+	}
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+/**
+schema of the api: the object types by name and the root types of the operations
+ */
+type gqlSchema struct {
+	types    map[string]*gqlObject
+	query    string
+	mutation string
+}
+
+type gqlObject struct {
+	name   string
+	fields map[string]*gqlField
+}
+
+/**
+resolves the field for all the parent objects of one level of the query at once, so the
+resolvers load the data of a level by a fixed number of queries. returns one value per parent,
+lists as []interface{}
+ */
+type gqlResolver func(ctx *gqlContext, parents []interface{}, args map[string]interface{}) ([]interface{}, error)
+
+type gqlField struct {
+	typ     string
+	args    map[string]gqlArg
+	resolve gqlResolver
+}
+
+type gqlArg struct {
+	typ          string
+	defaultValue interface{}
+}
+
+/**
+state of one request: the authorized user and the variables of the operation
+ */
+type gqlContext struct {
+	user      User
+	variables map[string]interface{}
+	errors    []gqlError
+}
+
+type gqlError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+/**
+response object keeping the fields in the order of the selection set
+ */
+type gqlResult struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (r *gqlResult) set(key string, value interface{}) {
+	if _, ok := r.values[key]; !ok {
+		r.keys = append(r.keys, key)
+	}
+	r.values[key] = value
+}
+
+func (r *gqlResult) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+	for i, key := range r.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(r.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+/**
+parses, validates and executes the operation of the document, the errors of the resolvers end up
+in the errors of the response next to the data. a gqlSyntaxError means nothing was executed
+ */
+func (s *gqlSchema) execute(ctx *gqlContext, query string, operationName string, variables map[string]interface{}) (*gqlResult, error) {
+	doc, err := parseGraphQL(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var op *gqlOperation
+	for _, candidate := range doc.operations {
+		if operationName == "" || candidate.name == operationName {
+			if op != nil {
+				return nil, gqlSyntaxError{"operationName is required for a document with several operations"}
+			}
+			op = candidate
+		}
+	}
+	if op == nil {
+		return nil, gqlSyntaxError{fmt.Sprintf("operation %q is not in the document", operationName)}
+	}
+
+	ctx.variables, err = coerceVariables(op.variables, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *gqlObject = s.types[s.query]
+	if op.kind == "mutation" {
+		root = s.types[s.mutation]
+	}
+
+	complexity, err := s.measure(ctx, doc, root, op.selections, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if complexity > graphqlMaxComplexity {
+		return nil, gqlSyntaxError{fmt.Sprintf("query complexity is over the limit of %d", graphqlMaxComplexity)}
+	}
+
+	results := s.executeSelections(ctx, doc, root, []interface{}{nil}, op.selections, nil)
+
+	return results[0], nil
+}
+
+/**
+checks the selections against the schema and the depth limit and returns their complexity.
+pageSize is the first argument of the page field obj is the type of, which the items of the
+page count by, 0 outside of a page. the complexity saturates at one over the limit
+ */
+func (s *gqlSchema) measure(ctx *gqlContext, doc *gqlDocument, obj *gqlObject, selections []gqlSelection, depth int, pageSize int) (int, error) {
+	if depth > graphqlMaxDepth {
+		return 0, gqlSyntaxError{fmt.Sprintf("query is nested deeper than %d levels", graphqlMaxDepth)}
+	}
+
+	fields, err := s.collectFields(ctx, doc, obj, selections, map[string]bool{})
+	if err != nil {
+		return 0, err
+	}
+
+	var complexity int
+	for _, group := range fields {
+		var sel gqlSelection = group.selection
+		if sel.name == "__typename" {
+			complexity = saturate(complexity + 1)
+			continue
+		}
+
+		field, ok := obj.fields[sel.name]
+		if !ok {
+			return 0, gqlSyntaxError{fmt.Sprintf("field %s is not defined on %s", sel.name, obj.name)}
+		}
+
+		args, err := coerceArgs(ctx, field, sel.args)
+		if err != nil {
+			return 0, gqlSyntaxError{fmt.Sprintf("%s.%s: %s", obj.name, sel.name, err.Error())}
+		}
+
+		var typeName string = namedType(field.typ)
+		child, isObject := s.types[typeName]
+		if isObject != (len(group.subSelections) > 0) {
+			if isObject {
+				return 0, gqlSyntaxError{fmt.Sprintf("field %s of type %s needs a selection set", sel.name, field.typ)}
+			}
+			return 0, gqlSyntaxError{fmt.Sprintf("field %s of type %s can not have a selection set", sel.name, field.typ)}
+		}
+
+		var cost int = 1
+		if isObject {
+			childCost, err := s.measure(ctx, doc, child, group.subSelections, depth+1, childPageSize(field.typ, args))
+			if err != nil {
+				return 0, err
+			}
+			cost += childCost * listSize(field.typ, args, pageSize)
+		}
+
+		complexity = saturate(complexity + saturate(cost))
+	}
+
+	return complexity, nil
+}
+
+/**
+items a list field may return: its first argument, the size of the page it is the items of,
+the default one otherwise. saturated so the cost of the items does not overflow
+ */
+func listSize(typ string, args map[string]interface{}, pageSize int) int {
+	if !strings.HasPrefix(typ, "[") {
+		return 1
+	}
+
+	if first, ok := args["first"].(int64); ok && first > 0 {
+		return saturate64(first)
+	}
+
+	if pageSize > 0 {
+		return pageSize
+	}
+
+	return graphqlDefaultListSize
+}
+
+/**
+items of the page a field returns when it is a page, a field of an object type taking the
+first argument: the argument or the page size of the listings. 0 for the other fields
+ */
+func childPageSize(typ string, args map[string]interface{}) int {
+	if strings.HasPrefix(typ, "[") {
+		return 0
+	}
+
+	first, ok := args["first"]
+	if !ok {
+		return 0
+	}
+
+	if first, ok := first.(int64); ok && first > 0 {
+		return saturate64(first)
+	}
+
+	return defaultPageSize
+}
+
+/**
+caps a complexity at one over the limit, any query reaching it is refused anyway
+ */
+func saturate(n int) int {
+	if n < 0 || n > graphqlMaxComplexity {
+		return graphqlMaxComplexity + 1
+	}
+
+	return n
+}
+
+func saturate64(n int64) int {
+	if n > graphqlMaxComplexity {
+		return graphqlMaxComplexity + 1
+	}
+
+	return int(n)
+}
+
+type gqlFieldGroup struct {
+	selection     gqlSelection
+	subSelections []gqlSelection
+}
+
+/**
+fields of the selection set by response key with the fragments expanded, the sub selections
+of the fields of the same key merged
+ */
+func (s *gqlSchema) collectFields(ctx *gqlContext, doc *gqlDocument, obj *gqlObject, selections []gqlSelection, visited map[string]bool) ([]*gqlFieldGroup, error) {
+	var groups []*gqlFieldGroup
+	var byKey map[string]*gqlFieldGroup = make(map[string]*gqlFieldGroup)
+
+	var collect func(selections []gqlSelection) error
+	collect = func(selections []gqlSelection) error {
+		for _, sel := range selections {
+			include, err := included(ctx, sel.directives)
+			if err != nil {
+				return err
+			}
+			if !include {
+				continue
+			}
+
+			if sel.spread != "" {
+				fragment, ok := doc.fragments[sel.spread]
+				if !ok {
+					return gqlSyntaxError{fmt.Sprintf("fragment %s is not defined", sel.spread)}
+				}
+				if visited[sel.spread] {
+					return gqlSyntaxError{fmt.Sprintf("fragment %s spreads itself", sel.spread)}
+				}
+				if fragment.typeCondition != obj.name {
+					continue
+				}
+				visited[sel.spread] = true
+				err = collect(fragment.selections)
+				delete(visited, sel.spread)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			if sel.inline {
+				if sel.typeCond != "" && sel.typeCond != obj.name {
+					continue
+				}
+				err = collect(sel.selections)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			group, ok := byKey[sel.responseKey()]
+			if !ok {
+				group = &gqlFieldGroup{selection: sel}
+				byKey[sel.responseKey()] = group
+				groups = append(groups, group)
+			} else if group.selection.name != sel.name {
+				return gqlSyntaxError{fmt.Sprintf("%s selects both %s and %s", sel.responseKey(), group.selection.name, sel.name)}
+			}
+			group.subSelections = append(group.subSelections, sel.selections...)
+		}
+
+		return nil
+	}
+
+	return groups, collect(selections)
+}
+
+func included(ctx *gqlContext, directives []gqlDirective) (bool, error) {
+	for _, directive := range directives {
+		if directive.name != "include" && directive.name != "skip" {
+			return false, gqlSyntaxError{fmt.Sprintf("directive @%s is not supported", directive.name)}
+		}
+
+		value, err := coerceValue(ctx, "Boolean!", directive.args["if"])
+		if err != nil {
+			return false, gqlSyntaxError{fmt.Sprintf("@%s: %s", directive.name, err.Error())}
+		}
+
+		if value.(bool) == (directive.name == "skip") {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+/**
+resolves the selections for all the parents of a level, field by field, returns a result per parent
+ */
+func (s *gqlSchema) executeSelections(ctx *gqlContext, doc *gqlDocument, obj *gqlObject, parents []interface{}, selections []gqlSelection, path []interface{}) []*gqlResult {
+	var results []*gqlResult = make([]*gqlResult, len(parents))
+	for i := range results {
+		results[i] = &gqlResult{values: make(map[string]interface{})}
+	}
+
+	// validated by measure already
+	groups, _ := s.collectFields(ctx, doc, obj, selections, map[string]bool{})
+
+	for _, group := range groups {
+		var sel gqlSelection = group.selection
+		var key string = sel.responseKey()
+		var fieldPath []interface{} = append(append([]interface{}{}, path...), key)
+
+		if sel.name == "__typename" {
+			for _, result := range results {
+				result.set(key, obj.name)
+			}
+			continue
+		}
+
+		var field *gqlField = obj.fields[sel.name]
+		args, _ := coerceArgs(ctx, field, sel.args)
+
+		values, err := field.resolve(ctx, parents, args)
+		if err == nil && len(values) != len(parents) {
+			err = fmt.Errorf("resolver of %s.%s returned %d values for %d parents", obj.name, sel.name, len(values), len(parents))
+		}
+		if err != nil {
+			ctx.addError(err, fieldPath)
+			values = make([]interface{}, len(parents))
+		}
+
+		values = s.completeValues(ctx, doc, field.typ, values, group.subSelections, fieldPath)
+
+		for i, result := range results {
+			result.set(key, values[i])
+		}
+	}
+
+	return results
+}
+
+/**
+turns the resolved values into response values, the objects of all the values (and of all the
+items of the lists) are resolved together as the next level
+ */
+func (s *gqlSchema) completeValues(ctx *gqlContext, doc *gqlDocument, typ string, values []interface{}, selections []gqlSelection, path []interface{}) []interface{} {
+	var completed []interface{} = make([]interface{}, len(values))
+	var nonNull bool = strings.HasSuffix(typ, "!")
+	var inner string = strings.TrimSuffix(typ, "!")
+
+	if strings.HasPrefix(inner, "[") {
+		var items []interface{}
+		var counts []int = make([]int, len(values))
+
+		for i, value := range values {
+			list, _ := value.([]interface{})
+			if value != nil {
+				counts[i] = len(list)
+				items = append(items, list...)
+			} else {
+				counts[i] = -1
+			}
+		}
+
+		items = s.completeValues(ctx, doc, inner[1:len(inner)-1], items, selections, path)
+
+		for i, count := range counts {
+			if count >= 0 {
+				completed[i], items = items[:count:count], items[count:]
+			}
+		}
+	} else if obj, ok := s.types[inner]; ok {
+		var objects []interface{}
+		for _, value := range values {
+			if value != nil {
+				objects = append(objects, value)
+			}
+		}
+
+		results := s.executeSelections(ctx, doc, obj, objects, selections, path)
+		for i, value := range values {
+			if value != nil {
+				completed[i], results = results[0], results[1:]
+			}
+		}
+	} else {
+		copy(completed, values)
+	}
+
+	if nonNull {
+		for _, value := range completed {
+			if value == nil {
+				ctx.addError(fmt.Errorf("null for the non-null type %s", typ), path)
+				break
+			}
+		}
+	}
+
+	return completed
+}
+
+func (ctx *gqlContext) addError(err error, path []interface{}) {
+	var gerr gqlError = gqlError{Message: err.Error(), Path: path}
+
+	code, problem := problemOf(err)
+	if code != CodeInternal {
+		gerr.Extensions = map[string]interface{}{"code": code.Code}
+		if problem.Field != "" {
+			gerr.Extensions["field"] = problem.Field
+		}
+	} else if errs, ok := err.(ValidationErrors); ok {
+		gerr.Extensions = map[string]interface{}{"code": CodeValidationFailed.Code, "errors": errs}
+	} else {
+		gerr.Message = CodeInternal.Title
+		gerr.Extensions = map[string]interface{}{"code": CodeInternal.Code}
+	}
+
+	ctx.errors = append(ctx.errors, gerr)
+}
+
+func coerceVariables(defs []gqlVariableDef, values map[string]interface{}) (map[string]interface{}, error) {
+	var coerced map[string]interface{} = make(map[string]interface{})
+	var ctx *gqlContext = &gqlContext{}
+
+	for _, def := range defs {
+		value, ok := values[def.name]
+		if !ok {
+			value = def.defaultValue
+		}
+
+		value, err := coerceValue(ctx, def.typ, value)
+		if err != nil {
+			return nil, gqlSyntaxError{fmt.Sprintf("variable $%s: %s", def.name, err.Error())}
+		}
+		coerced[def.name] = value
+	}
+
+	return coerced, nil
+}
+
+func coerceArgs(ctx *gqlContext, field *gqlField, values map[string]interface{}) (map[string]interface{}, error) {
+	var args map[string]interface{} = make(map[string]interface{})
+
+	for name := range values {
+		if _, ok := field.args[name]; !ok {
+			return nil, fmt.Errorf("unknown argument %s", name)
+		}
+	}
+
+	for name, arg := range field.args {
+		value, ok := values[name]
+		if !ok {
+			value = arg.defaultValue
+		}
+
+		value, err := coerceValue(ctx, arg.typ, value)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %s", name, err.Error())
+		}
+		args[name] = value
+	}
+
+	return args, nil
+}
+
+/**
+converts an argument or variable value to the go value of the input type: int64, float64,
+string, bool or []interface{} of those, nil for a missing nullable value
+ */
+func coerceValue(ctx *gqlContext, typ string, value interface{}) (interface{}, error) {
+	if v, ok := value.(gqlVariable); ok {
+		value = ctx.variables[string(v)]
+	}
+
+	if value == nil {
+		if strings.HasSuffix(typ, "!") {
+			return nil, fmt.Errorf("must not be null, type %s", typ)
+		}
+		return nil, nil
+	}
+
+	typ = strings.TrimSuffix(typ, "!")
+
+	if strings.HasPrefix(typ, "[") {
+		var items []interface{} = []interface{}{}
+		list, ok := value.([]interface{})
+		if !ok {
+			list = []interface{}{value}
+		}
+		for _, item := range list {
+			coerced, err := coerceValue(ctx, typ[1:len(typ)-1], item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, coerced)
+		}
+		return items, nil
+	}
+
+	switch typ {
+	case "Int":
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+				return int64(v), nil
+			}
+		}
+	case "Float":
+		switch v := value.(type) {
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+	case "String":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case gqlEnum:
+			return string(v), nil
+		}
+	case "Boolean":
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%v is not a valid %s", value, typ)
+}
+
+/**
+the type name of a type reference, Answer for [Answer!]!
+ */
+func namedType(typ string) string {
+	return strings.Trim(typ, "[]!")
+}
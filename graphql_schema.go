@@ -0,0 +1,737 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query" validate:"required,max=20000"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type GraphQLResponse struct {
+	Data   *gqlResult `json:"data,omitempty"`
+	Errors []gqlError `json:"errors,omitempty"`
+}
+
+/**
+schema of /graphql:
+
+	type Query {
+		me: User!
+		user(email: String!): User
+		topUsers(first: Int = 5): [User!]!
+		question(id: Int!): Question
+		questions(first: Int, after: String, sort: String, order: String, tag: String, author: String,
+			answered: Boolean, accepted: Boolean, from: String, to: String): QuestionPage!
+		answer(id: Int!): Answer
+		answers(questionId: Int!, first: Int, after: String): AnswerPage!
+		search(query: String!, type: String, tags: [String!], author: String, first: Int, after: String): SearchPage!
+	}
+
+	type Mutation {
+		postQuestion(question: String!, tags: [String!]): PostedQuestion!
+		postAnswer(questionId: Int!, answer: String!): Answer!
+		rateAnswer(answerId: Int!): Answer!
+		acceptAnswer(answerId: Int!): Answer!
+		closeVote(questionId: Int!, reason: String!, duplicateOf: Int): Question!
+		reopenVote(questionId: Int!): Question!
+	}
+
+	type User { name: String!, email: String!, answerCount: Int! }
+	type Question { id: Int!, question: String!, state: String!, closeReason: String, views: Int!,
+		answerCount: Int!, score: Int!, createdAt: String!, lastActivityAt: String!, tags: [String!]!,
+		author: User, duplicateOf: Question, acceptedAnswer: Answer, answers(first: Int = 10): [Answer!]! }
+	type Answer { id: Int!, answer: String!, score: Int!, votes: Votes!, createdAt: String!, author: User, question: Question }
+	type Votes { up: Int!, down: Int! }
+	type SearchHit { type: String!, score: Float!, question: Question, answer: Answer }
+	type QuestionPage, AnswerPage, SearchPage { items: [...!]!, next: String, prev: String, total: Int }
+	type PostedQuestion { question: Question!, duplicates: [SearchHit!]! }
+
+the fields of the objects of a level are resolved together, so author, answers, votes and the other
+relations cost one query per level whatever the count of the items is
+ */
+var graphqlSchema *gqlSchema = newGraphQLSchema()
+
+/**
+executes the graphql query of the request as the authorized user
+ */
+func ServeGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req GraphQLRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	var ctx *gqlContext = &gqlContext{user: user}
+	var resp GraphQLResponse
+	var status int = http.StatusOK
+
+	resp.Data, err = graphqlSchema.execute(ctx, req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		status = http.StatusBadRequest
+		resp.Errors = []gqlError{{Message: err.Error(), Extensions: map[string]interface{}{"code": CodeInvalidQuery.Code}}}
+	} else {
+		resp.Errors = ctx.errors
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+func newGraphQLSchema() *gqlSchema {
+	var pageFields = func(item string) map[string]*gqlField {
+		return map[string]*gqlField{
+			"items": {typ: "[" + item + "!]!", resolve: eachParent(func(p interface{}) interface{} { return p.(Page).Items })},
+			"next":  {typ: "String", resolve: eachParent(func(p interface{}) interface{} { return nullString(p.(Page).Next) })},
+			"prev":  {typ: "String", resolve: eachParent(func(p interface{}) interface{} { return nullString(p.(Page).Prev) })},
+			"total": {typ: "Int", resolve: eachParent(func(p interface{}) interface{} {
+				if total := p.(Page).Total; total != nil {
+					return *total
+				}
+				return nil
+			})},
+		}
+	}
+
+	var pageArgs map[string]gqlArg = map[string]gqlArg{"first": {typ: "Int"}, "after": {typ: "String"}}
+
+	var objects []*gqlObject = []*gqlObject{
+		{name: "Query", fields: map[string]*gqlField{
+			"me":       {typ: "User!", resolve: rootField(resolveMe)},
+			"user":     {typ: "User", args: map[string]gqlArg{"email": {typ: "String!"}}, resolve: rootField(resolveUser)},
+			"topUsers": {typ: "[User!]!", args: map[string]gqlArg{"first": {typ: "Int", defaultValue: int64(5)}}, resolve: rootField(resolveTopUsers)},
+			"question": {typ: "Question", args: map[string]gqlArg{"id": {typ: "Int!"}}, resolve: rootField(resolveQuestion)},
+			"questions": {typ: "QuestionPage!", args: withArgs(pageArgs, map[string]gqlArg{
+				"sort": {typ: "String"}, "order": {typ: "String"}, "tag": {typ: "String"}, "author": {typ: "String"},
+				"answered": {typ: "Boolean"}, "accepted": {typ: "Boolean"}, "from": {typ: "String"}, "to": {typ: "String"},
+			}), resolve: rootField(resolveQuestions)},
+			"answer":  {typ: "Answer", args: map[string]gqlArg{"id": {typ: "Int!"}}, resolve: rootField(resolveAnswer)},
+			"answers": {typ: "AnswerPage!", args: withArgs(pageArgs, map[string]gqlArg{"questionId": {typ: "Int!"}}), resolve: rootField(resolveAnswers)},
+			"search": {typ: "SearchPage!", args: withArgs(pageArgs, map[string]gqlArg{
+				"query": {typ: "String!"}, "type": {typ: "String"}, "tags": {typ: "[String!]"}, "author": {typ: "String"},
+			}), resolve: rootField(resolveSearch)},
+		}},
+		{name: "Mutation", fields: map[string]*gqlField{
+			"postQuestion": {typ: "PostedQuestion!", args: map[string]gqlArg{"question": {typ: "String!"}, "tags": {typ: "[String!]"}}, resolve: rootField(resolvePostQuestion)},
+			"postAnswer":   {typ: "Answer!", args: map[string]gqlArg{"questionId": {typ: "Int!"}, "answer": {typ: "String!"}}, resolve: rootField(resolvePostAnswer)},
+			"rateAnswer":   {typ: "Answer!", args: map[string]gqlArg{"answerId": {typ: "Int!"}}, resolve: rootField(resolveRateAnswer)},
+			"acceptAnswer": {typ: "Answer!", args: map[string]gqlArg{"answerId": {typ: "Int!"}}, resolve: rootField(resolveAcceptAnswer)},
+			"closeVote": {typ: "Question!", args: map[string]gqlArg{"questionId": {typ: "Int!"}, "reason": {typ: "String!"}, "duplicateOf": {typ: "Int"}},
+				resolve: rootField(resolveCloseVote)},
+			"reopenVote": {typ: "Question!", args: map[string]gqlArg{"questionId": {typ: "Int!"}}, resolve: rootField(resolveReopenVote)},
+		}},
+		{name: "User", fields: map[string]*gqlField{
+			"name":        {typ: "String!", resolve: eachParent(func(p interface{}) interface{} { return p.(User).Name })},
+			"email":       {typ: "String!", resolve: eachParent(func(p interface{}) interface{} { return p.(User).Email })},
+			"answerCount": {typ: "Int!", resolve: eachParent(func(p interface{}) interface{} { return p.(User).AnswerCount })},
+		}},
+		{name: "Question", fields: map[string]*gqlField{
+			"id":             {typ: "Int!", resolve: eachParent(func(p interface{}) interface{} { return p.(Question).Id })},
+			"question":       {typ: "String!", resolve: eachParent(func(p interface{}) interface{} { return p.(Question).Question })},
+			"state":          {typ: "String!", resolve: eachParent(func(p interface{}) interface{} { return p.(Question).CurrentState() })},
+			"closeReason":    {typ: "String", resolve: eachParent(func(p interface{}) interface{} { return nullString(p.(Question).CloseReason) })},
+			"views":          {typ: "Int!", resolve: eachParent(func(p interface{}) interface{} { return p.(Question).Views })},
+			"answerCount":    {typ: "Int!", resolve: eachParent(func(p interface{}) interface{} { return p.(Question).AnswerCount })},
+			"score":          {typ: "Int!", resolve: eachParent(func(p interface{}) interface{} { return p.(Question).Score })},
+			"createdAt":      {typ: "String!", resolve: eachParent(func(p interface{}) interface{} { return p.(Question).CreatedAt.Format(time.RFC3339) })},
+			"lastActivityAt": {typ: "String!", resolve: eachParent(func(p interface{}) interface{} { return p.(Question).LastActivityAt.Format(time.RFC3339) })},
+			"tags":           {typ: "[String!]!", resolve: resolveQuestionTags},
+			"author":         {typ: "User", resolve: resolveQuestionAuthors},
+			"duplicateOf":    {typ: "Question", resolve: resolveDuplicatesOf},
+			"acceptedAnswer": {typ: "Answer", resolve: resolveAcceptedAnswers},
+			"answers":        {typ: "[Answer!]!", args: map[string]gqlArg{"first": {typ: "Int", defaultValue: int64(graphqlDefaultListSize)}}, resolve: resolveQuestionAnswers},
+		}},
+		{name: "Answer", fields: map[string]*gqlField{
+			"id":        {typ: "Int!", resolve: eachParent(func(p interface{}) interface{} { return p.(Answer).Id })},
+			"answer":    {typ: "String!", resolve: eachParent(func(p interface{}) interface{} { return p.(Answer).Answer })},
+			"score":     {typ: "Int!", resolve: eachParent(func(p interface{}) interface{} { return p.(Answer).Score })},
+			"createdAt": {typ: "String!", resolve: eachParent(func(p interface{}) interface{} { return p.(Answer).CreatedAt.Format(time.RFC3339) })},
+			"votes":     {typ: "Votes!", resolve: resolveAnswerVotes},
+			"author":    {typ: "User", resolve: resolveAnswerAuthors},
+			"question":  {typ: "Question", resolve: resolveAnswerQuestions},
+		}},
+		{name: "Votes", fields: map[string]*gqlField{
+			"up":   {typ: "Int!", resolve: eachParent(func(p interface{}) interface{} { return p.(answerVotes).Up })},
+			"down": {typ: "Int!", resolve: eachParent(func(p interface{}) interface{} { return p.(answerVotes).Down })},
+		}},
+		{name: "SearchHit", fields: map[string]*gqlField{
+			"type":  {typ: "String!", resolve: eachParent(func(p interface{}) interface{} { return p.(SearchHit).Kind })},
+			"score": {typ: "Float!", resolve: eachParent(func(p interface{}) interface{} { return p.(SearchHit).Score })},
+			"question": {typ: "Question", resolve: eachParent(func(p interface{}) interface{} {
+				if q := p.(SearchHit).Question; q != nil {
+					return *q
+				}
+				return nil
+			})},
+			"answer": {typ: "Answer", resolve: eachParent(func(p interface{}) interface{} {
+				if a := p.(SearchHit).Answer; a != nil {
+					return *a
+				}
+				return nil
+			})},
+		}},
+		{name: "PostedQuestion", fields: map[string]*gqlField{
+			"question":   {typ: "Question!", resolve: eachParent(func(p interface{}) interface{} { return p.(postedQuestion).question })},
+			"duplicates": {typ: "[SearchHit!]!", resolve: eachParent(func(p interface{}) interface{} { return toInterfaces(p.(postedQuestion).duplicates) })},
+		}},
+		{name: "QuestionPage", fields: pageFields("Question")},
+		{name: "AnswerPage", fields: pageFields("Answer")},
+		{name: "SearchPage", fields: pageFields("SearchHit")},
+	}
+
+	var schema *gqlSchema = &gqlSchema{types: make(map[string]*gqlObject), query: "Query", mutation: "Mutation"}
+	for _, object := range objects {
+		schema.types[object.name] = object
+	}
+
+	return schema
+}
+
+type postedQuestion struct {
+	question   Question
+	duplicates []SearchHit
+}
+
+/**
+resolver of a field of the root types, called once with the single nil parent
+ */
+func rootField(f func(ctx *gqlContext, args map[string]interface{}) (interface{}, error)) gqlResolver {
+	return func(ctx *gqlContext, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+		value, err := f(ctx, args)
+		return []interface{}{value}, err
+	}
+}
+
+/**
+resolver of a field read from the parent itself
+ */
+func eachParent(f func(parent interface{}) interface{}) gqlResolver {
+	return func(ctx *gqlContext, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+		var values []interface{} = make([]interface{}, len(parents))
+		for i, parent := range parents {
+			values[i] = f(parent)
+		}
+		return values, nil
+	}
+}
+
+func withArgs(base map[string]gqlArg, args map[string]gqlArg) map[string]gqlArg {
+	var merged map[string]gqlArg = make(map[string]gqlArg)
+	for name, arg := range base {
+		merged[name] = arg
+	}
+	for name, arg := range args {
+		merged[name] = arg
+	}
+
+	return merged
+}
+
+/**
+validates the request struct built from the arguments by its validate tags, as the http api does
+ */
+func validateArgs(req interface{}) error {
+	errs := validateStruct(reflect.ValueOf(req).Elem(), "")
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+/**
+converts a slice into the []interface{} the list values are resolved as
+ */
+func toInterfaces(slice interface{}) []interface{} {
+	var v reflect.Value = reflect.ValueOf(slice)
+	var items []interface{} = make([]interface{}, v.Len())
+
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+
+	return items
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+func argString(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+func argInt(args map[string]interface{}, name string) int64 {
+	i, _ := args[name].(int64)
+	return i
+}
+
+func argIntPtr(args map[string]interface{}, name string) *int64 {
+	if i, ok := args[name].(int64); ok {
+		return &i
+	}
+
+	return nil
+}
+
+func argBoolPtr(args map[string]interface{}, name string) *bool {
+	if b, ok := args[name].(bool); ok {
+		return &b
+	}
+
+	return nil
+}
+
+func argStrings(args map[string]interface{}, name string) []string {
+	var strs []string
+
+	list, _ := args[name].([]interface{})
+	for _, item := range list {
+		strs = append(strs, item.(string))
+	}
+
+	return strs
+}
+
+func argPage(args map[string]interface{}) PageRequest {
+	var page PageRequest = PageRequest{Cursor: argString(args, "after")}
+
+	if first := argIntPtr(args, "first"); first != nil {
+		var limit int = int(*first)
+		page.Limit = &limit
+	}
+
+	return page
+}
+
+func resolveMe(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	return ctx.user, nil
+}
+
+func resolveUser(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	var user User
+
+	if user.LoadByEmail(argString(args, "email")) != nil || user.DeletedAt != nil {
+		return nil, nil
+	}
+
+	return user, nil
+}
+
+func resolveTopUsers(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	var user User
+	var req PageRequest = argPage(args)
+
+	err := validateArgs(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	_, limit := req.PageParams()
+	users, err := user.GetTopUsers(limit)
+
+	return toInterfaces(users), err
+}
+
+func resolveQuestion(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	var question Question
+
+	if question.Load(argInt(args, "id")) != nil {
+		return nil, nil
+	}
+
+	return question, nil
+}
+
+func resolveQuestions(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	var question Question
+	var req ListQuestionsRequest = ListQuestionsRequest{PageRequest: argPage(args), Sort: argString(args, "sort"), Order: argString(args, "order"),
+		Tag: argString(args, "tag"), Author: argString(args, "author"), Answered: argBoolPtr(args, "answered"), Accepted: argBoolPtr(args, "accepted"),
+		From: argString(args, "from"), To: argString(args, "to")}
+
+	err := validateArgs(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := req.Filter()
+	if err != nil {
+		return nil, err
+	}
+
+	questions, info, err := question.GetList(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPage(toInterfaces(questions), info), nil
+}
+
+func resolveAnswer(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	var answer Answer
+
+	if answer.Load(argInt(args, "id")) != nil {
+		return nil, nil
+	}
+
+	return answer, nil
+}
+
+func resolveAnswers(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	var question Question
+	var req QuestionAnswersRequest = QuestionAnswersRequest{PageRequest: argPage(args), QuestionId: argInt(args, "questionId")}
+
+	err := validateArgs(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	if question.Load(req.QuestionId) != nil {
+		return nil, ActionError{Code: CodeQuestionNotFound, Field: "questionId"}
+	}
+
+	cursor, limit := req.PageParams()
+	answers, info, err := question.GetAnswersByRate(cursor, limit, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPage(toInterfaces(answers), info), nil
+}
+
+func resolveSearch(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	var req SearchRequest = SearchRequest{PageRequest: argPage(args), Query: argString(args, "query"), Type: argString(args, "type"),
+		Tags: argStrings(args, "tags"), Author: argString(args, "author")}
+
+	err := validateArgs(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := req.SearchQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	hits, info, err := SearchIdx.Search(query)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := LoadSearchHits(hits)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPage(toInterfaces(results), info), nil
+}
+
+func resolvePostQuestion(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	var req PostQuestionRequest = PostQuestionRequest{Question: argString(args, "question"), Tags: argStrings(args, "tags")}
+
+	err := validateArgs(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	question, duplicates, err := PostQuestionAs(ctx.user, req.Question, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return postedQuestion{question: question, duplicates: duplicates}, nil
+}
+
+func resolvePostAnswer(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	var req PostAnswerRequest = PostAnswerRequest{QuestionId: argInt(args, "questionId"), Answer: argString(args, "answer")}
+
+	err := validateArgs(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := PostAnswerAs(ctx.user, req.QuestionId, req.Answer)
+	if err != nil {
+		return nil, err
+	}
+
+	return answer, nil
+}
+
+func resolveRateAnswer(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	var req RateAnswerRequest = RateAnswerRequest{AnswerId: argInt(args, "answerId")}
+
+	err := validateArgs(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := RateAnswerAs(ctx.user, req.AnswerId)
+	if err != nil {
+		return nil, err
+	}
+
+	return answer, nil
+}
+
+func resolveAcceptAnswer(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	answer, err := AcceptAnswerAs(ctx.user, argInt(args, "answerId"))
+	if err != nil {
+		return nil, err
+	}
+
+	return answer, nil
+}
+
+func resolveCloseVote(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	var req CloseVoteRequest = CloseVoteRequest{QuestionId: argInt(args, "questionId"), Reason: argString(args, "reason"), DuplicateOf: argIntPtr(args, "duplicateOf")}
+
+	err := validateArgs(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := CloseVoteAs(ctx.user, req.QuestionId, req.Reason, req.DuplicateOf)
+	if err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+func resolveReopenVote(ctx *gqlContext, args map[string]interface{}) (interface{}, error) {
+	question, err := ReopenVoteAs(ctx.user, argInt(args, "questionId"))
+	if err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+/**
+tags of the questions, the ones loaded without them get them by a single query
+ */
+func resolveQuestionTags(ctx *gqlContext, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	var missing []Question
+	var values []interface{} = make([]interface{}, len(parents))
+
+	for _, parent := range parents {
+		if parent.(Question).Tags == nil {
+			missing = append(missing, parent.(Question))
+		}
+	}
+
+	err := attachQuestionTags(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	var byId map[int64][]string = make(map[int64][]string)
+	for _, question := range missing {
+		byId[question.Id] = question.Tags
+	}
+
+	for i, parent := range parents {
+		var tags []string = parent.(Question).Tags
+		if tags == nil {
+			tags = byId[parent.(Question).Id]
+		}
+		values[i] = toInterfaces(tags)
+	}
+
+	return values, nil
+}
+
+func resolveQuestionAuthors(ctx *gqlContext, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	var ids []int64
+	for _, parent := range parents {
+		ids = append(ids, parent.(Question).UserId)
+	}
+
+	return usersByIds(ids)
+}
+
+func resolveAnswerAuthors(ctx *gqlContext, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	var ids []int64
+	for _, parent := range parents {
+		ids = append(ids, parent.(Answer).UserId)
+	}
+
+	return usersByIds(ids)
+}
+
+/**
+the not deleted users of the ids by a single query, nil for the deleted ones
+ */
+func usersByIds(ids []int64) ([]interface{}, error) {
+	var values []interface{} = make([]interface{}, len(ids))
+
+	users, err := selectUsers(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, id := range ids {
+		if user, ok := users[id]; ok {
+			values[i] = *user
+		}
+	}
+
+	return values, nil
+}
+
+func resolveDuplicatesOf(ctx *gqlContext, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	var ids []int64
+	for _, parent := range parents {
+		ids = append(ids, parent.(Question).DuplicateOfId)
+	}
+
+	return questionsByIds(ids)
+}
+
+func resolveAnswerQuestions(ctx *gqlContext, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	var ids []int64
+	for _, parent := range parents {
+		ids = append(ids, parent.(Answer).QuestionId)
+	}
+
+	return questionsByIds(ids)
+}
+
+/**
+the questions of the ids by a single query, nil for the 0 ids
+ */
+func questionsByIds(ids []int64) ([]interface{}, error) {
+	var values []interface{} = make([]interface{}, len(ids))
+	var byId map[int64]Question = make(map[int64]Question)
+
+	questions, err := selectQuestions(nonZeroIds(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, question := range questions {
+		if question.DeletedAt == nil {
+			byId[question.Id] = question
+		}
+	}
+
+	for i, id := range ids {
+		if question, ok := byId[id]; ok {
+			values[i] = question
+		}
+	}
+
+	return values, nil
+}
+
+func resolveAcceptedAnswers(ctx *gqlContext, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	var ids []int64
+	var values []interface{} = make([]interface{}, len(parents))
+	var byId map[int64]Answer = make(map[int64]Answer)
+
+	for _, parent := range parents {
+		ids = append(ids, parent.(Question).AcceptedAnswerId)
+	}
+
+	answers, err := selectAnswers(nonZeroIds(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, answer := range answers {
+		if answer.DeletedAt == nil {
+			byId[answer.Id] = answer
+		}
+	}
+
+	for i, id := range ids {
+		if answer, ok := byId[id]; ok {
+			values[i] = answer
+		}
+	}
+
+	return values, nil
+}
+
+func resolveQuestionAnswers(ctx *gqlContext, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	var ids []int64
+	var values []interface{} = make([]interface{}, len(parents))
+	var first int64 = argInt(args, "first")
+
+	if first < 1 || first > maxPageSize {
+		return nil, ValidationErrors{{Field: "first", Rule: "max", Message: "must be between 1 and 50"}}
+	}
+
+	for _, parent := range parents {
+		ids = append(ids, parent.(Question).Id)
+	}
+
+	byQuestion, err := selectAnswersByRate(ids, int(first))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, parent := range parents {
+		values[i] = toInterfaces(byQuestion[parent.(Question).Id])
+	}
+
+	return values, nil
+}
+
+func resolveAnswerVotes(ctx *gqlContext, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	var ids []int64
+	var values []interface{} = make([]interface{}, len(parents))
+
+	for _, parent := range parents {
+		ids = append(ids, parent.(Answer).Id)
+	}
+
+	votes, err := selectAnswerVotes(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, parent := range parents {
+		values[i] = votes[parent.(Answer).Id]
+	}
+
+	return values, nil
+}
+
+func nonZeroIds(ids []int64) []int64 {
+	var nonZero []int64
+
+	for _, id := range uniqueIds(ids) {
+		if id != 0 {
+			nonZero = append(nonZero, id)
+		}
+	}
+
+	return nonZero
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+)
+
+func measureQuery(t *testing.T, query string) int {
+	doc, err := parseGraphQL(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	complexity, err := graphqlSchema.measure(&gqlContext{}, doc, graphqlSchema.types[graphqlSchema.query], doc.operations[0].selections, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return complexity
+}
+
+func TestComplexityCountsThePageItems(t *testing.T) {
+	var cases map[string]int = map[string]int{
+		// the items of a page count by the first argument of the page
+		`{ questions(first: 20) { items { id } } }`: 1 + (1 + 20*1),
+		// or by the page size of the listings
+		`{ answers(questionId: 1) { items { id } next } }`:                1 + (1 + defaultPageSize*1) + 1,
+		`{ search(query: "go", first: 3) { items { question { id } } } }`: 1 + (1 + 3*2),
+		// the lists outside of a page by their own first argument or the default one
+		`{ question(id: 1) { answers { id } } }`:                                      1 + (1 + graphqlDefaultListSize*1),
+		`{ topUsers(first: 3) { name } }`:                                             1 + 3*1,
+		`{ questions(first: 2) { items { answers(first: 4) { author { name } } } } }`: 1 + (1 + 2*(1+4*2)),
+	}
+
+	for query, expected := range cases {
+		if complexity := measureQuery(t, query); complexity != expected {
+			t.Errorf("%s: complexity %d, want %d", query, complexity, expected)
+		}
+	}
+}
+
+func TestComplexityOfNestedPagesIsOverTheLimit(t *testing.T) {
+	// 50 questions of 50 answers resolve 2500 authors
+	var complexity int = measureQuery(t, `{ questions(first: 50) { items { answers(first: 50) { author { name } } } } }`)
+	if complexity <= graphqlMaxComplexity {
+		t.Fatalf("complexity %d, want over %d", complexity, graphqlMaxComplexity)
+	}
+
+	complexity = measureQuery(t, `{ topUsers(first: 9223372036854775807) { name } a: topUsers(first: 9223372036854775807) { name } }`)
+	if complexity != graphqlMaxComplexity+1 {
+		t.Fatalf("complexity %d, want it saturated at %d", complexity, graphqlMaxComplexity+1)
+	}
+}
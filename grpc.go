@@ -0,0 +1,448 @@
+package main
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	grpcPort = "9090"
+
+	// WatchAnswers looks for new answers this often
+	answerWatchInterval = 2 * time.Second
+)
+
+// grpc codes of the error catalog, the not listed ones are Internal
+var grpcCodes map[string]codes.Code = map[string]codes.Code{
+	CodeValidationFailed.Code:    codes.InvalidArgument,
+	CodeAccessForbidden.Code:     codes.Unauthenticated,
+	CodeModeratorRequired.Code:   codes.PermissionDenied,
+	CodeNotOwner.Code:            codes.PermissionDenied,
+	CodeEmailTaken.Code:          codes.AlreadyExists,
+	CodeRateExists.Code:          codes.AlreadyExists,
+	CodeVoteExists.Code:          codes.AlreadyExists,
+	CodeConflict.Code:            codes.AlreadyExists,
+	CodeUserNotFound.Code:        codes.NotFound,
+	CodeQuestionNotFound.Code:    codes.NotFound,
+	CodeAnswerNotFound.Code:      codes.NotFound,
+	CodeInvalidCursor.Code:       codes.InvalidArgument,
+	CodeInvalidDuplicate.Code:    codes.InvalidArgument,
+	CodeInvalidTransition.Code:   codes.FailedPrecondition,
+	CodeInvalidCloseReason.Code:  codes.InvalidArgument,
+	CodeMissingDuplicate.Code:    codes.InvalidArgument,
+	CodeQuestionNotWritable.Code: codes.FailedPrecondition,
+}
+
+/**
+Questions service of questions.proto, over the same models and actions as the http handlers
+ */
+type grpcServer struct {
+	UnimplementedQuestionsServer
+}
+
+func NewGRPCServer() *grpc.Server {
+	var server *grpc.Server = grpc.NewServer(grpc.UnaryInterceptor(grpcAuthUnary), grpc.StreamInterceptor(grpcAuthStream))
+	RegisterQuestionsServer(server, grpcServer{})
+
+	return server
+}
+
+func ServeGRPC(port string) error {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+
+	return NewGRPCServer().Serve(listener)
+}
+
+/**
+authorizes the calls by the access-token metadata, the user is put into the context as AuthUser does
+ */
+func grpcAuthUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, h grpc.UnaryHandler) (interface{}, error) {
+	ctx, ok := authorizeToken(ctx, grpcAccessToken(ctx))
+	if !ok {
+		return nil, grpcError(ActionError{Code: CodeAccessForbidden})
+	}
+
+	return h(ctx, req)
+}
+
+func grpcAuthStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+	ctx, ok := authorizeToken(stream.Context(), grpcAccessToken(stream.Context()))
+	if !ok {
+		return grpcError(ActionError{Code: CodeAccessForbidden})
+	}
+
+	return h(srv, authorizedStream{ServerStream: stream, ctx: ctx})
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func grpcAccessToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if tokens := md.Get("access-token"); len(tokens) > 0 {
+		return tokens[0]
+	}
+
+	return ""
+}
+
+func grpcUser(ctx context.Context) User {
+	user, _ := ctx.Value(authUserKey).(User)
+	return user
+}
+
+/**
+status of the error with the catalog code as the reason of an ErrorInfo detail, the field
+errors of a validation as a BadRequest detail
+ */
+func grpcError(err error) error {
+	var st *status.Status
+
+	if errs, ok := err.(ValidationErrors); ok {
+		var violations []*errdetails.BadRequest_FieldViolation
+		for _, fe := range errs {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: fe.Field, Description: fe.Message})
+		}
+
+		st = status.New(codes.InvalidArgument, errs.Error())
+		st, _ = st.WithDetails(&errdetails.ErrorInfo{Reason: CodeValidationFailed.Code, Domain: "questions"}, &errdetails.BadRequest{FieldViolations: violations})
+		return st.Err()
+	}
+
+	code, problem := problemOf(err)
+	grpcCode, ok := grpcCodes[code.Code]
+	if !ok {
+		grpcCode = codes.Internal
+	}
+
+	var message string = code.Title
+	if problem.Detail != "" {
+		message += ": " + problem.Detail
+	}
+
+	var info *errdetails.ErrorInfo = &errdetails.ErrorInfo{Reason: code.Code, Domain: "questions"}
+	if problem.Field != "" {
+		info.Metadata = map[string]string{"field": problem.Field}
+	}
+
+	st, _ = status.New(grpcCode, message).WithDetails(info)
+
+	return st.Err()
+}
+
+func validateMessage(req interface{}) error {
+	errs := validateStruct(reflect.ValueOf(req).Elem(), "")
+	if len(errs) > 0 {
+		return grpcError(errs)
+	}
+
+	return nil
+}
+
+func pageRequest(cursor string, limit int32) PageRequest {
+	var page PageRequest = PageRequest{Cursor: cursor}
+
+	if limit != 0 {
+		var l int = int(limit)
+		page.Limit = &l
+	}
+
+	return page
+}
+
+func (s grpcServer) PostQuestion(ctx context.Context, in *RpcPostQuestionRequest) (*RpcPostQuestionReply, error) {
+	var req PostQuestionRequest = PostQuestionRequest{Question: in.Question, Tags: in.Tags}
+
+	err := validateMessage(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	question, duplicates, err := PostQuestionAs(grpcUser(ctx), req.Question, req.Tags)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	var reply *RpcPostQuestionReply = &RpcPostQuestionReply{Question: rpcQuestion(question)}
+	for _, hit := range duplicates {
+		if hit.Question != nil {
+			reply.Duplicates = append(reply.Duplicates, rpcQuestion(*hit.Question))
+		}
+	}
+
+	return reply, nil
+}
+
+func (s grpcServer) GetQuestion(ctx context.Context, in *RpcQuestionRequest) (*RpcQuestion, error) {
+	var req QuestionRequest = QuestionRequest{QuestionId: in.QuestionId}
+
+	err := validateMessage(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := loadQuestions([]int64{req.QuestionId})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	if len(questions) == 0 || questions[0].DeletedAt != nil {
+		return nil, grpcError(ActionError{Code: CodeQuestionNotFound, Field: "question_id"})
+	}
+
+	return rpcQuestion(questions[0]), nil
+}
+
+func (s grpcServer) ListQuestions(ctx context.Context, in *RpcListQuestionsRequest) (*RpcQuestionPage, error) {
+	var question Question
+	var req ListQuestionsRequest = ListQuestionsRequest{PageRequest: pageRequest(in.Cursor, in.Limit), Sort: in.Sort, Order: in.Order, Tag: in.Tag, Author: in.Author}
+
+	err := validateMessage(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := req.Filter()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	questions, info, err := question.GetList(filter)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	var page *RpcQuestionPage = &RpcQuestionPage{Next: info.Next, Prev: info.Prev}
+	for _, q := range questions {
+		page.Items = append(page.Items, rpcQuestion(q))
+	}
+
+	return page, nil
+}
+
+func (s grpcServer) CloseVote(ctx context.Context, in *RpcCloseVoteRequest) (*RpcQuestion, error) {
+	var req CloseVoteRequest = CloseVoteRequest{QuestionId: in.QuestionId, Reason: in.Reason}
+	if in.DuplicateOf != 0 {
+		req.DuplicateOf = &in.DuplicateOf
+	}
+
+	err := validateMessage(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := CloseVoteAs(grpcUser(ctx), req.QuestionId, req.Reason, req.DuplicateOf)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return rpcQuestion(question), nil
+}
+
+func (s grpcServer) ReopenVote(ctx context.Context, in *RpcQuestionRequest) (*RpcQuestion, error) {
+	var req QuestionRequest = QuestionRequest{QuestionId: in.QuestionId}
+
+	err := validateMessage(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := ReopenVoteAs(grpcUser(ctx), req.QuestionId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return rpcQuestion(question), nil
+}
+
+func (s grpcServer) PostAnswer(ctx context.Context, in *RpcPostAnswerRequest) (*RpcAnswer, error) {
+	var req PostAnswerRequest = PostAnswerRequest{QuestionId: in.QuestionId, Answer: in.Answer}
+
+	err := validateMessage(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := PostAnswerAs(grpcUser(ctx), req.QuestionId, req.Answer)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return rpcAnswer(answer), nil
+}
+
+func (s grpcServer) ListAnswers(ctx context.Context, in *RpcListAnswersRequest) (*RpcAnswerPage, error) {
+	var question Question
+	var req QuestionAnswersRequest = QuestionAnswersRequest{PageRequest: pageRequest(in.Cursor, in.Limit), QuestionId: in.QuestionId}
+
+	err := validateMessage(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	if question.Load(req.QuestionId) != nil {
+		return nil, grpcError(ActionError{Code: CodeQuestionNotFound, Field: "question_id"})
+	}
+
+	cursor, limit := req.PageParams()
+	answers, info, err := question.GetAnswersByRate(cursor, limit, true)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	var page *RpcAnswerPage = &RpcAnswerPage{Next: info.Next, Prev: info.Prev}
+	for _, answer := range answers {
+		page.Items = append(page.Items, rpcAnswer(answer))
+	}
+
+	return page, nil
+}
+
+func (s grpcServer) RateAnswer(ctx context.Context, in *RpcRateAnswerRequest) (*RpcAnswer, error) {
+	var req RateAnswerRequest = RateAnswerRequest{AnswerId: in.AnswerId}
+
+	err := validateMessage(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := RateAnswerAs(grpcUser(ctx), req.AnswerId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return rpcAnswer(answer), nil
+}
+
+func (s grpcServer) AcceptAnswer(ctx context.Context, in *RpcAnswerRequest) (*RpcAnswer, error) {
+	var req AnswerRequest = AnswerRequest{AnswerId: in.AnswerId}
+
+	err := validateMessage(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := AcceptAnswerAs(grpcUser(ctx), req.AnswerId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return rpcAnswer(answer), nil
+}
+
+func (s grpcServer) TopUsers(ctx context.Context, in *RpcTopUsersRequest) (*RpcTopUsersReply, error) {
+	var user User
+	var req PageRequest = pageRequest("", in.Limit)
+
+	err := validateMessage(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	var limit int = 5
+	if req.Limit != nil {
+		limit = *req.Limit
+	}
+
+	users, err := user.GetTopUsers(limit)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	var reply *RpcTopUsersReply = &RpcTopUsersReply{}
+	for _, u := range users {
+		reply.Users = append(reply.Users, rpcUser(u))
+	}
+
+	return reply, nil
+}
+
+/**
+sends the answers posted after the call to the question, every answerWatchInterval,
+until the client goes away
+ */
+func (s grpcServer) WatchAnswers(in *RpcQuestionRequest, stream Questions_WatchAnswersServer) error {
+	var question Question
+	var req QuestionRequest = QuestionRequest{QuestionId: in.QuestionId}
+
+	err := validateMessage(&req)
+	if err != nil {
+		return err
+	}
+
+	if question.Load(req.QuestionId) != nil {
+		return grpcError(ActionError{Code: CodeQuestionNotFound, Field: "question_id"})
+	}
+
+	lastId, err := question.LastAnswerId()
+	if err != nil {
+		return grpcError(err)
+	}
+
+	var ticker *time.Ticker = time.NewTicker(answerWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		answers, err := question.GetAnswersAfter(lastId)
+		if err != nil {
+			return grpcError(err)
+		}
+
+		for _, answer := range answers {
+			err = stream.Send(rpcAnswer(answer))
+			if err != nil {
+				return err
+			}
+			lastId = answer.Id
+		}
+	}
+}
+
+func rpcUser(u User) *RpcUser {
+	return &RpcUser{Name: u.Name, Email: u.Email, AnswerCount: u.AnswerCount}
+}
+
+func rpcQuestion(q Question) *RpcQuestion {
+	var rq *RpcQuestion = &RpcQuestion{Id: q.Id, Question: q.Question, State: q.CurrentState(), CloseReason: q.CloseReason,
+		DuplicateOfId: q.DuplicateOfId, AcceptedAnswerId: q.AcceptedAnswerId, Views: q.Views, AnswerCount: q.AnswerCount,
+		Score: q.Score, CreatedAt: q.CreatedAt.Unix(), LastActivityAt: q.LastActivityAt.Unix(), Tags: q.Tags}
+
+	if q.User != nil {
+		rq.User = rpcUser(*q.User)
+	}
+
+	return rq
+}
+
+func rpcAnswer(a Answer) *RpcAnswer {
+	var ra *RpcAnswer = &RpcAnswer{Id: a.Id, QuestionId: a.QuestionId, Answer: a.Answer, Score: a.Score, CreatedAt: a.CreatedAt.Unix()}
+
+	if a.User != nil {
+		ra.User = rpcUser(*a.User)
+	}
+
+	return ra
+}
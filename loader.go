@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+)
+
+// answers embedded into each question of a question listing
+const embeddedAnswersLimit = 3
+
+/**
+loads the questions of the ids in the order of the ids, with their authors, tags and
+latest answers, using a fixed number of queries whatever the count of the questions is
+ */
+func loadQuestions(ids []int64) ([]Question, error) {
+	questions, err := selectQuestions(ids)
+	if err != nil {
+		return questions, err
+	}
+
+	err = attachQuestionAuthors(questions)
+	if err != nil {
+		return questions, err
+	}
+
+	err = attachQuestionTags(questions)
+	if err != nil {
+		return questions, err
+	}
+
+	err = attachQuestionAnswers(questions, embeddedAnswersLimit)
+
+	return questions, err
+}
+
+/**
+loads the answers of the ids in the order of the ids, with their authors
+ */
+func loadAnswers(ids []int64) ([]Answer, error) {
+	answers, err := selectAnswers(ids)
+	if err != nil {
+		return answers, err
+	}
+
+	err = attachAnswerAuthors(answers)
+
+	return answers, err
+}
+
+func selectQuestions(ids []int64) ([]Question, error) {
+	var questions []Question = []Question{}
+
+	if len(ids) == 0 {
+		return questions, nil
+	}
+
+	placeholders, args := inIds(ids)
+	_, err := DBMap.Select(&questions, fmt.Sprintf("SELECT * FROM question WHERE id IN (%s) ORDER BY FIELD(id, %s)", placeholders, placeholders), append(args, args...)...)
+
+	return questions, err
+}
+
+func selectAnswers(ids []int64) ([]Answer, error) {
+	var answers []Answer = []Answer{}
+
+	if len(ids) == 0 {
+		return answers, nil
+	}
+
+	placeholders, args := inIds(ids)
+	_, err := DBMap.Select(&answers, fmt.Sprintf("SELECT * FROM answer WHERE id IN (%s) ORDER BY FIELD(id, %s)", placeholders, placeholders), append(args, args...)...)
+
+	return answers, err
+}
+
+/**
+loads the not deleted users of the ids by a single query
+ */
+func selectUsers(ids []int64) (map[int64]*User, error) {
+	var users []User
+	var byId map[int64]*User = make(map[int64]*User)
+
+	if len(ids) == 0 {
+		return byId, nil
+	}
+
+	placeholders, args := inIds(uniqueIds(ids))
+	_, err := DBMap.Select(&users, fmt.Sprintf("SELECT * FROM user WHERE id IN (%s) AND deleted_at IS NULL", placeholders), args...)
+
+	for k := range users {
+		byId[users[k].Id] = &users[k]
+	}
+
+	return byId, err
+}
+
+func attachQuestionAuthors(questions []Question) error {
+	var ids []int64
+
+	for _, question := range questions {
+		ids = append(ids, question.UserId)
+	}
+
+	users, err := selectUsers(ids)
+
+	for k := range questions {
+		questions[k].User = users[questions[k].UserId]
+	}
+
+	return err
+}
+
+func attachAnswerAuthors(answers []Answer) error {
+	var ids []int64
+
+	for _, answer := range answers {
+		ids = append(ids, answer.UserId)
+	}
+
+	users, err := selectUsers(ids)
+
+	for k := range answers {
+		answers[k].User = users[answers[k].UserId]
+	}
+
+	return err
+}
+
+func attachQuestionTags(questions []Question) error {
+	var tags []QuestionTag
+	var ids []int64
+	var byQuestion map[int64][]string = make(map[int64][]string)
+
+	for k := range questions {
+		questions[k].Tags = []string{}
+		ids = append(ids, questions[k].Id)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders, args := inIds(ids)
+	_, err := DBMap.Select(&tags, fmt.Sprintf("SELECT * FROM question_tag WHERE question_id IN (%s) ORDER BY tag", placeholders), args...)
+
+	for _, tag := range tags {
+		byQuestion[tag.QuestionId] = append(byQuestion[tag.QuestionId], tag.Tag)
+	}
+
+	for k := range questions {
+		if tags, ok := byQuestion[questions[k].Id]; ok {
+			questions[k].Tags = tags
+		}
+	}
+
+	return err
+}
+
+/**
+attaches the latest limit answers (with their authors) to every question by a single query
+ */
+func attachQuestionAnswers(questions []Question, limit int) error {
+	var answers []Answer
+	var ids []int64
+	var byQuestion map[int64][]Answer = make(map[int64][]Answer)
+
+	for k := range questions {
+		questions[k].Answers = []Answer{}
+		ids = append(ids, questions[k].Id)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders, args := inIds(ids)
+	query := fmt.Sprintf("SELECT * FROM answer WHERE question_id IN (%s) AND deleted_at IS NULL AND (SELECT COUNT(*) FROM answer newer WHERE newer.question_id = answer.question_id AND newer.deleted_at IS NULL AND newer.id > answer.id) < ? ORDER BY id DESC", placeholders)
+
+	_, err := DBMap.Select(&answers, query, append(args, limit)...)
+	if err != nil {
+		return err
+	}
+
+	err = attachAnswerAuthors(answers)
+
+	for _, answer := range answers {
+		byQuestion[answer.QuestionId] = append(byQuestion[answer.QuestionId], answer)
+	}
+
+	for k := range questions {
+		if answers, ok := byQuestion[questions[k].Id]; ok {
+			questions[k].Answers = answers
+		}
+	}
+
+	return err
+}
+
+/**
+the best rated limit answers (with their authors) of every question by a single query
+ */
+func selectAnswersByRate(questionIds []int64, limit int) (map[int64][]Answer, error) {
+	var answers []Answer
+	var byQuestion map[int64][]Answer = make(map[int64][]Answer)
+
+	if len(questionIds) == 0 {
+		return byQuestion, nil
+	}
+
+	placeholders, args := inIds(uniqueIds(questionIds))
+	query := fmt.Sprintf("SELECT * FROM answer WHERE question_id IN (%s) AND deleted_at IS NULL AND (SELECT COUNT(*) FROM answer better WHERE better.question_id = answer.question_id AND better.deleted_at IS NULL AND (better.score > answer.score OR (better.score = answer.score AND better.id > answer.id))) < ? ORDER BY score DESC, id DESC", placeholders)
+
+	_, err := DBMap.Select(&answers, query, append(args, limit)...)
+	if err != nil {
+		return byQuestion, err
+	}
+
+	err = attachAnswerAuthors(answers)
+
+	for _, answer := range answers {
+		byQuestion[answer.QuestionId] = append(byQuestion[answer.QuestionId], answer)
+	}
+
+	return byQuestion, err
+}
+
+type answerVotes struct {
+	AnswerId int64 `db:"answer_id" json:"-"`
+	Up       int64 `db:"up" json:"up"`
+	Down     int64 `db:"down" json:"down"`
+}
+
+/**
+up and down rate counts of the answers by a single query
+ */
+func selectAnswerVotes(answerIds []int64) (map[int64]answerVotes, error) {
+	var votes []answerVotes
+	var byAnswer map[int64]answerVotes = make(map[int64]answerVotes)
+
+	if len(answerIds) == 0 {
+		return byAnswer, nil
+	}
+
+	placeholders, args := inIds(uniqueIds(answerIds))
+	_, err := DBMap.Select(&votes, fmt.Sprintf("SELECT answer_id, SUM(rate > 0) AS up, SUM(rate < 0) AS down FROM answer_rate WHERE answer_id IN (%s) GROUP BY answer_id", placeholders), args...)
+
+	for _, v := range votes {
+		byAnswer[v.AnswerId] = v
+	}
+
+	return byAnswer, err
+}
+
+func uniqueIds(ids []int64) []int64 {
+	var unique []int64
+	var seen map[int64]bool = make(map[int64]bool)
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
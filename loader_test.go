@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+var listingTables []string = []string{"follow", "answer_rate", "answer", "question_tag", "question", "user"}
+
+/**
+50 questions of 10 answers each and a user following their tag, the first question is the one
+the answers are listed of
+ */
+func seedListings(tb testing.TB) (Question, User) {
+	useTestDatabase(tb)
+	truncateTables(tb, listingTables...)
+
+	var questions []Question = seedQuestions(tb, maxPageSize, 10)
+
+	var viewer User = User{Name: "viewer", Email: "viewer@example.com", Password: "-", TokenExpiration: time.Now()}
+	if err := DBMap.Insert(&viewer); err != nil {
+		tb.Fatal(err)
+	}
+
+	var follow Follow = NewTagFollow(viewer, "go")
+	if err := follow.Save(); err != nil {
+		tb.Fatal(err)
+	}
+
+	return questions[0], viewer
+}
+
+/**
+the listings with the data embedded into their items, by the page size
+ */
+func listings(question Question, viewer User) map[string]func(limit int) error {
+	return map[string]func(limit int) error{
+		"questions": func(limit int) error {
+			_, _, err := Question{}.getList(QuestionFilter{Sort: QuestionSortNewest, Desc: true, Limit: limit})
+			return err
+		},
+		"answers": func(limit int) error {
+			_, _, err := question.GetAnswersByRate("", limit, true)
+			return err
+		},
+		"feed": func(limit int) error {
+			_, _, err := Feed(viewer, "", limit)
+			return err
+		},
+	}
+}
+
+func TestListingsMakeAConstantNumberOfQueries(t *testing.T) {
+	question, viewer := seedListings(t)
+
+	for name, list := range listings(question, viewer) {
+		small := countQueries(t, func() error { return list(1) })
+		large := countQueries(t, func() error { return list(maxPageSize) })
+
+		if small != large {
+			t.Errorf("%s: %d queries for a page of 1, %d for a page of %d", name, small, large, maxPageSize)
+		}
+	}
+}
+
+func benchmarkListing(b *testing.B, name string) {
+	question, viewer := seedListings(b)
+	var list func(limit int) error = listings(question, viewer)[name]
+
+	for _, limit := range []int{defaultPageSize, maxPageSize} {
+		b.Run(fmt.Sprintf("limit=%d", limit), func(b *testing.B) {
+			b.ResetTimer()
+
+			queries := countQueries(b, func() error {
+				for i := 0; i < b.N; i++ {
+					if err := list(limit); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+			b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+		})
+	}
+}
+
+func BenchmarkListQuestions(b *testing.B) {
+	benchmarkListing(b, "questions")
+}
+
+func BenchmarkAnswersByRate(b *testing.B) {
+	benchmarkListing(b, "answers")
+}
+
+func BenchmarkFeed(b *testing.B) {
+	benchmarkListing(b, "feed")
+}
@@ -4,21 +4,104 @@ import (
 	"net/http"
 	"log"
 	"fmt"
+	"time"
+	"flag"
+	"os"
 )
 
 const listenPort = "8080"
 
-func main(){
-	http.HandleFunc("/user/create", PostOnly(CreateUser))
-	http.HandleFunc("/user/login", PostOnly(LoginUser))
-	http.HandleFunc("/user/list/top5", GetOnly(UsersTopFive))
+var reconcile = flag.Bool("reconcile", false, "recompute the answer counts and scores, repair the drifted ones and exit")
+var printOpenAPI = flag.Bool("openapi", false, "print the openapi specification of the routes and exit")
+var grantModerator = flag.String("grant-moderator", "", "make the user of the email a moderator and exit")
+var revokeModerator = flag.String("revoke-moderator", "", "take the moderator rights of the user of the email and exit")
+var deletedRetention = flag.Duration("deleted-retention", 30*24*time.Hour, "soft deleted users, questions and answers are purged after this period")
+var checkOpenAPI = flag.Bool("check-openapi", false, "fail when "+openAPIFile+" differs from the specification of the routes")
 
-	http.HandleFunc("/question/new", PostOnly(AuthUser(PostQuestion)))
-	http.HandleFunc("/question/list/byanswers", GetOnly(AuthUser(QuestionsByAnswer)))
+func main() {
+	flag.Parse()
 
-	http.HandleFunc("/answer/new", PostOnly(AuthUser(PostAnswer)))
-	http.HandleFunc("/answer/rate", PostOnly(AuthUser(RateAnswer)))
-	http.HandleFunc("/answer/list/byrate", GetOnly(AuthUser(QuestionAnswersByRate)))
+	if *printOpenAPI {
+		data, err := OpenAPIJson(NewAPIRouter())
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(data)
+		return
+	}
 
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s",listenPort), nil))
+	if *checkOpenAPI {
+		err := CheckOpenAPI(NewAPIRouter())
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	estabilishConnection(true)
+
+	if *grantModerator != "" || *revokeModerator != "" {
+		var u User
+		var email string = *grantModerator
+		if email == "" {
+			email = *revokeModerator
+		}
+
+		if u.LoadByEmail(email) != nil {
+			log.Fatalf("no user with the email %s", email)
+		}
+
+		err := u.SetModerator(*grantModerator != "")
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *reconcile {
+		repaired, err := ReconcileCounters()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%d rows repaired\n", repaired)
+		return
+	}
+
+	err := LoadDigestConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = RebuildSearchIndex()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = RelayOutbox()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	go purgeDeletedPeriodically(retentionInterval)
+	go relayOutboxPeriodically(outboxRelayInterval)
+	go retryWebhooksPeriodically(webhookRetryInterval)
+	go sendDigestsPeriodically(digestInterval)
+
+	go func() {
+		log.Fatal(ServeGRPC(grpcPort))
+	}()
+
+	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", listenPort), WithRequestId(NewAPIRouter())))
+}
+
+/**
+ hard deletes rows soft deleted longer than -deleted-retention ago, every interval
+ */
+func purgeDeletedPeriodically(interval time.Duration) {
+	for range time.Tick(interval) {
+		err := PurgeDeleted(time.Now().Add(-*deletedRetention))
+		if err != nil {
+			log.Println("purging deleted rows failed:", err)
+		}
+	}
 }
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+/**
+applied migration, the versions in schema_migration are not run again
+ */
+type SchemaMigration struct {
+	Version   int64     `db:"version, primarykey" json:"version"`
+	Name      string    `db:"name, size:255, notnull" json:"name"`
+	AppliedAt time.Time `db:"applied_at, notnull" json:"applied_at"`
+}
+
+/**
+schema change of the tables of an earlier release. CreateTablesIfNotExists only creates the missing tables,
+the columns added to the existing ones come from here. the steps are safe to run on a table which has the
+column already, a new database gets the current tables and only records the versions
+ */
+type migration struct {
+	Version int64
+	Name    string
+	Up      func() error
+}
+
+var migrations []migration = []migration{
+	{1, "soft delete of users, questions and answers, moderators", func() error {
+		return addColumns([]tableColumn{
+			{"user", "moderator", "BOOLEAN NOT NULL DEFAULT FALSE"},
+			{"user", "deleted_at", "DATETIME NULL"},
+			{"user", "deleted_by", "BIGINT DEFAULT 0"},
+			{"question", "deleted_at", "DATETIME NULL"},
+			{"question", "deleted_by", "BIGINT DEFAULT 0"},
+			{"answer", "deleted_at", "DATETIME NULL"},
+			{"answer", "deleted_by", "BIGINT DEFAULT 0"},
+		})
+	}},
+	{2, "duplicate questions", func() error {
+		return addColumns([]tableColumn{
+			{"question", "duplicate_of_id", "BIGINT DEFAULT 0"},
+		})
+	}},
+	{3, "question states", func() error {
+		return addColumns([]tableColumn{
+			{"question", "state", fmt.Sprintf("VARCHAR(16) NOT NULL DEFAULT '%s'", QuestionOpen)},
+			{"question", "close_reason", "VARCHAR(32) DEFAULT ''"},
+			{"question", "state_changed_at", "DATETIME NULL"},
+			{"question", "state_changed_by", "BIGINT DEFAULT 0"},
+		})
+	}},
+	{4, "question listing columns", func() error {
+		// the rows of before have no creation time, they get the time of the migration
+		return addColumns([]tableColumn{
+			{"question", "accepted_answer_id", "BIGINT DEFAULT 0"},
+			{"question", "views", "BIGINT NOT NULL DEFAULT 0"},
+			{"question", "created_at", "DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP"},
+			{"question", "last_activity_at", "DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP"},
+			{"answer", "created_at", "DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP"},
+		})
+	}},
+	{5, "answer counts and scores", func() error {
+		err := addColumns([]tableColumn{
+			{"user", "answer_count", "BIGINT NOT NULL DEFAULT 0"},
+			{"question", "answer_count", "BIGINT NOT NULL DEFAULT 0"},
+			{"question", "score", "BIGINT NOT NULL DEFAULT 0"},
+			{"answer", "score", "BIGINT NOT NULL DEFAULT 0"},
+		})
+		if err != nil {
+			return err
+		}
+
+		// the new counters start at 0, count the existing answers and rates into them
+		_, err = ReconcileCounters()
+
+		return err
+	}},
+	{6, "claims of the outbox events", func() error {
+		return addColumns([]tableColumn{
+			{"event_outbox", "claimed_at", "DATETIME NULL"},
+		})
+	}},
+}
+
+type tableColumn struct {
+	table      string
+	column     string
+	definition string
+}
+
+/**
+adds the columns the tables do not have yet
+ */
+func addColumns(columns []tableColumn) error {
+	for _, column := range columns {
+		count, err := DBMap.SelectInt("SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?", column.table, column.column)
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			continue
+		}
+
+		_, err = DBMap.Exec(fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN `%s` %s", column.table, column.column, column.definition))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/**
+runs the migrations which have not been applied yet in version order, stops at the first failing one
+ */
+func Migrate() error {
+	for _, m := range migrations {
+		applied, err := DBMap.SelectInt("SELECT COUNT(*) FROM schema_migration WHERE version = ?", m.Version)
+		if err != nil {
+			return err
+		}
+
+		if applied > 0 {
+			continue
+		}
+
+		err = m.Up()
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %v", m.Version, m.Name, err)
+		}
+
+		err = DBMap.Insert(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// there are no comments on questions and answers yet, the comment notifications come with them
+const (
+	// someone answered a question of the user
+	NotificationAnswer = "answer"
+	// someone rated an answer of the user
+	NotificationVote = "vote"
+	// the author of the question accepted an answer of the user
+	NotificationAccept = "accept"
+	// someone mentioned the user in a question or an answer
+	NotificationMention = "mention"
+)
+
+var notificationTypes []string = []string{NotificationAnswer, NotificationVote, NotificationAccept, NotificationMention}
+
+// mentions beyond this many in one question or answer are ignored
+const maxMentions = 10
+
+// @ followed by the email of a user or by a name without spaces, not preceded by a word so emails are no mentions
+var mentionPattern *regexp.Regexp = regexp.MustCompile(`(?:^|[^\w@.+-])@([\w.+-]*\w(?:@[\w-]+(?:\.[\w-]+)+)?)`)
+
+/**
+inbox entry of a user. the notifications of one type on one question are grouped while unread:
+a new one bumps Count, AnswerId and UpdatedAt of the unread entry instead of adding another
+ */
+type Notification struct {
+	Id         int64      `db:"id, primarykey, autoincrement" json:"id"`
+	UserId     int64      `db:"user_id, notnull" json:"-"`
+	Type       string     `db:"type, size:16, notnull" json:"type"`
+	QuestionId int64      `db:"question_id, notnull" json:"question_id"`
+	AnswerId   int64      `db:"answer_id" json:"answer_id,omitempty"`
+	Count      int64      `db:"count, notnull" json:"count"`
+	ReadAt     *time.Time `db:"read_at" json:"read_at,omitempty"`
+	CreatedAt  time.Time  `db:"created_at, notnull" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at, notnull" json:"updated_at"`
+}
+
+func (n *Notification) Load(id int64) error {
+	err := DBMap.SelectOne(n, "SELECT * FROM notification WHERE id = ?", id)
+
+	return err
+}
+
+/**
+notifications of the user, latest first, only the unread ones when unreadOnly is set
+ */
+func (n Notification) GetByUser(user User, unreadOnly bool, cursor string, limit int) ([]Notification, PageInfo, error) {
+	var notifications []Notification = []Notification{}
+	var conditions []string = []string{"user_id = ?"}
+
+	if unreadOnly {
+		conditions = append(conditions, "read_at IS NULL")
+	}
+
+	ids, info, err := keysetQuery{Table: "notification", SortExpr: "notification.updated_at", SortKind: sortKindTime, Conditions: conditions, Args: []interface{}{user.Id}, Desc: true, Cursor: cursor, Limit: limit, Count: true}.Ids()
+	if err != nil || len(ids) == 0 {
+		return notifications, info, err
+	}
+
+	placeholders, args := inIds(ids)
+	_, err = DBMap.Select(&notifications, fmt.Sprintf("SELECT * FROM notification WHERE id IN (%s) ORDER BY FIELD(id, %s)", placeholders, placeholders), append(args, args...)...)
+
+	return notifications, info, err
+}
+
+func (n *Notification) Save() error {
+	return Transaction(n.SaveIn)
+}
+
+func (n *Notification) SaveIn(uow *UnitOfWork) error {
+	if n.Id == 0 {
+		return uow.Executor().Insert(n)
+	}
+
+	_, err := uow.Executor().Update(n)
+
+	return err
+}
+
+func (n *Notification) MarkRead(read bool) error {
+	if read && n.ReadAt == nil {
+		var now time.Time = time.Now()
+		n.ReadAt = &now
+	} else if !read {
+		n.ReadAt = nil
+	}
+
+	return n.Save()
+}
+
+/**
+marks every unread notification of the user read, returns how many were marked
+ */
+func MarkNotificationsRead(user User) (int64, error) {
+	result, err := DBMap.Exec("UPDATE notification SET read_at = ? WHERE user_id = ? AND read_at IS NULL", time.Now(), user.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+type notificationCount struct {
+	Type  string `db:"type"`
+	Count int64  `db:"count"`
+}
+
+/**
+unread notifications of the user by type, every type is listed
+ */
+func UnreadNotificationCounts(user User) (map[string]int64, error) {
+	var rows []notificationCount
+	var counts map[string]int64 = make(map[string]int64)
+
+	_, err := DBMap.Select(&rows, "SELECT type, COUNT(*) AS count FROM notification WHERE user_id = ? AND read_at IS NULL GROUP BY type", user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range notificationTypes {
+		counts[t] = 0
+	}
+
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+
+	return counts, nil
+}
+
+/**
+adds the notification to the inbox of the user, grouped into the unread notification of the same type
+on the question if there is one. does nothing when the user has turned the type off.
+the notifications of a user are serialized on the row of the user: locking the unread notification
+alone takes a gap lock when there is none yet, and two first notifications both inserting after
+it deadlock or are not grouped
+ */
+func Notify(userId int64, notificationType string, questionId int64, answerId int64) error {
+	enabled, err := NotificationEnabled(userId, notificationType)
+	if err != nil || !enabled {
+		return err
+	}
+
+	return Transaction(func(uow *UnitOfWork) error {
+		var notification Notification
+		var now time.Time = time.Now()
+
+		_, err := uow.Executor().SelectInt("SELECT id FROM user WHERE id = ? FOR UPDATE", userId)
+		if err != nil {
+			return err
+		}
+
+		err = uow.Executor().SelectOne(&notification, "SELECT * FROM notification WHERE user_id = ? AND type = ? AND question_id = ? AND read_at IS NULL ORDER BY id DESC LIMIT 1 FOR UPDATE", userId, notificationType, questionId)
+		if err == sql.ErrNoRows {
+			notification = Notification{UserId: userId, Type: notificationType, QuestionId: questionId, AnswerId: answerId, Count: 1, CreatedAt: now, UpdatedAt: now}
+			return uow.Executor().Insert(&notification)
+		} else if err != nil {
+			return err
+		}
+
+		notification.Count++
+		notification.AnswerId = answerId
+		notification.UpdatedAt = now
+		_, err = uow.Executor().Update(&notification)
+
+		return err
+	})
+}
+
+/**
+the mentioned emails and names of the text, lower cased, each once, at most maxMentions
+ */
+func ParseMentions(text string) []string {
+	var mentions []string = []string{}
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		var mention string = strings.ToLower(match[1])
+		if containsString(mentions, mention) {
+			continue
+		}
+
+		mentions = append(mentions, mention)
+		if len(mentions) == maxMentions {
+			break
+		}
+	}
+
+	return mentions
+}
+
+/**
+users mentioned in the text by their email, or by their name when no other user has the same name
+ */
+func MentionedUsers(text string) ([]User, error) {
+	var users []User
+	var mentioned []User = []User{}
+	var byName map[string][]User = make(map[string][]User)
+
+	var mentions []string = ParseMentions(text)
+	if len(mentions) == 0 {
+		return mentioned, nil
+	}
+
+	var placeholders string = strings.TrimSuffix(strings.Repeat("?, ", len(mentions)), ", ")
+	var args []interface{}
+	for _, mention := range mentions {
+		args = append(args, mention)
+	}
+
+	_, err := DBMap.Select(&users, fmt.Sprintf("SELECT * FROM user WHERE deleted_at IS NULL AND (LOWER(email) IN (%[1]s) OR LOWER(name) IN (%[1]s))", placeholders), append(args, args...)...)
+	if err != nil {
+		return mentioned, err
+	}
+
+	for _, u := range users {
+		byName[strings.ToLower(u.Name)] = append(byName[strings.ToLower(u.Name)], u)
+	}
+
+	for _, mention := range mentions {
+		for _, u := range users {
+			if strings.ToLower(u.Email) == mention {
+				mentioned = append(mentioned, u)
+			}
+		}
+
+		if !strings.Contains(mention, "@") && len(byName[mention]) == 1 {
+			mentioned = append(mentioned, byName[mention][0])
+		}
+	}
+
+	return uniqueUsers(mentioned), nil
+}
+
+func uniqueUsers(users []User) []User {
+	var unique []User = []User{}
+	var seen map[int64]bool = make(map[int64]bool)
+
+	for _, u := range users {
+		if !seen[u.Id] {
+			seen[u.Id] = true
+			unique = append(unique, u)
+		}
+	}
+
+	return unique
+}
+
+/**
+notifies the users mentioned in the text of a question or an answer, but not the ones in skip
+ */
+func notifyMentions(text string, questionId int64, answerId int64, skip ...int64) error {
+	users, err := MentionedUsers(text)
+	if err != nil {
+		return err
+	}
+
+	for _, u := range users {
+		if containsId(skip, u.Id) {
+			continue
+		}
+
+		err = Notify(u.Id, NotificationMention, questionId, answerId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func containsId(ids []int64, id int64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+/**
+turned off notification type of a user, the types without a setting are on
+ */
+type NotificationSetting struct {
+	Id      int64  `db:"id, primarykey, autoincrement" json:"-"`
+	UserId  int64  `db:"user_id, notnull" json:"-"`
+	Type    string `db:"type, size:16, notnull" json:"type"`
+	Enabled bool   `db:"enabled, notnull" json:"enabled"`
+}
+
+func NotificationEnabled(userId int64, notificationType string) (bool, error) {
+	var setting NotificationSetting
+
+	err := DBMap.SelectOne(&setting, "SELECT * FROM notification_setting WHERE user_id = ? AND type = ?", userId, notificationType)
+	if err == sql.ErrNoRows {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return setting.Enabled, nil
+}
+
+/**
+notification types of the user with whether they are on
+ */
+func NotificationPreferences(user User) (map[string]bool, error) {
+	var settings []NotificationSetting
+	var preferences map[string]bool = make(map[string]bool)
+
+	_, err := DBMap.Select(&settings, "SELECT * FROM notification_setting WHERE user_id = ?", user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range notificationTypes {
+		preferences[t] = true
+	}
+
+	for _, setting := range settings {
+		preferences[setting.Type] = setting.Enabled
+	}
+
+	return preferences, nil
+}
+
+func SetNotificationPreference(user User, notificationType string, enabled bool) error {
+	_, err := DBMap.Exec("INSERT INTO notification_setting (user_id, type, enabled) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE enabled = VALUES(enabled)", user.Id, notificationType, enabled)
+
+	return err
+}
+
+/**
+notifies the author of the question of a new answer, the author of the answer of its rates
+and its acceptance and the users mentioned in new questions and answers. nobody is notified
+of its own doing, the author of the question is not notified of a mention in an answer to it
+ */
+func subscribeNotifications(bus *EventBus) {
+	bus.SubscribeAsync("notifications", func(e DomainEvent) error {
+		var question Question
+		var answer Answer
+
+		switch event := e.(type) {
+		case QuestionPosted:
+			err := question.Load(event.QuestionId)
+			if err != nil {
+				return err
+			}
+			return notifyMentions(question.Question, event.QuestionId, 0, event.UserId)
+		case AnswerPosted:
+			err := question.Load(event.QuestionId)
+			if err != nil {
+				return err
+			}
+			err = answer.Load(event.AnswerId)
+			if err != nil {
+				return err
+			}
+
+			err = notifyMentions(answer.Answer, event.QuestionId, event.AnswerId, event.UserId, question.UserId)
+			if err != nil || question.UserId == event.UserId {
+				return err
+			}
+			return Notify(question.UserId, NotificationAnswer, event.QuestionId, event.AnswerId)
+		case AnswerRated:
+			err := answer.Load(event.AnswerId)
+			if err != nil || answer.UserId == event.UserId {
+				return err
+			}
+			return Notify(answer.UserId, NotificationVote, event.QuestionId, event.AnswerId)
+		case AnswerAccepted:
+			err := answer.Load(event.AnswerId)
+			if err != nil || answer.UserId == event.ByUserId {
+				return err
+			}
+			return Notify(answer.UserId, NotificationAccept, event.QuestionId, event.AnswerId)
+		}
+
+		return nil
+	}, EventQuestionPosted, EventAnswerPosted, EventAnswerRated, EventAnswerAccepted)
+}
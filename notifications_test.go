@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	var cases map[string][]string = map[string][]string{
+		"thanks @Ada, and @bob.":                      {"ada", "bob"},
+		"@ada@example.com knows":                      {"ada@example.com"},
+		"write to ada@example.com":                    {},
+		"@ada @ADA @ada":                              {"ada"},
+		"(@grace_h) and @ alone":                      {"grace_h"},
+		"ask @ada.lovelace@example.co.uk or @linus-t": {"ada.lovelace@example.co.uk", "linus-t"},
+	}
+
+	for text, expected := range cases {
+		if mentions := ParseMentions(text); !reflect.DeepEqual(mentions, expected) {
+			t.Errorf("%q: got %q, want %q", text, mentions, expected)
+		}
+	}
+
+	var many []string
+	for i := 0; i < 2*maxMentions; i++ {
+		many = append(many, fmt.Sprintf("@user%d", i))
+	}
+	if mentions := ParseMentions(strings.Join(many, " ")); len(mentions) != maxMentions {
+		t.Errorf("got %d mentions, want %d", len(mentions), maxMentions)
+	}
+}
+
+func TestMentionedUsers(t *testing.T) {
+	useTestDatabase(t)
+	truncateTables(t, "user")
+
+	var users []User
+	for _, u := range []User{{Name: "ada", Email: "ada@example.com"}, {Name: "sam", Email: "sam1@example.com"}, {Name: "sam", Email: "sam2@example.com"}} {
+		u.Password = "-"
+		if err := DBMap.Insert(&u); err != nil {
+			t.Fatal(err)
+		}
+		users = append(users, u)
+	}
+
+	mentioned, err := MentionedUsers("@Ada and @sam, cc @sam2@example.com and @nobody")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// sam is ambiguous, only its email mentions one of them
+	if len(mentioned) != 2 || mentioned[0].Id != users[0].Id || mentioned[1].Id != users[2].Id {
+		t.Fatalf("mentioned %+v, want ada and sam2", mentioned)
+	}
+}
+
+func TestConcurrentNotificationsAreGrouped(t *testing.T) {
+	useTestDatabase(t)
+	truncateTables(t, "notification", "notification_setting", "user")
+
+	var user User = User{Name: "asker", Email: "asker@example.com", Password: "-"}
+	if err := DBMap.Insert(&user); err != nil {
+		t.Fatal(err)
+	}
+
+	// answers posted on the question at the same time
+	var wg sync.WaitGroup
+	var errs chan error = make(chan error, 10)
+	for i := 1; i <= 10; i++ {
+		wg.Add(1)
+		go func(answerId int64) {
+			defer wg.Done()
+			errs <- Notify(user.Id, NotificationAnswer, 1, answerId)
+		}(int64(i))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var notifications []Notification
+	if _, err := DBMap.Select(&notifications, "SELECT * FROM notification WHERE user_id = ?", user.Id); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 1 || notifications[0].Count != 10 {
+		t.Fatalf("notifications %+v, want one of 10 answers", notifications)
+	}
+}
@@ -0,0 +1,517 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// committed snapshot of the specification, see -check-openapi
+const openAPIFile = "openapi.json"
+
+/**
+documentation of a resource route: Request is the request type the handler decodes (the fields
+in the path pattern become path parameters, the others query parameters of GET and the json body
+of the other methods), Response is the value the handler responds with, nil for an empty response.
+ContentType is the media type of a response which is not json
+ */
+type Operation struct {
+	Summary     string
+	Request     interface{}
+	Response    interface{}
+	ContentType string
+	Auth        bool
+	Moderator   bool
+}
+
+/**
+page envelope of the listings, documents the Page responses by the type of their items
+ */
+type pageOf struct {
+	Item interface{}
+}
+
+var apiOperations map[string]Operation = map[string]Operation{
+	"POST /users":                  {Summary: "register a user", Request: CreateUserRequest{}},
+	"POST /sessions":               {Summary: "log in, the token goes to the access-token header of the next requests", Request: LoginRequest{}, Response: LoginResponse{}},
+	"GET /users/top":               {Summary: "top 5 users by answer count", Auth: true, Response: []User{}},
+	"GET /users/deleted":           {Summary: "soft deleted users, latest deletion first", Auth: true, Moderator: true, Request: PageRequest{}, Response: pageOf{User{}}},
+	"DELETE /users/{email}":        {Summary: "soft delete a user, the user itself or a moderator", Auth: true, Request: DeleteUserRequest{}},
+	"POST /users/{email}/restore":  {Summary: "restore a soft deleted user", Auth: true, Moderator: true, Request: UserRequest{}},
+	"PUT /users/{email}/follow":    {Summary: "follow the user", Auth: true, Request: UserRequest{}, Response: Follow{}},
+	"DELETE /users/{email}/follow": {Summary: "stop following the user", Auth: true, Request: UserRequest{}},
+
+	"GET /questions":                             {Summary: "list questions by sort, filters and cursor", Auth: true, Request: ListQuestionsRequest{}, Response: pageOf{Question{}}},
+	"POST /questions":                            {Summary: "ask a question, the response lists the questions it may duplicate", Auth: true, Request: PostQuestionRequest{}, Response: PostQuestionResponse{}},
+	"GET /questions/deleted":                     {Summary: "soft deleted questions, latest deletion first", Auth: true, Moderator: true, Request: PageRequest{}, Response: pageOf{Question{}}},
+	"DELETE /questions/{question_id}":            {Summary: "soft delete a question, its author or a moderator", Auth: true, Request: QuestionRequest{}},
+	"POST /questions/{question_id}/restore":      {Summary: "restore a soft deleted question", Auth: true, Moderator: true, Request: QuestionRequest{}},
+	"GET /questions/{question_id}/similar":       {Summary: "questions similar to the question", Auth: true, Request: QuestionRequest{}, Response: []SearchHit{}},
+	"PUT /questions/{question_id}/duplicate":     {Summary: "close the question as a duplicate, duplicate_of 0 reopens it", Auth: true, Moderator: true, Request: MarkDuplicateRequest{}},
+	"PUT /questions/{question_id}/state":         {Summary: "set the state of the question overriding the votes", Auth: true, Moderator: true, Request: ChangeStateRequest{}, Response: Question{}},
+	"POST /questions/{question_id}/close-votes":  {Summary: "vote to close the question", Auth: true, Request: CloseVoteRequest{}, Response: Question{}},
+	"POST /questions/{question_id}/reopen-votes": {Summary: "vote to reopen the question", Auth: true, Request: QuestionRequest{}, Response: Question{}},
+	"GET /questions/{question_id}/answers":       {Summary: "answers of the question by rate", Auth: true, Request: QuestionAnswersRequest{}, Response: pageOf{Answer{}}},
+	"POST /questions/{question_id}/answers":      {Summary: "answer the question", Auth: true, Request: PostAnswerRequest{}, Response: CreatedResponse{}},
+	"PUT /questions/{question_id}/follow":        {Summary: "follow the question", Auth: true, Request: QuestionRequest{}, Response: Follow{}},
+	"DELETE /questions/{question_id}/follow":     {Summary: "stop following the question", Auth: true, Request: QuestionRequest{}},
+
+	"GET /answers/deleted":              {Summary: "soft deleted answers, latest deletion first", Auth: true, Moderator: true, Request: PageRequest{}, Response: pageOf{Answer{}}},
+	"DELETE /answers/{answer_id}":       {Summary: "soft delete an answer, its author or a moderator", Auth: true, Request: AnswerRequest{}},
+	"POST /answers/{answer_id}/restore": {Summary: "restore a soft deleted answer", Auth: true, Moderator: true, Request: AnswerRequest{}},
+	"POST /answers/{answer_id}/votes":   {Summary: "rate the answer", Auth: true, Request: RateAnswerRequest{}},
+	"POST /answers/{answer_id}/accept":  {Summary: "accept the answer of an own question", Auth: true, Request: AnswerRequest{}},
+
+	"PUT /tags/{tag}/follow":    {Summary: "follow the tag", Auth: true, Request: TagRequest{}, Response: Follow{}},
+	"DELETE /tags/{tag}/follow": {Summary: "stop following the tag", Auth: true, Request: TagRequest{}},
+	"GET /follows":              {Summary: "questions, tags and users the user follows, latest first", Auth: true, Request: PageRequest{}, Response: pageOf{Follow{}}},
+	"GET /feed":                 {Summary: "new questions and answers of everything the user follows, latest first", Auth: true, Request: PageRequest{}, Response: pageOf{FeedItem{}}},
+
+	"GET /search":          {Summary: "full-text search of questions and answers, quoted parts are phrases", Auth: true, Request: SearchRequest{}, Response: pageOf{SearchHit{}}},
+	"POST /search/reindex": {Summary: "rebuild the search index", Auth: true, Moderator: true},
+
+	"GET /cache/stats": {Summary: "cache hit and miss counters", Auth: true, Moderator: true, Response: CacheStats{}},
+
+	"GET /notifications":                   {Summary: "notifications of the user, latest first", Auth: true, Request: ListNotificationsRequest{}, Response: pageOf{Notification{}}},
+	"GET /notifications/unread-count":      {Summary: "unread notifications of the user, in all and by type", Auth: true, Response: UnreadCountResponse{}},
+	"POST /notifications/read":             {Summary: "mark every notification of the user read", Auth: true},
+	"PUT /notifications/{notification_id}": {Summary: "mark a notification read or unread", Auth: true, Request: MarkNotificationRequest{}, Response: Notification{}},
+	"GET /notifications/preferences":       {Summary: "notification types of the user and whether they are on", Auth: true, Response: map[string]bool{}},
+	"PUT /notifications/preferences":       {Summary: "turn notification types on or off", Auth: true, Request: NotificationPreferencesRequest{}, Response: map[string]bool{}},
+
+	"GET /digests/settings":     {Summary: "how often the user gets the email digest", Auth: true, Response: DigestSettingsResponse{}},
+	"PUT /digests/settings":     {Summary: "set how often the user gets the email digest, off stops it", Auth: true, Request: DigestSettingsRequest{}, Response: DigestSettingsResponse{}},
+	"GET /digests/unsubscribe":  {Summary: "page of the unsubscribe link of the digest asking to confirm, changes nothing, no login needed", Request: UnsubscribeRequest{}, ContentType: "text/html"},
+	"POST /digests/unsubscribe": {Summary: "stop the email digest of the user of the token, posted by the confirmation page and the one-click unsubscribe of the List-Unsubscribe header. the token is in the query as for GET", Response: DigestSettingsResponse{}},
+
+	"POST /webhooks":                        {Summary: "register a webhook, the response carries its signing secret", Auth: true, Request: CreateWebhookRequest{}, Response: WebhookResponse{}},
+	"GET /webhooks":                         {Summary: "webhooks of the user", Auth: true, Response: []WebhookResponse{}},
+	"DELETE /webhooks/{webhook_id}":         {Summary: "delete a webhook with its delivery log, its owner or a moderator", Auth: true, Request: WebhookRequest{}},
+	"GET /webhooks/{webhook_id}/deliveries": {Summary: "delivery log of a webhook, latest first", Auth: true, Request: WebhookDeliveriesRequest{}, Response: pageOf{WebhookDelivery{}}},
+	"POST /webhooks/{webhook_id}/deliveries/{delivery_id}/replay": {Summary: "deliver the payload of a delivery again", Auth: true, Request: DeliveryRequest{}, Response: WebhookDelivery{}},
+}
+
+/**
+routes outside of the version groups, they serialize by the latest version
+ */
+var rootOperations map[string]Operation = map[string]Operation{
+	"GET /openapi.json": {Summary: "this specification", Response: map[string]interface{}{}},
+	"POST /graphql":     {Summary: "graphql queries and mutations of the resources", Auth: true, Request: GraphQLRequest{}, Response: GraphQLResponse{}},
+	"GET /events":       {Summary: "server-sent events of a question, a tag or the notifications of the user, Last-Event-ID resumes the stream", Auth: true, Request: StreamEventsRequest{}, ContentType: "text/event-stream"},
+}
+
+/**
+OpenAPI 3 document of the versioned routes of the router and of the routes outside of the versions
+(rootOperations), the schemas are derived from the request types and from the wire types of the
+responses in each version. the unversioned and the old paths are deprecated aliases and left out
+ */
+func BuildOpenAPI(router *Router) (map[string]interface{}, error) {
+	var paths map[string]interface{} = make(map[string]interface{})
+	var sb *schemaBuilder = &schemaBuilder{components: make(map[string]interface{})}
+
+	for _, version := range apiVersions {
+		var prefix string = "/" + version.Name
+		sb.wire = version.WireTypes
+
+		for _, rte := range router.routes {
+			if !strings.HasPrefix(rte.pattern, prefix+"/") {
+				continue
+			}
+
+			var pattern string = strings.TrimPrefix(rte.pattern, prefix)
+			op, ok := apiOperations[rte.method+" "+pattern]
+			if !ok {
+				return nil, fmt.Errorf("route %s %s is not documented", rte.method, rte.pattern)
+			}
+
+			item, _ := paths[rte.pattern].(map[string]interface{})
+			if item == nil {
+				item = make(map[string]interface{})
+				paths[rte.pattern] = item
+			}
+			item[strings.ToLower(rte.method)] = sb.operation(rte, op, version.Name+operationName(rte.method, pattern))
+		}
+	}
+
+	sb.wire = apiVersions[len(apiVersions)-1].WireTypes
+	for _, rte := range router.routes {
+		op, ok := rootOperations[rte.method+" "+rte.pattern]
+		if !ok {
+			continue
+		}
+
+		var name string = operationName(rte.method, rte.pattern)
+		item, _ := paths[rte.pattern].(map[string]interface{})
+		if item == nil {
+			item = make(map[string]interface{})
+			paths[rte.pattern] = item
+		}
+		item[strings.ToLower(rte.method)] = sb.operation(rte, op, strings.ToLower(name[:1])+name[1:])
+	}
+
+	for key := range rootOperations {
+		var parts []string = strings.SplitN(key, " ", 2)
+		if _, ok := paths[parts[1]].(map[string]interface{})[strings.ToLower(parts[0])]; !ok {
+			return nil, fmt.Errorf("operation %s has no route", key)
+		}
+	}
+
+	sb.components["Problem"] = sb.structSchema(reflect.TypeOf(Problem{}))
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "questions",
+			"version": apiVersions[len(apiVersions)-1].Name,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": sb.components,
+			"securitySchemes": map[string]interface{}{
+				"accessToken": map[string]interface{}{"type": "apiKey", "in": "header", "name": "access-token"},
+			},
+		},
+	}, nil
+}
+
+/**
+the document as indented json, as it is served and committed to openAPIFile
+ */
+func OpenAPIJson(router *Router) ([]byte, error) {
+	spec, err := BuildOpenAPI(router)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+/**
+fails when the routes or the types have changed without openAPIFile being regenerated (-openapi)
+ */
+func CheckOpenAPI(router *Router) error {
+	generated, err := OpenAPIJson(router)
+	if err != nil {
+		return err
+	}
+
+	committed, err := ioutil.ReadFile(openAPIFile)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(generated, committed) {
+		return fmt.Errorf("%s is out of date, regenerate it by -openapi", openAPIFile)
+	}
+
+	return nil
+}
+
+func ServeOpenAPI(router *Router) handler {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := OpenAPIJson(router)
+		if err != nil {
+			writeError(w, r, CodeInternal)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	}
+}
+
+type schemaBuilder struct {
+	components map[string]interface{}
+	wire       map[reflect.Type]reflect.Type
+}
+
+func (sb *schemaBuilder) operation(rte route, op Operation, id string) map[string]interface{} {
+	var operation map[string]interface{} = map[string]interface{}{
+		"summary":     op.Summary,
+		"operationId": id,
+	}
+	var parameters []interface{} = []interface{}{}
+	var pathParams []string
+
+	for _, segment := range rte.segments {
+		if strings.HasPrefix(segment, "{") {
+			pathParams = append(pathParams, strings.Trim(segment, "{}"))
+		}
+	}
+
+	if op.Request != nil {
+		var body map[string]interface{} = map[string]interface{}{}
+		var required []string
+
+		for _, field := range requestFields(reflect.TypeOf(op.Request)) {
+			var schema map[string]interface{} = sb.fieldSchema(field)
+			var name string = jsonName(field)
+			var isRequired bool = strings.Contains(field.Tag.Get("validate"), "required")
+
+			if containsString(pathParams, name) {
+				parameters = append(parameters, map[string]interface{}{"name": name, "in": "path", "required": true, "schema": schema})
+			} else if rte.method == http.MethodGet {
+				parameters = append(parameters, map[string]interface{}{"name": name, "in": "query", "required": isRequired, "schema": schema})
+			} else {
+				body[name] = schema
+				if isRequired {
+					required = append(required, name)
+				}
+			}
+		}
+
+		if len(body) > 0 {
+			var schema map[string]interface{} = map[string]interface{}{"type": "object", "properties": body, "additionalProperties": false}
+			if len(required) > 0 {
+				schema["required"] = required
+			}
+			operation["requestBody"] = map[string]interface{}{
+				"required": len(required) > 0,
+				"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}},
+			}
+		}
+	}
+
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	var ok map[string]interface{} = map[string]interface{}{"description": "ok"}
+	if op.ContentType != "" {
+		ok["content"] = map[string]interface{}{op.ContentType: map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}}
+	} else if op.Response != nil {
+		ok["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": sb.responseSchema(op.Response)}}
+	}
+
+	operation["responses"] = map[string]interface{}{
+		"200": ok,
+		"default": map[string]interface{}{
+			"description": "error, the code tells which one",
+			"content":     map[string]interface{}{"application/problem+json": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/Problem"}}},
+		},
+	}
+
+	if op.Auth {
+		operation["security"] = []interface{}{map[string]interface{}{"accessToken": []string{}}}
+	}
+
+	if op.Moderator {
+		operation["description"] = "moderators only"
+	}
+
+	return operation
+}
+
+func (sb *schemaBuilder) responseSchema(response interface{}) map[string]interface{} {
+	if page, ok := response.(pageOf); ok {
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"items": map[string]interface{}{"type": "array", "items": sb.schema(reflect.TypeOf(page.Item))},
+				"next":  map[string]interface{}{"type": "string"},
+				"prev":  map[string]interface{}{"type": "string"},
+				"total": map[string]interface{}{"type": "integer", "format": "int64"},
+			},
+			"required": []string{"items"},
+		}
+	}
+
+	return sb.schema(reflect.TypeOf(response))
+}
+
+func (sb *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	if wire, ok := sb.wire[t]; ok {
+		t = wire
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		var schema map[string]interface{} = sb.schema(t.Elem())
+		if _, ref := schema["$ref"]; ref {
+			return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": sb.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": sb.schema(t.Elem())}
+	case reflect.Struct:
+		var name string = strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+		if _, ok := sb.components[name]; !ok {
+			// placeholder first, so the recursive types end at the $ref
+			sb.components[name] = map[string]interface{}{}
+			sb.components[name] = sb.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+
+	return map[string]interface{}{}
+}
+
+func (sb *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	var properties map[string]interface{} = make(map[string]interface{})
+	var required []string
+
+	for _, field := range requestFields(t) {
+		var name string = jsonName(field)
+		properties[name] = sb.fieldSchema(field)
+		if strings.Contains(field.Tag.Get("validate"), "required") {
+			required = append(required, name)
+		}
+	}
+
+	var schema map[string]interface{} = map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+/**
+schema of the field with the constraints of its validate tag
+ */
+func (sb *schemaBuilder) fieldSchema(field reflect.StructField) map[string]interface{} {
+	var schema map[string]interface{} = sb.schema(field.Type)
+	var t reflect.Type = field.Type
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var rules []string = strings.Split(field.Tag.Get("validate"), ",")
+	for k, rule := range rules {
+		if rule == "dive" && t.Kind() == reflect.Slice {
+			if items, ok := schema["items"].(map[string]interface{}); ok {
+				applyRules(items, t.Elem(), rules[k+1:])
+			}
+			rules = rules[:k]
+			break
+		}
+	}
+
+	applyRules(schema, t, rules)
+
+	return schema
+}
+
+/**
+sets the constraints of the rules on the schema of a value of type t
+ */
+func applyRules(schema map[string]interface{}, t reflect.Type, rules []string) {
+	for _, rule := range rules {
+		var name, param string = rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, param = rule[:i], rule[i+1:]
+		}
+		bound, _ := strconv.ParseInt(param, 10, 64)
+
+		switch {
+		case name == "min" && t.Kind() == reflect.String:
+			schema["minLength"] = bound
+		case name == "max" && t.Kind() == reflect.String:
+			schema["maxLength"] = bound
+		case name == "min" && t.Kind() == reflect.Slice:
+			schema["minItems"] = bound
+		case name == "max" && t.Kind() == reflect.Slice:
+			schema["maxItems"] = bound
+		case name == "min":
+			schema["minimum"] = bound
+		case name == "max":
+			schema["maximum"] = bound
+		case name == "oneof" && t.Kind() == reflect.String:
+			schema["enum"] = strings.Split(param, " ")
+		case name == "oneof":
+			var values []int64
+			for _, value := range strings.Split(param, " ") {
+				i, _ := strconv.ParseInt(value, 10, 64)
+				values = append(values, i)
+			}
+			schema["enum"] = values
+		case name == "email":
+			schema["format"] = "email"
+		case name == "date":
+			schema["format"] = "date"
+		case name == "url":
+			schema["format"] = "uri"
+		}
+	}
+}
+
+/**
+json fields of the struct, the fields of embedded structs included
+ */
+func requestFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		var field reflect.StructField = t.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, requestFields(field.Type)...)
+			continue
+		}
+
+		if field.PkgPath != "" || jsonName(field) == "-" {
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func jsonName(field reflect.StructField) string {
+	var name string = strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+/**
+operation name of the route: the method and the literal segments of the pattern, GetQuestionsAnswers for GET /questions/{question_id}/answers
+ */
+func operationName(method string, pattern string) string {
+	var verbs map[string]string = map[string]string{http.MethodGet: "Get", http.MethodPost: "Post", http.MethodPut: "Put", http.MethodDelete: "Delete"}
+	var words []string = []string{verbs[method]}
+	var segments []string = splitPath(pattern)
+
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, "{") {
+			continue
+		}
+		for _, part := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '.' }) {
+			words = append(words, strings.ToUpper(part[:1])+part[1:])
+		}
+	}
+
+	return strings.Join(words, "")
+}
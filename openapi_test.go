@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIFileIsUpToDate(t *testing.T) {
+	err := CheckOpenAPI(NewAPIRouter())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	var router *Router = NewAPIRouter()
+
+	spec, err := BuildOpenAPI(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths map[string]interface{} = spec["paths"].(map[string]interface{})
+	var prefix string = "/" + apiVersions[len(apiVersions)-1].Name
+
+	for _, rte := range router.routes {
+		var documented bool = strings.HasPrefix(rte.pattern, prefix+"/")
+		if _, ok := rootOperations[rte.method+" "+rte.pattern]; ok {
+			documented = true
+		}
+		if !documented {
+			continue
+		}
+
+		item, _ := paths[rte.pattern].(map[string]interface{})
+		if _, ok := item[strings.ToLower(rte.method)]; !ok {
+			t.Errorf("%s %s is not in the specification", rte.method, rte.pattern)
+		}
+	}
+
+	for _, key := range []string{"GET /openapi.json", "POST /graphql", "GET /events"} {
+		var parts []string = strings.SplitN(key, " ", 2)
+		item, _ := paths[parts[1]].(map[string]interface{})
+		if _, ok := item[strings.ToLower(parts[0])]; !ok {
+			t.Errorf("%s is not in the specification", key)
+		}
+	}
+}
+
+func TestOpenAPIEventStreamIsNotJson(t *testing.T) {
+	spec, err := BuildOpenAPI(NewAPIRouter())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var operation map[string]interface{} = spec["paths"].(map[string]interface{})["/events"].(map[string]interface{})[strings.ToLower(http.MethodGet)].(map[string]interface{})
+	var content map[string]interface{} = operation["responses"].(map[string]interface{})["200"].(map[string]interface{})["content"].(map[string]interface{})
+
+	if _, ok := content["text/event-stream"]; !ok {
+		t.Errorf("the event stream responds with %v", content)
+	}
+}
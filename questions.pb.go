@@ -0,0 +1,1210 @@
+// gRPC api of the questions service, served on grpcPort next to the http api.
+// every call needs the access token of POST /v1/sessions in the access-token metadata.
+// the errors carry the code of the http error catalog as the reason of a google.rpc.ErrorInfo.
+//
+// the go code is generated into package main, the message names are prefixed by Rpc so they
+// do not collide with the models:
+//   protoc --go_out=. --go-grpc_out=. questions.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: questions.proto
+
+package main
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RpcUser struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	AnswerCount   int64                  `protobuf:"varint,3,opt,name=answer_count,json=answerCount,proto3" json:"answer_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcUser) Reset() {
+	*x = RpcUser{}
+	mi := &file_questions_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcUser) ProtoMessage() {}
+
+func (x *RpcUser) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcUser.ProtoReflect.Descriptor instead.
+func (*RpcUser) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RpcUser) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RpcUser) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *RpcUser) GetAnswerCount() int64 {
+	if x != nil {
+		return x.AnswerCount
+	}
+	return 0
+}
+
+type RpcQuestion struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Question         string                 `protobuf:"bytes,2,opt,name=question,proto3" json:"question,omitempty"`
+	State            string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	CloseReason      string                 `protobuf:"bytes,4,opt,name=close_reason,json=closeReason,proto3" json:"close_reason,omitempty"`
+	DuplicateOfId    int64                  `protobuf:"varint,5,opt,name=duplicate_of_id,json=duplicateOfId,proto3" json:"duplicate_of_id,omitempty"`
+	AcceptedAnswerId int64                  `protobuf:"varint,6,opt,name=accepted_answer_id,json=acceptedAnswerId,proto3" json:"accepted_answer_id,omitempty"`
+	Views            int64                  `protobuf:"varint,7,opt,name=views,proto3" json:"views,omitempty"`
+	AnswerCount      int64                  `protobuf:"varint,8,opt,name=answer_count,json=answerCount,proto3" json:"answer_count,omitempty"`
+	Score            int64                  `protobuf:"varint,9,opt,name=score,proto3" json:"score,omitempty"`
+	// unix seconds
+	CreatedAt      int64    `protobuf:"varint,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastActivityAt int64    `protobuf:"varint,11,opt,name=last_activity_at,json=lastActivityAt,proto3" json:"last_activity_at,omitempty"`
+	Tags           []string `protobuf:"bytes,12,rep,name=tags,proto3" json:"tags,omitempty"`
+	User           *RpcUser `protobuf:"bytes,13,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RpcQuestion) Reset() {
+	*x = RpcQuestion{}
+	mi := &file_questions_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcQuestion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcQuestion) ProtoMessage() {}
+
+func (x *RpcQuestion) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcQuestion.ProtoReflect.Descriptor instead.
+func (*RpcQuestion) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RpcQuestion) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RpcQuestion) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *RpcQuestion) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *RpcQuestion) GetCloseReason() string {
+	if x != nil {
+		return x.CloseReason
+	}
+	return ""
+}
+
+func (x *RpcQuestion) GetDuplicateOfId() int64 {
+	if x != nil {
+		return x.DuplicateOfId
+	}
+	return 0
+}
+
+func (x *RpcQuestion) GetAcceptedAnswerId() int64 {
+	if x != nil {
+		return x.AcceptedAnswerId
+	}
+	return 0
+}
+
+func (x *RpcQuestion) GetViews() int64 {
+	if x != nil {
+		return x.Views
+	}
+	return 0
+}
+
+func (x *RpcQuestion) GetAnswerCount() int64 {
+	if x != nil {
+		return x.AnswerCount
+	}
+	return 0
+}
+
+func (x *RpcQuestion) GetScore() int64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *RpcQuestion) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *RpcQuestion) GetLastActivityAt() int64 {
+	if x != nil {
+		return x.LastActivityAt
+	}
+	return 0
+}
+
+func (x *RpcQuestion) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *RpcQuestion) GetUser() *RpcUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type RpcAnswer struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	QuestionId int64                  `protobuf:"varint,2,opt,name=question_id,json=questionId,proto3" json:"question_id,omitempty"`
+	Answer     string                 `protobuf:"bytes,3,opt,name=answer,proto3" json:"answer,omitempty"`
+	Score      int64                  `protobuf:"varint,4,opt,name=score,proto3" json:"score,omitempty"`
+	// unix seconds
+	CreatedAt     int64    `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	User          *RpcUser `protobuf:"bytes,6,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcAnswer) Reset() {
+	*x = RpcAnswer{}
+	mi := &file_questions_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcAnswer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcAnswer) ProtoMessage() {}
+
+func (x *RpcAnswer) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcAnswer.ProtoReflect.Descriptor instead.
+func (*RpcAnswer) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RpcAnswer) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RpcAnswer) GetQuestionId() int64 {
+	if x != nil {
+		return x.QuestionId
+	}
+	return 0
+}
+
+func (x *RpcAnswer) GetAnswer() string {
+	if x != nil {
+		return x.Answer
+	}
+	return ""
+}
+
+func (x *RpcAnswer) GetScore() int64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *RpcAnswer) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *RpcAnswer) GetUser() *RpcUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type RpcQuestionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	QuestionId    int64                  `protobuf:"varint,1,opt,name=question_id,json=questionId,proto3" json:"question_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcQuestionRequest) Reset() {
+	*x = RpcQuestionRequest{}
+	mi := &file_questions_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcQuestionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcQuestionRequest) ProtoMessage() {}
+
+func (x *RpcQuestionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcQuestionRequest.ProtoReflect.Descriptor instead.
+func (*RpcQuestionRequest) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RpcQuestionRequest) GetQuestionId() int64 {
+	if x != nil {
+		return x.QuestionId
+	}
+	return 0
+}
+
+type RpcAnswerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AnswerId      int64                  `protobuf:"varint,1,opt,name=answer_id,json=answerId,proto3" json:"answer_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcAnswerRequest) Reset() {
+	*x = RpcAnswerRequest{}
+	mi := &file_questions_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcAnswerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcAnswerRequest) ProtoMessage() {}
+
+func (x *RpcAnswerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcAnswerRequest.ProtoReflect.Descriptor instead.
+func (*RpcAnswerRequest) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RpcAnswerRequest) GetAnswerId() int64 {
+	if x != nil {
+		return x.AnswerId
+	}
+	return 0
+}
+
+type RpcPostQuestionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Question      string                 `protobuf:"bytes,1,opt,name=question,proto3" json:"question,omitempty"`
+	Tags          []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcPostQuestionRequest) Reset() {
+	*x = RpcPostQuestionRequest{}
+	mi := &file_questions_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcPostQuestionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcPostQuestionRequest) ProtoMessage() {}
+
+func (x *RpcPostQuestionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcPostQuestionRequest.ProtoReflect.Descriptor instead.
+func (*RpcPostQuestionRequest) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RpcPostQuestionRequest) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *RpcPostQuestionRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type RpcPostQuestionReply struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Question *RpcQuestion           `protobuf:"bytes,1,opt,name=question,proto3" json:"question,omitempty"`
+	// similar questions the new one may duplicate
+	Duplicates    []*RpcQuestion `protobuf:"bytes,2,rep,name=duplicates,proto3" json:"duplicates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcPostQuestionReply) Reset() {
+	*x = RpcPostQuestionReply{}
+	mi := &file_questions_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcPostQuestionReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcPostQuestionReply) ProtoMessage() {}
+
+func (x *RpcPostQuestionReply) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcPostQuestionReply.ProtoReflect.Descriptor instead.
+func (*RpcPostQuestionReply) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RpcPostQuestionReply) GetQuestion() *RpcQuestion {
+	if x != nil {
+		return x.Question
+	}
+	return nil
+}
+
+func (x *RpcPostQuestionReply) GetDuplicates() []*RpcQuestion {
+	if x != nil {
+		return x.Duplicates
+	}
+	return nil
+}
+
+type RpcListQuestionsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Cursor string                 `protobuf:"bytes,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit  int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// newest, activity, answers, score or views
+	Sort string `protobuf:"bytes,3,opt,name=sort,proto3" json:"sort,omitempty"`
+	// asc or desc
+	Order         string `protobuf:"bytes,4,opt,name=order,proto3" json:"order,omitempty"`
+	Tag           string `protobuf:"bytes,5,opt,name=tag,proto3" json:"tag,omitempty"`
+	Author        string `protobuf:"bytes,6,opt,name=author,proto3" json:"author,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcListQuestionsRequest) Reset() {
+	*x = RpcListQuestionsRequest{}
+	mi := &file_questions_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcListQuestionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcListQuestionsRequest) ProtoMessage() {}
+
+func (x *RpcListQuestionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcListQuestionsRequest.ProtoReflect.Descriptor instead.
+func (*RpcListQuestionsRequest) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RpcListQuestionsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *RpcListQuestionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *RpcListQuestionsRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *RpcListQuestionsRequest) GetOrder() string {
+	if x != nil {
+		return x.Order
+	}
+	return ""
+}
+
+func (x *RpcListQuestionsRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *RpcListQuestionsRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+type RpcQuestionPage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*RpcQuestion         `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Next          string                 `protobuf:"bytes,2,opt,name=next,proto3" json:"next,omitempty"`
+	Prev          string                 `protobuf:"bytes,3,opt,name=prev,proto3" json:"prev,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcQuestionPage) Reset() {
+	*x = RpcQuestionPage{}
+	mi := &file_questions_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcQuestionPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcQuestionPage) ProtoMessage() {}
+
+func (x *RpcQuestionPage) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcQuestionPage.ProtoReflect.Descriptor instead.
+func (*RpcQuestionPage) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RpcQuestionPage) GetItems() []*RpcQuestion {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *RpcQuestionPage) GetNext() string {
+	if x != nil {
+		return x.Next
+	}
+	return ""
+}
+
+func (x *RpcQuestionPage) GetPrev() string {
+	if x != nil {
+		return x.Prev
+	}
+	return ""
+}
+
+type RpcCloseVoteRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	QuestionId int64                  `protobuf:"varint,1,opt,name=question_id,json=questionId,proto3" json:"question_id,omitempty"`
+	Reason     string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	// canonical question of a duplicate vote, 0 for the other reasons
+	DuplicateOf   int64 `protobuf:"varint,3,opt,name=duplicate_of,json=duplicateOf,proto3" json:"duplicate_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcCloseVoteRequest) Reset() {
+	*x = RpcCloseVoteRequest{}
+	mi := &file_questions_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcCloseVoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcCloseVoteRequest) ProtoMessage() {}
+
+func (x *RpcCloseVoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcCloseVoteRequest.ProtoReflect.Descriptor instead.
+func (*RpcCloseVoteRequest) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RpcCloseVoteRequest) GetQuestionId() int64 {
+	if x != nil {
+		return x.QuestionId
+	}
+	return 0
+}
+
+func (x *RpcCloseVoteRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RpcCloseVoteRequest) GetDuplicateOf() int64 {
+	if x != nil {
+		return x.DuplicateOf
+	}
+	return 0
+}
+
+type RpcPostAnswerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	QuestionId    int64                  `protobuf:"varint,1,opt,name=question_id,json=questionId,proto3" json:"question_id,omitempty"`
+	Answer        string                 `protobuf:"bytes,2,opt,name=answer,proto3" json:"answer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcPostAnswerRequest) Reset() {
+	*x = RpcPostAnswerRequest{}
+	mi := &file_questions_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcPostAnswerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcPostAnswerRequest) ProtoMessage() {}
+
+func (x *RpcPostAnswerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcPostAnswerRequest.ProtoReflect.Descriptor instead.
+func (*RpcPostAnswerRequest) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RpcPostAnswerRequest) GetQuestionId() int64 {
+	if x != nil {
+		return x.QuestionId
+	}
+	return 0
+}
+
+func (x *RpcPostAnswerRequest) GetAnswer() string {
+	if x != nil {
+		return x.Answer
+	}
+	return ""
+}
+
+type RpcListAnswersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	QuestionId    int64                  `protobuf:"varint,1,opt,name=question_id,json=questionId,proto3" json:"question_id,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcListAnswersRequest) Reset() {
+	*x = RpcListAnswersRequest{}
+	mi := &file_questions_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcListAnswersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcListAnswersRequest) ProtoMessage() {}
+
+func (x *RpcListAnswersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcListAnswersRequest.ProtoReflect.Descriptor instead.
+func (*RpcListAnswersRequest) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RpcListAnswersRequest) GetQuestionId() int64 {
+	if x != nil {
+		return x.QuestionId
+	}
+	return 0
+}
+
+func (x *RpcListAnswersRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *RpcListAnswersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type RpcAnswerPage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*RpcAnswer           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Next          string                 `protobuf:"bytes,2,opt,name=next,proto3" json:"next,omitempty"`
+	Prev          string                 `protobuf:"bytes,3,opt,name=prev,proto3" json:"prev,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcAnswerPage) Reset() {
+	*x = RpcAnswerPage{}
+	mi := &file_questions_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcAnswerPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcAnswerPage) ProtoMessage() {}
+
+func (x *RpcAnswerPage) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcAnswerPage.ProtoReflect.Descriptor instead.
+func (*RpcAnswerPage) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RpcAnswerPage) GetItems() []*RpcAnswer {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *RpcAnswerPage) GetNext() string {
+	if x != nil {
+		return x.Next
+	}
+	return ""
+}
+
+func (x *RpcAnswerPage) GetPrev() string {
+	if x != nil {
+		return x.Prev
+	}
+	return ""
+}
+
+type RpcRateAnswerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AnswerId      int64                  `protobuf:"varint,1,opt,name=answer_id,json=answerId,proto3" json:"answer_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcRateAnswerRequest) Reset() {
+	*x = RpcRateAnswerRequest{}
+	mi := &file_questions_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcRateAnswerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcRateAnswerRequest) ProtoMessage() {}
+
+func (x *RpcRateAnswerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcRateAnswerRequest.ProtoReflect.Descriptor instead.
+func (*RpcRateAnswerRequest) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RpcRateAnswerRequest) GetAnswerId() int64 {
+	if x != nil {
+		return x.AnswerId
+	}
+	return 0
+}
+
+type RpcTopUsersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcTopUsersRequest) Reset() {
+	*x = RpcTopUsersRequest{}
+	mi := &file_questions_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcTopUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcTopUsersRequest) ProtoMessage() {}
+
+func (x *RpcTopUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcTopUsersRequest.ProtoReflect.Descriptor instead.
+func (*RpcTopUsersRequest) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RpcTopUsersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type RpcTopUsersReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*RpcUser             `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcTopUsersReply) Reset() {
+	*x = RpcTopUsersReply{}
+	mi := &file_questions_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcTopUsersReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcTopUsersReply) ProtoMessage() {}
+
+func (x *RpcTopUsersReply) ProtoReflect() protoreflect.Message {
+	mi := &file_questions_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcTopUsersReply.ProtoReflect.Descriptor instead.
+func (*RpcTopUsersReply) Descriptor() ([]byte, []int) {
+	return file_questions_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RpcTopUsersReply) GetUsers() []*RpcUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+var File_questions_proto protoreflect.FileDescriptor
+
+const file_questions_proto_rawDesc = "" +
+	"\n" +
+	"\x0fquestions.proto\x12\fquestions.v1\"V\n" +
+	"\aRpcUser\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12!\n" +
+	"\fanswer_count\x18\x03 \x01(\x03R\vanswerCount\"\x9f\x03\n" +
+	"\vRpcQuestion\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
+	"\bquestion\x18\x02 \x01(\tR\bquestion\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12!\n" +
+	"\fclose_reason\x18\x04 \x01(\tR\vcloseReason\x12&\n" +
+	"\x0fduplicate_of_id\x18\x05 \x01(\x03R\rduplicateOfId\x12,\n" +
+	"\x12accepted_answer_id\x18\x06 \x01(\x03R\x10acceptedAnswerId\x12\x14\n" +
+	"\x05views\x18\a \x01(\x03R\x05views\x12!\n" +
+	"\fanswer_count\x18\b \x01(\x03R\vanswerCount\x12\x14\n" +
+	"\x05score\x18\t \x01(\x03R\x05score\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\x03R\tcreatedAt\x12(\n" +
+	"\x10last_activity_at\x18\v \x01(\x03R\x0elastActivityAt\x12\x12\n" +
+	"\x04tags\x18\f \x03(\tR\x04tags\x12)\n" +
+	"\x04user\x18\r \x01(\v2\x15.questions.v1.RpcUserR\x04user\"\xb4\x01\n" +
+	"\tRpcAnswer\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1f\n" +
+	"\vquestion_id\x18\x02 \x01(\x03R\n" +
+	"questionId\x12\x16\n" +
+	"\x06answer\x18\x03 \x01(\tR\x06answer\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x03R\x05score\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12)\n" +
+	"\x04user\x18\x06 \x01(\v2\x15.questions.v1.RpcUserR\x04user\"5\n" +
+	"\x12RpcQuestionRequest\x12\x1f\n" +
+	"\vquestion_id\x18\x01 \x01(\x03R\n" +
+	"questionId\"/\n" +
+	"\x10RpcAnswerRequest\x12\x1b\n" +
+	"\tanswer_id\x18\x01 \x01(\x03R\banswerId\"H\n" +
+	"\x16RpcPostQuestionRequest\x12\x1a\n" +
+	"\bquestion\x18\x01 \x01(\tR\bquestion\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\"\x88\x01\n" +
+	"\x14RpcPostQuestionReply\x125\n" +
+	"\bquestion\x18\x01 \x01(\v2\x19.questions.v1.RpcQuestionR\bquestion\x129\n" +
+	"\n" +
+	"duplicates\x18\x02 \x03(\v2\x19.questions.v1.RpcQuestionR\n" +
+	"duplicates\"\x9b\x01\n" +
+	"\x17RpcListQuestionsRequest\x12\x16\n" +
+	"\x06cursor\x18\x01 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x12\n" +
+	"\x04sort\x18\x03 \x01(\tR\x04sort\x12\x14\n" +
+	"\x05order\x18\x04 \x01(\tR\x05order\x12\x10\n" +
+	"\x03tag\x18\x05 \x01(\tR\x03tag\x12\x16\n" +
+	"\x06author\x18\x06 \x01(\tR\x06author\"j\n" +
+	"\x0fRpcQuestionPage\x12/\n" +
+	"\x05items\x18\x01 \x03(\v2\x19.questions.v1.RpcQuestionR\x05items\x12\x12\n" +
+	"\x04next\x18\x02 \x01(\tR\x04next\x12\x12\n" +
+	"\x04prev\x18\x03 \x01(\tR\x04prev\"q\n" +
+	"\x13RpcCloseVoteRequest\x12\x1f\n" +
+	"\vquestion_id\x18\x01 \x01(\x03R\n" +
+	"questionId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12!\n" +
+	"\fduplicate_of\x18\x03 \x01(\x03R\vduplicateOf\"O\n" +
+	"\x14RpcPostAnswerRequest\x12\x1f\n" +
+	"\vquestion_id\x18\x01 \x01(\x03R\n" +
+	"questionId\x12\x16\n" +
+	"\x06answer\x18\x02 \x01(\tR\x06answer\"f\n" +
+	"\x15RpcListAnswersRequest\x12\x1f\n" +
+	"\vquestion_id\x18\x01 \x01(\x03R\n" +
+	"questionId\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"f\n" +
+	"\rRpcAnswerPage\x12-\n" +
+	"\x05items\x18\x01 \x03(\v2\x17.questions.v1.RpcAnswerR\x05items\x12\x12\n" +
+	"\x04next\x18\x02 \x01(\tR\x04next\x12\x12\n" +
+	"\x04prev\x18\x03 \x01(\tR\x04prev\"?\n" +
+	"\x14RpcRateAnswerRequest\x12\x1b\n" +
+	"\tanswer_id\x18\x01 \x01(\x03R\banswerIdJ\x04\b\x02\x10\x03R\x04rate\"*\n" +
+	"\x12RpcTopUsersRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\"?\n" +
+	"\x10RpcTopUsersReply\x12+\n" +
+	"\x05users\x18\x01 \x03(\v2\x15.questions.v1.RpcUserR\x05users2\xe9\x06\n" +
+	"\tQuestions\x12X\n" +
+	"\fPostQuestion\x12$.questions.v1.RpcPostQuestionRequest\x1a\".questions.v1.RpcPostQuestionReply\x12J\n" +
+	"\vGetQuestion\x12 .questions.v1.RpcQuestionRequest\x1a\x19.questions.v1.RpcQuestion\x12U\n" +
+	"\rListQuestions\x12%.questions.v1.RpcListQuestionsRequest\x1a\x1d.questions.v1.RpcQuestionPage\x12I\n" +
+	"\tCloseVote\x12!.questions.v1.RpcCloseVoteRequest\x1a\x19.questions.v1.RpcQuestion\x12I\n" +
+	"\n" +
+	"ReopenVote\x12 .questions.v1.RpcQuestionRequest\x1a\x19.questions.v1.RpcQuestion\x12I\n" +
+	"\n" +
+	"PostAnswer\x12\".questions.v1.RpcPostAnswerRequest\x1a\x17.questions.v1.RpcAnswer\x12O\n" +
+	"\vListAnswers\x12#.questions.v1.RpcListAnswersRequest\x1a\x1b.questions.v1.RpcAnswerPage\x12I\n" +
+	"\n" +
+	"RateAnswer\x12\".questions.v1.RpcRateAnswerRequest\x1a\x17.questions.v1.RpcAnswer\x12G\n" +
+	"\fAcceptAnswer\x12\x1e.questions.v1.RpcAnswerRequest\x1a\x17.questions.v1.RpcAnswer\x12L\n" +
+	"\bTopUsers\x12 .questions.v1.RpcTopUsersRequest\x1a\x1e.questions.v1.RpcTopUsersReply\x12K\n" +
+	"\fWatchAnswers\x12 .questions.v1.RpcQuestionRequest\x1a\x17.questions.v1.RpcAnswer0\x01B\tZ\a./;mainb\x06proto3"
+
+var (
+	file_questions_proto_rawDescOnce sync.Once
+	file_questions_proto_rawDescData []byte
+)
+
+func file_questions_proto_rawDescGZIP() []byte {
+	file_questions_proto_rawDescOnce.Do(func() {
+		file_questions_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_questions_proto_rawDesc), len(file_questions_proto_rawDesc)))
+	})
+	return file_questions_proto_rawDescData
+}
+
+var file_questions_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_questions_proto_goTypes = []any{
+	(*RpcUser)(nil),                 // 0: questions.v1.RpcUser
+	(*RpcQuestion)(nil),             // 1: questions.v1.RpcQuestion
+	(*RpcAnswer)(nil),               // 2: questions.v1.RpcAnswer
+	(*RpcQuestionRequest)(nil),      // 3: questions.v1.RpcQuestionRequest
+	(*RpcAnswerRequest)(nil),        // 4: questions.v1.RpcAnswerRequest
+	(*RpcPostQuestionRequest)(nil),  // 5: questions.v1.RpcPostQuestionRequest
+	(*RpcPostQuestionReply)(nil),    // 6: questions.v1.RpcPostQuestionReply
+	(*RpcListQuestionsRequest)(nil), // 7: questions.v1.RpcListQuestionsRequest
+	(*RpcQuestionPage)(nil),         // 8: questions.v1.RpcQuestionPage
+	(*RpcCloseVoteRequest)(nil),     // 9: questions.v1.RpcCloseVoteRequest
+	(*RpcPostAnswerRequest)(nil),    // 10: questions.v1.RpcPostAnswerRequest
+	(*RpcListAnswersRequest)(nil),   // 11: questions.v1.RpcListAnswersRequest
+	(*RpcAnswerPage)(nil),           // 12: questions.v1.RpcAnswerPage
+	(*RpcRateAnswerRequest)(nil),    // 13: questions.v1.RpcRateAnswerRequest
+	(*RpcTopUsersRequest)(nil),      // 14: questions.v1.RpcTopUsersRequest
+	(*RpcTopUsersReply)(nil),        // 15: questions.v1.RpcTopUsersReply
+}
+var file_questions_proto_depIdxs = []int32{
+	0,  // 0: questions.v1.RpcQuestion.user:type_name -> questions.v1.RpcUser
+	0,  // 1: questions.v1.RpcAnswer.user:type_name -> questions.v1.RpcUser
+	1,  // 2: questions.v1.RpcPostQuestionReply.question:type_name -> questions.v1.RpcQuestion
+	1,  // 3: questions.v1.RpcPostQuestionReply.duplicates:type_name -> questions.v1.RpcQuestion
+	1,  // 4: questions.v1.RpcQuestionPage.items:type_name -> questions.v1.RpcQuestion
+	2,  // 5: questions.v1.RpcAnswerPage.items:type_name -> questions.v1.RpcAnswer
+	0,  // 6: questions.v1.RpcTopUsersReply.users:type_name -> questions.v1.RpcUser
+	5,  // 7: questions.v1.Questions.PostQuestion:input_type -> questions.v1.RpcPostQuestionRequest
+	3,  // 8: questions.v1.Questions.GetQuestion:input_type -> questions.v1.RpcQuestionRequest
+	7,  // 9: questions.v1.Questions.ListQuestions:input_type -> questions.v1.RpcListQuestionsRequest
+	9,  // 10: questions.v1.Questions.CloseVote:input_type -> questions.v1.RpcCloseVoteRequest
+	3,  // 11: questions.v1.Questions.ReopenVote:input_type -> questions.v1.RpcQuestionRequest
+	10, // 12: questions.v1.Questions.PostAnswer:input_type -> questions.v1.RpcPostAnswerRequest
+	11, // 13: questions.v1.Questions.ListAnswers:input_type -> questions.v1.RpcListAnswersRequest
+	13, // 14: questions.v1.Questions.RateAnswer:input_type -> questions.v1.RpcRateAnswerRequest
+	4,  // 15: questions.v1.Questions.AcceptAnswer:input_type -> questions.v1.RpcAnswerRequest
+	14, // 16: questions.v1.Questions.TopUsers:input_type -> questions.v1.RpcTopUsersRequest
+	3,  // 17: questions.v1.Questions.WatchAnswers:input_type -> questions.v1.RpcQuestionRequest
+	6,  // 18: questions.v1.Questions.PostQuestion:output_type -> questions.v1.RpcPostQuestionReply
+	1,  // 19: questions.v1.Questions.GetQuestion:output_type -> questions.v1.RpcQuestion
+	8,  // 20: questions.v1.Questions.ListQuestions:output_type -> questions.v1.RpcQuestionPage
+	1,  // 21: questions.v1.Questions.CloseVote:output_type -> questions.v1.RpcQuestion
+	1,  // 22: questions.v1.Questions.ReopenVote:output_type -> questions.v1.RpcQuestion
+	2,  // 23: questions.v1.Questions.PostAnswer:output_type -> questions.v1.RpcAnswer
+	12, // 24: questions.v1.Questions.ListAnswers:output_type -> questions.v1.RpcAnswerPage
+	2,  // 25: questions.v1.Questions.RateAnswer:output_type -> questions.v1.RpcAnswer
+	2,  // 26: questions.v1.Questions.AcceptAnswer:output_type -> questions.v1.RpcAnswer
+	15, // 27: questions.v1.Questions.TopUsers:output_type -> questions.v1.RpcTopUsersReply
+	2,  // 28: questions.v1.Questions.WatchAnswers:output_type -> questions.v1.RpcAnswer
+	18, // [18:29] is the sub-list for method output_type
+	7,  // [7:18] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_questions_proto_init() }
+func file_questions_proto_init() {
+	if File_questions_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_questions_proto_rawDesc), len(file_questions_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_questions_proto_goTypes,
+		DependencyIndexes: file_questions_proto_depIdxs,
+		MessageInfos:      file_questions_proto_msgTypes,
+	}.Build()
+	File_questions_proto = out.File
+	file_questions_proto_goTypes = nil
+	file_questions_proto_depIdxs = nil
+}
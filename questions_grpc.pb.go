@@ -0,0 +1,515 @@
+// gRPC api of the questions service, served on grpcPort next to the http api.
+// every call needs the access token of POST /v1/sessions in the access-token metadata.
+// the errors carry the code of the http error catalog as the reason of a google.rpc.ErrorInfo.
+//
+// the go code is generated into package main, the message names are prefixed by Rpc so they
+// do not collide with the models:
+//   protoc --go_out=. --go-grpc_out=. questions.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: questions.proto
+
+package main
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Questions_PostQuestion_FullMethodName  = "/questions.v1.Questions/PostQuestion"
+	Questions_GetQuestion_FullMethodName   = "/questions.v1.Questions/GetQuestion"
+	Questions_ListQuestions_FullMethodName = "/questions.v1.Questions/ListQuestions"
+	Questions_CloseVote_FullMethodName     = "/questions.v1.Questions/CloseVote"
+	Questions_ReopenVote_FullMethodName    = "/questions.v1.Questions/ReopenVote"
+	Questions_PostAnswer_FullMethodName    = "/questions.v1.Questions/PostAnswer"
+	Questions_ListAnswers_FullMethodName   = "/questions.v1.Questions/ListAnswers"
+	Questions_RateAnswer_FullMethodName    = "/questions.v1.Questions/RateAnswer"
+	Questions_AcceptAnswer_FullMethodName  = "/questions.v1.Questions/AcceptAnswer"
+	Questions_TopUsers_FullMethodName      = "/questions.v1.Questions/TopUsers"
+	Questions_WatchAnswers_FullMethodName  = "/questions.v1.Questions/WatchAnswers"
+)
+
+// QuestionsClient is the client API for Questions service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type QuestionsClient interface {
+	PostQuestion(ctx context.Context, in *RpcPostQuestionRequest, opts ...grpc.CallOption) (*RpcPostQuestionReply, error)
+	GetQuestion(ctx context.Context, in *RpcQuestionRequest, opts ...grpc.CallOption) (*RpcQuestion, error)
+	ListQuestions(ctx context.Context, in *RpcListQuestionsRequest, opts ...grpc.CallOption) (*RpcQuestionPage, error)
+	CloseVote(ctx context.Context, in *RpcCloseVoteRequest, opts ...grpc.CallOption) (*RpcQuestion, error)
+	ReopenVote(ctx context.Context, in *RpcQuestionRequest, opts ...grpc.CallOption) (*RpcQuestion, error)
+	PostAnswer(ctx context.Context, in *RpcPostAnswerRequest, opts ...grpc.CallOption) (*RpcAnswer, error)
+	ListAnswers(ctx context.Context, in *RpcListAnswersRequest, opts ...grpc.CallOption) (*RpcAnswerPage, error)
+	RateAnswer(ctx context.Context, in *RpcRateAnswerRequest, opts ...grpc.CallOption) (*RpcAnswer, error)
+	AcceptAnswer(ctx context.Context, in *RpcAnswerRequest, opts ...grpc.CallOption) (*RpcAnswer, error)
+	TopUsers(ctx context.Context, in *RpcTopUsersRequest, opts ...grpc.CallOption) (*RpcTopUsersReply, error)
+	// streams the answers posted to the question from the call on, until the client cancels
+	WatchAnswers(ctx context.Context, in *RpcQuestionRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RpcAnswer], error)
+}
+
+type questionsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQuestionsClient(cc grpc.ClientConnInterface) QuestionsClient {
+	return &questionsClient{cc}
+}
+
+func (c *questionsClient) PostQuestion(ctx context.Context, in *RpcPostQuestionRequest, opts ...grpc.CallOption) (*RpcPostQuestionReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RpcPostQuestionReply)
+	err := c.cc.Invoke(ctx, Questions_PostQuestion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) GetQuestion(ctx context.Context, in *RpcQuestionRequest, opts ...grpc.CallOption) (*RpcQuestion, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RpcQuestion)
+	err := c.cc.Invoke(ctx, Questions_GetQuestion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) ListQuestions(ctx context.Context, in *RpcListQuestionsRequest, opts ...grpc.CallOption) (*RpcQuestionPage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RpcQuestionPage)
+	err := c.cc.Invoke(ctx, Questions_ListQuestions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) CloseVote(ctx context.Context, in *RpcCloseVoteRequest, opts ...grpc.CallOption) (*RpcQuestion, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RpcQuestion)
+	err := c.cc.Invoke(ctx, Questions_CloseVote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) ReopenVote(ctx context.Context, in *RpcQuestionRequest, opts ...grpc.CallOption) (*RpcQuestion, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RpcQuestion)
+	err := c.cc.Invoke(ctx, Questions_ReopenVote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) PostAnswer(ctx context.Context, in *RpcPostAnswerRequest, opts ...grpc.CallOption) (*RpcAnswer, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RpcAnswer)
+	err := c.cc.Invoke(ctx, Questions_PostAnswer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) ListAnswers(ctx context.Context, in *RpcListAnswersRequest, opts ...grpc.CallOption) (*RpcAnswerPage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RpcAnswerPage)
+	err := c.cc.Invoke(ctx, Questions_ListAnswers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) RateAnswer(ctx context.Context, in *RpcRateAnswerRequest, opts ...grpc.CallOption) (*RpcAnswer, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RpcAnswer)
+	err := c.cc.Invoke(ctx, Questions_RateAnswer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) AcceptAnswer(ctx context.Context, in *RpcAnswerRequest, opts ...grpc.CallOption) (*RpcAnswer, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RpcAnswer)
+	err := c.cc.Invoke(ctx, Questions_AcceptAnswer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) TopUsers(ctx context.Context, in *RpcTopUsersRequest, opts ...grpc.CallOption) (*RpcTopUsersReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RpcTopUsersReply)
+	err := c.cc.Invoke(ctx, Questions_TopUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) WatchAnswers(ctx context.Context, in *RpcQuestionRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RpcAnswer], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Questions_ServiceDesc.Streams[0], Questions_WatchAnswers_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RpcQuestionRequest, RpcAnswer]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Questions_WatchAnswersClient = grpc.ServerStreamingClient[RpcAnswer]
+
+// QuestionsServer is the server API for Questions service.
+// All implementations must embed UnimplementedQuestionsServer
+// for forward compatibility.
+type QuestionsServer interface {
+	PostQuestion(context.Context, *RpcPostQuestionRequest) (*RpcPostQuestionReply, error)
+	GetQuestion(context.Context, *RpcQuestionRequest) (*RpcQuestion, error)
+	ListQuestions(context.Context, *RpcListQuestionsRequest) (*RpcQuestionPage, error)
+	CloseVote(context.Context, *RpcCloseVoteRequest) (*RpcQuestion, error)
+	ReopenVote(context.Context, *RpcQuestionRequest) (*RpcQuestion, error)
+	PostAnswer(context.Context, *RpcPostAnswerRequest) (*RpcAnswer, error)
+	ListAnswers(context.Context, *RpcListAnswersRequest) (*RpcAnswerPage, error)
+	RateAnswer(context.Context, *RpcRateAnswerRequest) (*RpcAnswer, error)
+	AcceptAnswer(context.Context, *RpcAnswerRequest) (*RpcAnswer, error)
+	TopUsers(context.Context, *RpcTopUsersRequest) (*RpcTopUsersReply, error)
+	// streams the answers posted to the question from the call on, until the client cancels
+	WatchAnswers(*RpcQuestionRequest, grpc.ServerStreamingServer[RpcAnswer]) error
+	mustEmbedUnimplementedQuestionsServer()
+}
+
+// UnimplementedQuestionsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedQuestionsServer struct{}
+
+func (UnimplementedQuestionsServer) PostQuestion(context.Context, *RpcPostQuestionRequest) (*RpcPostQuestionReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostQuestion not implemented")
+}
+func (UnimplementedQuestionsServer) GetQuestion(context.Context, *RpcQuestionRequest) (*RpcQuestion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuestion not implemented")
+}
+func (UnimplementedQuestionsServer) ListQuestions(context.Context, *RpcListQuestionsRequest) (*RpcQuestionPage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListQuestions not implemented")
+}
+func (UnimplementedQuestionsServer) CloseVote(context.Context, *RpcCloseVoteRequest) (*RpcQuestion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloseVote not implemented")
+}
+func (UnimplementedQuestionsServer) ReopenVote(context.Context, *RpcQuestionRequest) (*RpcQuestion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReopenVote not implemented")
+}
+func (UnimplementedQuestionsServer) PostAnswer(context.Context, *RpcPostAnswerRequest) (*RpcAnswer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostAnswer not implemented")
+}
+func (UnimplementedQuestionsServer) ListAnswers(context.Context, *RpcListAnswersRequest) (*RpcAnswerPage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAnswers not implemented")
+}
+func (UnimplementedQuestionsServer) RateAnswer(context.Context, *RpcRateAnswerRequest) (*RpcAnswer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RateAnswer not implemented")
+}
+func (UnimplementedQuestionsServer) AcceptAnswer(context.Context, *RpcAnswerRequest) (*RpcAnswer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AcceptAnswer not implemented")
+}
+func (UnimplementedQuestionsServer) TopUsers(context.Context, *RpcTopUsersRequest) (*RpcTopUsersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TopUsers not implemented")
+}
+func (UnimplementedQuestionsServer) WatchAnswers(*RpcQuestionRequest, grpc.ServerStreamingServer[RpcAnswer]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAnswers not implemented")
+}
+func (UnimplementedQuestionsServer) mustEmbedUnimplementedQuestionsServer() {}
+func (UnimplementedQuestionsServer) testEmbeddedByValue()                   {}
+
+// UnsafeQuestionsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QuestionsServer will
+// result in compilation errors.
+type UnsafeQuestionsServer interface {
+	mustEmbedUnimplementedQuestionsServer()
+}
+
+func RegisterQuestionsServer(s grpc.ServiceRegistrar, srv QuestionsServer) {
+	// If the following call pancis, it indicates UnimplementedQuestionsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Questions_ServiceDesc, srv)
+}
+
+func _Questions_PostQuestion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RpcPostQuestionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).PostQuestion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Questions_PostQuestion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).PostQuestion(ctx, req.(*RpcPostQuestionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_GetQuestion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RpcQuestionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).GetQuestion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Questions_GetQuestion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).GetQuestion(ctx, req.(*RpcQuestionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_ListQuestions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RpcListQuestionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).ListQuestions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Questions_ListQuestions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).ListQuestions(ctx, req.(*RpcListQuestionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_CloseVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RpcCloseVoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).CloseVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Questions_CloseVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).CloseVote(ctx, req.(*RpcCloseVoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_ReopenVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RpcQuestionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).ReopenVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Questions_ReopenVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).ReopenVote(ctx, req.(*RpcQuestionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_PostAnswer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RpcPostAnswerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).PostAnswer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Questions_PostAnswer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).PostAnswer(ctx, req.(*RpcPostAnswerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_ListAnswers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RpcListAnswersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).ListAnswers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Questions_ListAnswers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).ListAnswers(ctx, req.(*RpcListAnswersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_RateAnswer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RpcRateAnswerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).RateAnswer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Questions_RateAnswer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).RateAnswer(ctx, req.(*RpcRateAnswerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_AcceptAnswer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RpcAnswerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).AcceptAnswer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Questions_AcceptAnswer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).AcceptAnswer(ctx, req.(*RpcAnswerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_TopUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RpcTopUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).TopUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Questions_TopUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).TopUsers(ctx, req.(*RpcTopUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_WatchAnswers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RpcQuestionRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuestionsServer).WatchAnswers(m, &grpc.GenericServerStream[RpcQuestionRequest, RpcAnswer]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Questions_WatchAnswersServer = grpc.ServerStreamingServer[RpcAnswer]
+
+// Questions_ServiceDesc is the grpc.ServiceDesc for Questions service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Questions_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "questions.v1.Questions",
+	HandlerType: (*QuestionsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PostQuestion",
+			Handler:    _Questions_PostQuestion_Handler,
+		},
+		{
+			MethodName: "GetQuestion",
+			Handler:    _Questions_GetQuestion_Handler,
+		},
+		{
+			MethodName: "ListQuestions",
+			Handler:    _Questions_ListQuestions_Handler,
+		},
+		{
+			MethodName: "CloseVote",
+			Handler:    _Questions_CloseVote_Handler,
+		},
+		{
+			MethodName: "ReopenVote",
+			Handler:    _Questions_ReopenVote_Handler,
+		},
+		{
+			MethodName: "PostAnswer",
+			Handler:    _Questions_PostAnswer_Handler,
+		},
+		{
+			MethodName: "ListAnswers",
+			Handler:    _Questions_ListAnswers_Handler,
+		},
+		{
+			MethodName: "RateAnswer",
+			Handler:    _Questions_RateAnswer_Handler,
+		},
+		{
+			MethodName: "AcceptAnswer",
+			Handler:    _Questions_AcceptAnswer_Handler,
+		},
+		{
+			MethodName: "TopUsers",
+			Handler:    _Questions_TopUsers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchAnswers",
+			Handler:       _Questions_WatchAnswers_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "questions.proto",
+}
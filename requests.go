@@ -0,0 +1,287 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+/**
+cursor and page size of a listing, limit defaults to defaultPageSize and is bound by maxPageSize.
+the request bodies below are decoded and validated by decodeRequest
+ */
+type PageRequest struct {
+	Cursor string `json:"cursor"`
+	Limit  *int   `json:"limit" validate:"min=1,max=50"`
+}
+
+func (pr PageRequest) PageParams() (string, int) {
+	if pr.Limit == nil {
+		return pr.Cursor, defaultPageSize
+	}
+
+	return pr.Cursor, *pr.Limit
+}
+
+type CreateUserRequest struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Name     string `json:"name" validate:"required,max=255"`
+	Password string `json:"password" validate:"required,max=255"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type PostQuestionRequest struct {
+	Question string   `json:"question" validate:"required,max=255"`
+	Tags     []string `json:"tags" validate:"max=10,dive,max=64"`
+}
+
+type PostAnswerRequest struct {
+	Answer     string `json:"answer" validate:"required,max=255"`
+	QuestionId int64  `json:"question_id" validate:"required,min=1"`
+}
+
+type QuestionRequest struct {
+	QuestionId int64 `json:"question_id" validate:"required,min=1"`
+}
+
+type AnswerRequest struct {
+	AnswerId int64 `json:"answer_id" validate:"required,min=1"`
+}
+
+type RateAnswerRequest struct {
+	AnswerId int64 `json:"answer_id" validate:"required,min=1"`
+}
+
+/**
+request of the deprecated /answer/rate, the rate stays required for the clients of before,
+its value is ignored and 1 is stored
+ */
+type LegacyRateAnswerRequest struct {
+	RateAnswerRequest
+	Rate *int64 `json:"rate" validate:"required"`
+}
+
+type QuestionsByAnswerRequest struct {
+	PageRequest
+	Order string `json:"order" validate:"oneof=asc desc"`
+}
+
+type QuestionAnswersRequest struct {
+	PageRequest
+	QuestionId int64 `json:"question_id" validate:"required,min=1"`
+}
+
+type DeleteUserRequest struct {
+	Email string `json:"email" validate:"email"`
+}
+
+type UserRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type SearchRequest struct {
+	PageRequest
+	Query  string   `json:"query" validate:"required,max=500"`
+	Type   string   `json:"type" validate:"oneof=question answer"`
+	Tags   []string `json:"tags" validate:"max=10,dive,max=64"`
+	Author string   `json:"author" validate:"email"`
+}
+
+type MarkDuplicateRequest struct {
+	QuestionId  int64  `json:"question_id" validate:"required,min=1"`
+	DuplicateOf *int64 `json:"duplicate_of" validate:"required,min=0"`
+}
+
+type CloseVoteRequest struct {
+	QuestionId  int64  `json:"question_id" validate:"required,min=1"`
+	Reason      string `json:"reason" validate:"required"`
+	DuplicateOf *int64 `json:"duplicate_of" validate:"min=1"`
+}
+
+type ChangeStateRequest struct {
+	QuestionId  int64  `json:"question_id" validate:"required,min=1"`
+	State       string `json:"state" validate:"required,oneof=open closed locked archived"`
+	Reason      string `json:"reason"`
+	DuplicateOf *int64 `json:"duplicate_of" validate:"min=1"`
+}
+
+/**
+topics of an event stream, at least one of them is required
+ */
+type StreamEventsRequest struct {
+	QuestionId    *int64 `json:"question_id" validate:"min=1"`
+	Tag           string `json:"tag" validate:"max=255"`
+	Notifications bool   `json:"notifications"`
+}
+
+type CreateWebhookRequest struct {
+	Url    string   `json:"url" validate:"required,url,max=2048"`
+	Events []string `json:"events" validate:"required,max=10"`
+	Tags   []string `json:"tags" validate:"max=10,dive,max=64"`
+	Secret string   `json:"secret" validate:"min=16,max=64"`
+}
+
+type WebhookRequest struct {
+	WebhookId int64 `json:"webhook_id" validate:"required,min=1"`
+}
+
+type WebhookDeliveriesRequest struct {
+	PageRequest
+	WebhookId int64 `json:"webhook_id" validate:"required,min=1"`
+}
+
+type DeliveryRequest struct {
+	WebhookId  int64 `json:"webhook_id" validate:"required,min=1"`
+	DeliveryId int64 `json:"delivery_id" validate:"required,min=1"`
+}
+
+type ListNotificationsRequest struct {
+	PageRequest
+	Unread bool `json:"unread"`
+}
+
+type MarkNotificationRequest struct {
+	NotificationId int64 `json:"notification_id" validate:"required,min=1"`
+	Read           *bool `json:"read" validate:"required"`
+}
+
+/**
+notification types to turn on or off, the missing ones are left as they are
+ */
+type NotificationPreferencesRequest struct {
+	Answer  *bool `json:"answer"`
+	Vote    *bool `json:"vote"`
+	Accept  *bool `json:"accept"`
+	Mention *bool `json:"mention"`
+}
+
+type TagRequest struct {
+	Tag string `json:"tag" validate:"required,max=64"`
+}
+
+type DigestSettingsRequest struct {
+	Frequency string `json:"frequency" validate:"required,oneof=off daily weekly"`
+}
+
+type UnsubscribeRequest struct {
+	Token string `json:"token" validate:"required,max=255"`
+}
+
+type ListQuestionsRequest struct {
+	PageRequest
+	Sort     string `json:"sort" validate:"oneof=newest activity answers score views"`
+	Order    string `json:"order" validate:"oneof=asc desc"`
+	Tag      string `json:"tag" validate:"max=255"`
+	Author   string `json:"author" validate:"email"`
+	Answered *bool  `json:"answered"`
+	Accepted *bool  `json:"accepted"`
+	From     string `json:"from" validate:"date"`
+	To       string `json:"to" validate:"date"`
+}
+
+/**
+question listing filter of the request, fails by CodeUserNotFound for an unknown author
+ */
+func (req ListQuestionsRequest) Filter() (QuestionFilter, error) {
+	var filter QuestionFilter = QuestionFilter{Sort: QuestionSortNewest, Desc: true}
+
+	if req.Sort != "" {
+		filter.Sort = req.Sort
+	}
+
+	filter.Desc = req.Order != "asc"
+	filter.Tag = strings.ToLower(strings.TrimSpace(req.Tag))
+	filter.Answered = req.Answered
+	filter.HasAccepted = req.Accepted
+
+	if req.Author != "" {
+		var user User
+		if user.LoadByEmail(req.Author) != nil {
+			return filter, ActionError{Code: CodeUserNotFound, Field: "author"}
+		}
+		filter.UserId = user.Id
+	}
+
+	if req.From != "" {
+		filter.From, _ = time.ParseInLocation(dateFormat, req.From, time.Local)
+	}
+
+	if req.To != "" {
+		filter.To, _ = time.ParseInLocation(dateFormat, req.To, time.Local)
+		filter.To = filter.To.AddDate(0, 0, 1)
+	}
+
+	filter.Cursor, filter.Limit = req.PageParams()
+
+	return filter, nil
+}
+
+/**
+search query of the request, fails by CodeUserNotFound for an unknown author
+ */
+func (req SearchRequest) SearchQuery() (SearchQuery, error) {
+	var query SearchQuery = ParseSearchQuery(req.Query)
+
+	query.Kind = req.Type
+	query.Tags = NormalizeTags(req.Tags)
+
+	if req.Author != "" {
+		var user User
+		if user.LoadByEmail(req.Author) != nil {
+			return query, ActionError{Code: CodeUserNotFound, Field: "author"}
+		}
+		query.UserId = user.Id
+	}
+
+	query.Cursor, query.Limit = req.PageParams()
+
+	return query, nil
+}
+
+type LoginResponse struct {
+	Token      string `json:"token"`
+	Expiration string `json:"expiration"`
+}
+
+type CreatedResponse struct {
+	Id int64 `json:"id"`
+}
+
+/**
+created question with the similar questions it may duplicate
+ */
+type PostQuestionResponse struct {
+	Id         int64       `json:"id"`
+	Duplicates []SearchHit `json:"duplicates"`
+}
+
+/**
+registered webhook, the secret is only in the response of the registration
+ */
+type WebhookResponse struct {
+	Id        int64     `json:"id"`
+	Url       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Tags      []string  `json:"tags"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewWebhookResponse(webhook Webhook) WebhookResponse {
+	return WebhookResponse{Id: webhook.Id, Url: webhook.Url, Events: webhook.EventList(), Tags: webhook.TagList(), CreatedAt: webhook.CreatedAt}
+}
+
+/**
+unread notifications, in all and by type
+ */
+type UnreadCountResponse struct {
+	Unread int64            `json:"unread"`
+	ByType map[string]int64 `json:"by_type"`
+}
+
+type DigestSettingsResponse struct {
+	Frequency string `json:"frequency"`
+}
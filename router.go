@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const pathParamsKey contextKey = "path-params"
+
+type route struct {
+	method   string
+	pattern  string
+	segments []string
+	handler  handler
+}
+
+/**
+matches the method and the path of a request against the registered patterns. a segment of
+a pattern in braces ({question_id}) captures that segment of the path, literal segments win
+over captures so /questions/deleted is not taken for /questions/{question_id}
+ */
+type Router struct {
+	routes []route
+}
+
+func NewRouter() *Router {
+	return &Router{}
+}
+
+func (rt *Router) Handle(method string, pattern string, h handler) {
+	rt.routes = append(rt.routes, route{method: method, pattern: pattern, segments: splitPath(pattern), handler: h})
+
+	sort.SliceStable(rt.routes, func(i, j int) bool {
+		return literalSegments(rt.routes[i].segments) > literalSegments(rt.routes[j].segments)
+	})
+}
+
+func (rt *Router) Get(pattern string, h handler) {
+	rt.Handle(http.MethodGet, pattern, h)
+}
+
+func (rt *Router) Post(pattern string, h handler) {
+	rt.Handle(http.MethodPost, pattern, h)
+}
+
+func (rt *Router) Put(pattern string, h handler) {
+	rt.Handle(http.MethodPut, pattern, h)
+}
+
+func (rt *Router) Delete(pattern string, h handler) {
+	rt.Handle(http.MethodDelete, pattern, h)
+}
+
+/**
+routes registered under a path prefix, wrap is applied to every handler of the group
+ */
+type RouteGroup struct {
+	router *Router
+	prefix string
+	wrap   func(pattern string, h handler) handler
+}
+
+func (rt *Router) Group(prefix string, wrap func(pattern string, h handler) handler) *RouteGroup {
+	return &RouteGroup{router: rt, prefix: prefix, wrap: wrap}
+}
+
+func (g *RouteGroup) Handle(method string, pattern string, h handler) {
+	g.router.Handle(method, g.prefix+pattern, g.wrap(pattern, h))
+}
+
+func (g *RouteGroup) Get(pattern string, h handler) {
+	g.Handle(http.MethodGet, pattern, h)
+}
+
+func (g *RouteGroup) Post(pattern string, h handler) {
+	g.Handle(http.MethodPost, pattern, h)
+}
+
+func (g *RouteGroup) Put(pattern string, h handler) {
+	g.Handle(http.MethodPut, pattern, h)
+}
+
+func (g *RouteGroup) Delete(pattern string, h handler) {
+	g.Handle(http.MethodDelete, pattern, h)
+}
+
+/**
+serves the request by the first matching route, 405 with the Allow header when the path
+is known but not for the method and 404 when the path is not known at all
+ */
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var allowed []string
+	var path []string = splitPath(r.URL.Path)
+
+	for _, rte := range rt.routes {
+		params, ok := matchPath(rte.segments, path)
+		if !ok {
+			continue
+		}
+
+		if rte.method != r.Method {
+			if !containsString(allowed, rte.method) {
+				allowed = append(allowed, rte.method)
+			}
+			continue
+		}
+
+		rte.handler(w, r.WithContext(context.WithValue(r.Context(), pathParamsKey, params)))
+		return
+	}
+
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeError(w, r, CodeMethodNotAllowed)
+		return
+	}
+
+	writeError(w, r, CodeRouteNotFound)
+}
+
+/**
+path parameters of the matched route by name
+ */
+func PathParams(r *http.Request) map[string]string {
+	params, _ := r.Context().Value(pathParamsKey).(map[string]string)
+	return params
+}
+
+func splitPath(path string) []string {
+	var segments []string
+
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	return segments
+}
+
+func matchPath(pattern []string, path []string) (map[string]string, bool) {
+	var params map[string]string = make(map[string]string)
+
+	if len(pattern) != len(path) {
+		return nil, false
+	}
+
+	for i, segment := range pattern {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params[segment[1:len(segment)-1]] = path[i]
+		} else if segment != path[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+func literalSegments(segments []string) int {
+	var count int
+
+	for _, segment := range segments {
+		if !strings.HasPrefix(segment, "{") {
+			count++
+		}
+	}
+
+	return count
+}
@@ -2,8 +2,9 @@ package main
 
 import (
 	"net/http"
-	"encoding/json"
 	"database/sql"
+	"log"
+	"strings"
 )
 
 /**
@@ -11,37 +12,32 @@ create user by eamil, password and name mandatory fields.
 also check if the email is in the database already
  */
 func CreateUser(w http.ResponseWriter, r *http.Request) {
-	jsonData, err := getJsonData(r)
-
+	var req CreateUserRequest
+	err := decodeRequest(w, r, &req)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+		writeRequestError(w, r, err)
 		return
 	}
 
 	var user User
-	email, eok := jsonData.(map[string]interface{})["email"]
-	name, nok := jsonData.(map[string]interface{})["name"]
-	password, pok := jsonData.(map[string]interface{})["password"]
-
-	if !eok || !nok || !pok {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
-		return
-	}
-	err = user.LoadByEmail(email.(string))
+	err = user.LoadByEmail(req.Email)
 
 	if err == nil {
-		http.Error(w, getErrorByStatusCode(http.StatusConflict), http.StatusConflict)
+		writeError(w, r, CodeEmailTaken)
 		return
 	} else if err != nil && err != sql.ErrNoRows {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, r, CodeInternal)
 		return
 	}
 
-	user = NewUser(name.(string), email.(string), password.(string))
+	user = NewUser(req.Name, req.Email, req.Password)
 	err = user.Save()
 
-	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+	if err == ErrConflict {
+		writeError(w, r, CodeEmailTaken)
+		return
+	} else if err != nil {
+		writeError(w, r, CodeInternal)
 		return
 	}
 
@@ -52,291 +48,1482 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 login user by email and password, returns access token must be sent in header in the following
  */
 func LoginUser(w http.ResponseWriter, r *http.Request) {
-	jsonData, err := getJsonData(r)
+	var req LoginRequest
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	var user User
+	err = user.LoadByEmailPass(req.Email, req.Password)
+
+	if err != nil {
+		writeError(w, r, CodeInvalidCredentials)
+		return
+	}
+
+	err = user.GenerateAccessToken()
 
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+		writeError(w, r, CodeInternal)
 		return
 	}
 
+	jsonResponse(w, r, LoginResponse{Token: user.AccessToken, Expiration: user.TokenExpiration.Format("2006-01-02 15:04:05")})
+}
+
+/**
+returns top 5 users by answer count to authenticated user request
+ */
+func UsersTopFive(w http.ResponseWriter, r *http.Request) {
+	var topUsers []User
 	var user User
-	email, eok := jsonData.(map[string]interface{})["email"]
-	password, pok := jsonData.(map[string]interface{})["password"]
 
-	if !eok ||  !pok {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+	topUsers,err := user.GetTopUsers(5)
+
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, topUsers)
+}
+
+/**
+save question to db to authenticated user request
+ */
+func PostQuestion(w http.ResponseWriter, r *http.Request) {
+	var req PostQuestionRequest
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
 		return
 	}
-	err = user.LoadByEmailPass(email.(string),password.(string))
 
+	question, duplicates, err := PostQuestionAs(user, req.Question, req.Tags)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+		writeActionError(w, r, err)
 		return
 	}
 
-	err = user.GenerateAccessToken()
+	jsonResponse(w, r, PostQuestionResponse{Id: question.Id, Duplicates: duplicates})
+}
+/**
+ post answer to question to authenticated user request
+ */
+func PostAnswer(w http.ResponseWriter, r *http.Request) {
+	var req PostAnswerRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
 
+	err := decodeRequest(w, r, &req)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeRequestError(w, r, err)
 		return
 	}
 
-	var mapResponse map[string]interface{} = make(map[string]interface{})
-	var byteResponse []byte
-	mapResponse["token"] = user.AccessToken
-	mapResponse["expiration"] = user.TokenExpiration.Format("2006-01-02 15:04:05")
-	byteResponse, err = json.Marshal(mapResponse)
+	answer, err := PostAnswerAs(user, req.QuestionId, req.Answer)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeActionError(w, r, err)
+		return
+	}
+
+	jsonResponse(w, r, CreatedResponse{Id: answer.Id})
+}
+/**
+ list questions ordered by answer count to authenticated user request
+ */
+func QuestionsByAnswer(w http.ResponseWriter, r *http.Request) {
+	var question Question
+	var req QuestionsByAnswerRequest
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	cursor, limit := req.PageParams()
+
+	questions, info, err := question.GetByAnswersCount(cursor, limit, req.Order != "asc")
+
+	if err == ErrInvalidCursor {
+		writeFieldError(w, r, CodeInvalidCursor, "cursor")
+		return
+	} else if err != nil {
+		writeError(w, r, CodeInternal)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	w.Write(byteResponse)
 
+	jsonResponse(w, r, NewPage(questions, info))
 }
 
 /**
-returns top 5 users by answer count to authenticated user request
+ list questions ordered by answers rate to authenticated user request
  */
-func UsersTopFive(w http.ResponseWriter, r *http.Request) {
-	var topUsers []User
-	var user User
+func QuestionAnswersByRate(w http.ResponseWriter, r *http.Request) {
+	var question Question
+	var req QuestionAnswersRequest
 
-	_,err := getAuthUser(r)
+	err := decodeRequest(w, r, &req)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeRequestError(w, r, err)
+		return
+	}
+
+	if question.Load(req.QuestionId) != nil {
+		writeFieldError(w, r, CodeQuestionNotFound, "question_id")
+		return
+	}
+
+	cursor, limit := req.PageParams()
+
+	if cursor == "" {
+		question.AddView()
+	}
+
+	answers, info, err := question.GetAnswersByRate(cursor, limit, true)
+
+	if err == ErrInvalidCursor {
+		writeFieldError(w, r, CodeInvalidCursor, "cursor")
+		return
+	} else if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, NewPage(answers, info))
+}
+/**
+ rate answer to authenticated user request
+ */
+func RateAnswer(w http.ResponseWriter, r *http.Request) {
+	var req RateAnswerRequest
+	rateAnswer(w, r, &req, &req.AnswerId)
+}
+
+/**
+ rate answer to authenticated user request of the deprecated /answer/rate, which still
+ requires the ignored rate
+ */
+func LegacyRateAnswer(w http.ResponseWriter, r *http.Request) {
+	var req LegacyRateAnswerRequest
+	rateAnswer(w, r, &req, &req.AnswerId)
+}
+
+/**
+decodes the request into req and rates the answer of answerId, a field of req
+ */
+func rateAnswer(w http.ResponseWriter, r *http.Request, req interface{}, answerId *int64) {
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
 		return
 	}
 
-	topUsers,err = user.GetTopUsers(5)
+	err := decodeRequest(w, r, req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
 
+	_, err = RateAnswerAs(user, *answerId)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeActionError(w, r, err)
 		return
 	}
 
-	jsonResponse(w,topUsers)
+	w.WriteHeader(http.StatusOK)
 }
 
 /**
-save question to db to authenticated user request
+ soft delete question, allowed to its author and to moderators
  */
-func PostQuestion(w http.ResponseWriter, r *http.Request) {
-	var user User
-	var qstring interface{}
+func DeleteQuestion(w http.ResponseWriter, r *http.Request) {
 	var question Question
+	var user User
 
-	jsonData, err := getJsonData(r)
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
 
+	var req QuestionRequest
+	err := decodeRequest(w, r, &req)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+		writeRequestError(w, r, err)
 		return
 	}
 
-	user,err = getAuthUser(r)
-	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+	if question.Load(req.QuestionId) != nil {
+		writeFieldError(w, r, CodeQuestionNotFound, "question_id")
 		return
 	}
 
-	qstring, qok := jsonData.(map[string]interface{})["question"]
-	if !qok {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+	if question.UserId != user.Id && !user.Moderator {
+		writeError(w, r, CodeNotOwner)
 		return
 	}
 
-	question = NewQuestion(qstring.(string),user)
-	err = question.Save()
+	err = question.Delete(user)
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+/**
+ restore soft deleted question to moderator request
+ */
+func UndeleteQuestion(w http.ResponseWriter, r *http.Request) {
+	var question Question
 
+	var req QuestionRequest
+	err := decodeRequest(w, r, &req)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeRequestError(w, r, err)
+		return
+	}
+
+	if question.LoadWithDeleted(req.QuestionId) != nil {
+		writeFieldError(w, r, CodeQuestionNotFound, "question_id")
 		return
 	}
 
-	var response map[string]interface{} = make(map[string]interface{})
-	response["id"] = question.Id
+	err = question.Undelete()
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
 
-	jsonResponse(w,response)
+	w.WriteHeader(http.StatusOK)
 }
+
 /**
- post answer to question to authenticated user request
+ list soft deleted questions, latest deletion first, to moderator request
  */
-func PostAnswer(w http.ResponseWriter, r *http.Request) {
-	var astring interface{}
-	var qid interface{}
-
+func DeletedQuestions(w http.ResponseWriter, r *http.Request) {
 	var question Question
+
+	var req PageRequest
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	cursor, limit := req.PageParams()
+
+	questions, info, err := question.GetDeleted(cursor, limit)
+
+	if err == ErrInvalidCursor {
+		writeFieldError(w, r, CodeInvalidCursor, "cursor")
+		return
+	} else if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, NewPage(questions, info))
+}
+
+/**
+ soft delete answer, allowed to its author and to moderators
+ */
+func DeleteAnswer(w http.ResponseWriter, r *http.Request) {
 	var answer Answer
 	var user User
 
-	user,err := getAuthUser(r)
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	var req AnswerRequest
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	if answer.Load(req.AnswerId) != nil {
+		writeFieldError(w, r, CodeAnswerNotFound, "answer_id")
+		return
+	}
+
+	if answer.UserId != user.Id && !user.Moderator {
+		writeError(w, r, CodeNotOwner)
+		return
+	}
+
+	err = answer.Delete(user)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, r, CodeInternal)
 		return
 	}
 
-	jsonData, err := getJsonData(r)
+	w.WriteHeader(http.StatusOK)
+}
+
+/**
+ restore soft deleted answer to moderator request
+ */
+func UndeleteAnswer(w http.ResponseWriter, r *http.Request) {
+	var answer Answer
+
+	var req AnswerRequest
+	err := decodeRequest(w, r, &req)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+		writeRequestError(w, r, err)
 		return
 	}
 
-	astring, aok := jsonData.(map[string]interface{})["answer"]
-	if !aok {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+	if answer.LoadWithDeleted(req.AnswerId) != nil {
+		writeFieldError(w, r, CodeAnswerNotFound, "answer_id")
 		return
 	}
 
-	qid,qok := jsonData.(map[string]interface{})["question_id"]
-	if !qok || question.Load(int64(qid.(float64))) != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+	err = answer.Undelete()
+	if err != nil {
+		writeError(w, r, CodeInternal)
 		return
 	}
 
-	answer = NewAnswer(astring.(string),user,question)
-	err = answer.Save()
+	w.WriteHeader(http.StatusOK)
+}
+
+/**
+ list soft deleted answers, latest deletion first, to moderator request
+ */
+func DeletedAnswers(w http.ResponseWriter, r *http.Request) {
+	var answer Answer
 
+	var req PageRequest
+	err := decodeRequest(w, r, &req)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeRequestError(w, r, err)
 		return
 	}
 
-	var response map[string]interface{} = make(map[string]interface{})
-	response["id"] = answer.Id
+	cursor, limit := req.PageParams()
+
+	answers, info, err := answer.GetDeleted(cursor, limit)
 
-	jsonResponse(w,response)
+	if err == ErrInvalidCursor {
+		writeFieldError(w, r, CodeInvalidCursor, "cursor")
+		return
+	} else if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, NewPage(answers, info))
 }
+
 /**
- list questions ordered by answer count to authenticated user request
+ soft delete user. without email the authenticated user deletes itself,
+ moderators can delete any user by email
  */
-func QuestionsByAnswer(w http.ResponseWriter, r *http.Request) {
-	var pageNum interface {}
-	var page int = 1
+func DeleteUser(w http.ResponseWriter, r *http.Request) {
+	var target User
+	var user User
+	var req DeleteUserRequest
 
-	var questions []Question
-	var question Question
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
 
-	_,err := getAuthUser(r)
+	err := decodeRequest(w, r, &req)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeRequestError(w, r, err)
+		return
+	}
+
+	if req.Email == "" || req.Email == user.Email {
+		target = user
+	} else if !user.Moderator {
+		writeError(w, r, CodeModeratorRequired)
+		return
+	} else if target.LoadByEmail(req.Email) != nil || target.DeletedAt != nil {
+		writeFieldError(w, r, CodeUserNotFound, "email")
 		return
 	}
 
-	jsonData, err := getJsonData(r)
+	err = target.Delete(user)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+		writeError(w, r, CodeInternal)
 		return
 	}
 
-	pageNum,pok := jsonData.(map[string]interface{})["page"]
-	if pok && int64(pageNum.(float64))>1{
-		page = int(pageNum.(float64))
+	w.WriteHeader(http.StatusOK)
+}
+
+/**
+ restore soft deleted user by email to moderator request
+ */
+func UndeleteUser(w http.ResponseWriter, r *http.Request) {
+	var target User
+	var req UserRequest
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
 	}
 
-	questions,err = question.GetByAnswersCount(page,5,true)
+	if target.LoadByEmail(req.Email) != nil {
+		writeFieldError(w, r, CodeUserNotFound, "email")
+		return
+	}
 
+	err = target.Undelete()
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, r, CodeInternal)
 		return
 	}
 
-	jsonResponse(w,questions)
+	w.WriteHeader(http.StatusOK)
 }
 
 /**
- list questions ordered by answers rate to authenticated user request
+ list soft deleted users, latest deletion first, to moderator request
  */
-func QuestionAnswersByRate(w http.ResponseWriter, r *http.Request) {
-	var qid interface{}
-	var pageNum interface {}
-	var page int = 1
+func DeletedUsers(w http.ResponseWriter, r *http.Request) {
+	var user User
 
-	var question Question
-	var answers []Answer
+	var req PageRequest
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	cursor, limit := req.PageParams()
+
+	users, info, err := user.GetDeleted(cursor, limit)
+
+	if err == ErrInvalidCursor {
+		writeFieldError(w, r, CodeInvalidCursor, "cursor")
+		return
+	} else if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, NewPage(users, info))
+}
+
+/**
+ full-text search over questions and answers to authenticated user request.
+ quoted parts of the query are matched as phrases, hits can be filtered by
+ type (question or answer), by tags of the question and by author email
+ */
+func SearchPosts(w http.ResponseWriter, r *http.Request) {
+	var req SearchRequest
 
-	_,err := getAuthUser(r)
+	err := decodeRequest(w, r, &req)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeRequestError(w, r, err)
 		return
 	}
 
-	jsonData, err := getJsonData(r)
+	query, err := req.SearchQuery()
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+		writeActionError(w, r, err)
 		return
 	}
 
-	qid, qok := jsonData.(map[string]interface{})["question_id"]
-	if !qok || question.Load(int64(qid.(float64))) != nil{
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+	hits, info, err := SearchIdx.Search(query)
+	if err == ErrInvalidCursor {
+		writeFieldError(w, r, CodeInvalidCursor, "cursor")
+		return
+	} else if err != nil {
+		writeError(w, r, CodeInternal)
 		return
 	}
 
-	pageNum,pok := jsonData.(map[string]interface{})["page"]
-	if pok && int64(pageNum.(float64))>1{
-		page = int(pageNum.(float64))
+	results, err := LoadSearchHits(hits)
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
 	}
 
-	answers,err = question.GetAnswersByRate(page,5,true)
+	jsonResponse(w, r, NewPage(results, info))
+}
+
+/**
+ rebuild the search index from the database to moderator request
+ */
+func ReindexSearch(w http.ResponseWriter, r *http.Request) {
+	err := RebuildSearchIndex()
 
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, r, CodeInternal)
 		return
 	}
 
-	jsonResponse(w,answers)
+	w.WriteHeader(http.StatusOK)
 }
+
 /**
- rate answer to authenticated user request
+ hit and miss counters of the cache to moderator request
  */
-func RateAnswer(w http.ResponseWriter, r *http.Request) {
-	var aid interface{}
+func CacheStatistics(w http.ResponseWriter, r *http.Request) {
+	jsonResponse(w, r, AppCache.Stats())
+}
 
-	var answer Answer
-	var answerRate AnswerRate
-	var user User
+/**
+ list questions similar to the given one to authenticated user request
+ */
+func SimilarQuestions(w http.ResponseWriter, r *http.Request) {
+	var question Question
 
-	user,err := getAuthUser(r)
+	var req QuestionRequest
+	err := decodeRequest(w, r, &req)
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeRequestError(w, r, err)
 		return
 	}
 
-	jsonData, err := getJsonData(r)
-	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+	if question.Load(req.QuestionId) != nil {
+		writeFieldError(w, r, CodeQuestionNotFound, "question_id")
 		return
 	}
 
-	aid, aok := jsonData.(map[string]interface{})["answer_id"]
-	if !aok || answer.Load(int64(aid.(float64))) != nil{
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+	hits, err := SearchIdx.SimilarQuestions(question.Question, question.Id, similarThreshold, 5)
+	if err != nil {
+		writeError(w, r, CodeInternal)
 		return
 	}
 
-	_,rok := jsonData.(map[string]interface{})["rate"]
-	if !rok {
-		http.Error(w, getErrorByStatusCode(http.StatusExpectationFailed), http.StatusExpectationFailed)
+	results, err := LoadSearchHits(hits)
+	if err != nil {
+		writeError(w, r, CodeInternal)
 		return
 	}
 
-	err = answerRate.LoadByAnswerAndUser(answer,user)
+	jsonResponse(w, r, results)
+}
 
-	if err == nil {
-		http.Error(w, getErrorByStatusCode(http.StatusConflict), http.StatusConflict)
+/**
+ close question as the duplicate of a canonical question to moderator request,
+ duplicate_of 0 reopens the question
+ */
+func MarkDuplicateQuestion(w http.ResponseWriter, r *http.Request) {
+	var question Question
+	var canonical Question
+	var user User
+	var req MarkDuplicateRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
 		return
-	} else if err != sql.ErrNoRows{
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+
+	if question.Load(req.QuestionId) != nil {
+		writeFieldError(w, r, CodeQuestionNotFound, "question_id")
 		return
 	}
 
-	answerRate = NewAnswerRate(1,user,answer)
-	err = answerRate.Save()
+	if *req.DuplicateOf == 0 {
+		err = question.Transition(QuestionOpen, "", user)
+	} else if canonical.Load(*req.DuplicateOf) != nil || canonical.Id == question.Id || canonical.DuplicateOfId == question.Id {
+		writeFieldError(w, r, CodeInvalidDuplicate, "duplicate_of")
+		return
+	} else {
+		err = question.MarkDuplicateOf(canonical, user)
+	}
 
 	if err != nil {
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeQuestionStateError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+/**
+ vote to close an open question to authenticated user request, the question gets closed
+ with the most voted reason when enough votes are collected
+ */
+func CloseVoteQuestion(w http.ResponseWriter, r *http.Request) {
+	var req CloseVoteRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	question, err := CloseVoteAs(user, req.QuestionId, req.Reason, req.DuplicateOf)
+	if err != nil {
+		writeActionError(w, r, err)
+		return
+	}
+
+	jsonResponse(w, r, question)
+}
+
+/**
+ vote to reopen a closed question to authenticated user request, the question gets
+ reopened when enough votes are collected
+ */
+func ReopenVoteQuestion(w http.ResponseWriter, r *http.Request) {
+	var req QuestionRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	question, err := ReopenVoteAs(user, req.QuestionId)
+	if err != nil {
+		writeActionError(w, r, err)
+		return
+	}
+
+	jsonResponse(w, r, question)
+}
+
+/**
+ set the state of a question (open, closed, locked or archived) to moderator request,
+ overriding the community votes. closing needs a reason, duplicate closing the canonical question
+ */
+func ChangeQuestionState(w http.ResponseWriter, r *http.Request) {
+	var question Question
+	var user User
+	var req ChangeStateRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	if question.Load(req.QuestionId) != nil {
+		writeFieldError(w, r, CodeQuestionNotFound, "question_id")
+		return
+	}
+
+	if req.DuplicateOf != nil {
+		var canonical Question
+		if canonical.Load(*req.DuplicateOf) != nil || canonical.Id == question.Id || canonical.DuplicateOfId == question.Id {
+			writeFieldError(w, r, CodeInvalidDuplicate, "duplicate_of")
+			return
+		}
+		question.DuplicateOfId = canonical.Id
+		if canonical.DuplicateOfId != 0 {
+			question.DuplicateOfId = canonical.DuplicateOfId
+		}
+	}
+
+	err = question.Transition(req.State, req.Reason, user)
+	if err != nil {
+		writeQuestionStateError(w, r, err)
+		return
+	}
+
+	jsonResponse(w, r, question)
+}
+
+/**
+ list questions to authenticated user request. sort can be newest, activity, answers, score
+ or views (order asc or desc), the list can be filtered by tag, author email, answered,
+ accepted (has accepted answer) and creation date range (from, to as 2006-01-02, both inclusive).
+ the response carries next and prev cursors, limit sets the page size up to maxPageSize
+ */
+func ListQuestions(w http.ResponseWriter, r *http.Request) {
+	var question Question
+	var req ListQuestionsRequest
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	filter, err := req.Filter()
+	if err != nil {
+		writeActionError(w, r, err)
+		return
+	}
+
+	questions, info, err := question.GetList(filter)
+
+	if err == ErrInvalidCursor {
+		writeFieldError(w, r, CodeInvalidCursor, "cursor")
+		return
+	} else if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, NewPage(questions, info))
+}
+
+/**
+ accept an answer of own question to authenticated user request
+ */
+func AcceptAnswer(w http.ResponseWriter, r *http.Request) {
+	var req AnswerRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	_, err = AcceptAnswerAs(user, req.AnswerId)
+	if err != nil {
+		writeActionError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+/**
+ register a webhook of the authenticated user for the given events, optionally only on
+ questions in the given tags. the response carries the secret the payloads are signed with
+ */
+func CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req CreateWebhookRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	for _, event := range req.Events {
+		if !containsString(webhookEvents, event) {
+			writeRequestError(w, r, ValidationErrors{{Field: "events", Rule: "oneof", Message: "must be one of " + strings.Join(webhookEvents, ", ")}})
+			return
+		}
+	}
+
+	err = CheckWebhookURL(r.Context(), req.Url)
+	if err != nil {
+		writeRequestError(w, r, ValidationErrors{{Field: "url", Rule: "public", Message: "must resolve to public addresses only"}})
+		return
+	}
+
+	var webhook Webhook = NewWebhook(user, req.Url, req.Events, req.Tags, req.Secret)
+	err = webhook.Save()
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	var resp WebhookResponse = NewWebhookResponse(webhook)
+	resp.Secret = webhook.Secret
+
+	jsonResponse(w, r, resp)
+}
+
+/**
+ list the webhooks of the authenticated user
+ */
+func ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	var webhook Webhook
+	var resp []WebhookResponse = []WebhookResponse{}
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	webhooks, err := webhook.GetByUser(user)
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		resp = append(resp, NewWebhookResponse(webhook))
+	}
+
+	jsonResponse(w, r, resp)
+}
+
+/**
+ delete a webhook with its delivery log, allowed to its owner and to moderators
+ */
+func DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	var req WebhookRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	webhook, ok := loadOwnWebhook(w, r, user, req.WebhookId)
+	if !ok {
+		return
+	}
+
+	err = webhook.Delete()
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+/**
+ list the deliveries of a webhook, latest first, with their status, response code and attempts
+ */
+func WebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	var delivery WebhookDelivery
+	var req WebhookDeliveriesRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	webhook, ok := loadOwnWebhook(w, r, user, req.WebhookId)
+	if !ok {
+		return
+	}
+
+	cursor, limit := req.PageParams()
+	deliveries, info, err := delivery.GetByWebhook(webhook, cursor, limit)
+
+	if err == ErrInvalidCursor {
+		writeFieldError(w, r, CodeInvalidCursor, "cursor")
+		return
+	} else if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, NewPage(deliveries, info))
+}
+
+/**
+ deliver the payload of a logged delivery again, for example after a failed one. the response is
+ the new delivery after its first attempt
+ */
+func ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	var delivery WebhookDelivery
+	var req DeliveryRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	webhook, ok := loadOwnWebhook(w, r, user, req.WebhookId)
+	if !ok {
+		return
+	}
+
+	if delivery.Load(req.DeliveryId) != nil || delivery.WebhookId != webhook.Id {
+		writeFieldError(w, r, CodeDeliveryNotFound, "delivery_id")
+		return
+	}
+
+	replay, err := ReplayDelivery(webhook, delivery)
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, replay)
+}
+
+/**
+ loads the webhook for its owner or a moderator, writes the error response and returns not ok otherwise
+ */
+func loadOwnWebhook(w http.ResponseWriter, r *http.Request, user User, webhookId int64) (Webhook, bool) {
+	var webhook Webhook
+
+	if webhook.Load(webhookId) != nil {
+		writeFieldError(w, r, CodeWebhookNotFound, "webhook_id")
+		return webhook, false
+	}
+
+	if webhook.UserId != user.Id && !user.Moderator {
+		writeError(w, r, CodeNotOwner)
+		return webhook, false
+	}
+
+	return webhook, true
+}
+
+/**
+ list the notifications of the authenticated user, latest first, unread=true lists only the unread ones
+ */
+func ListNotifications(w http.ResponseWriter, r *http.Request) {
+	var notification Notification
+	var req ListNotificationsRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	cursor, limit := req.PageParams()
+	notifications, info, err := notification.GetByUser(user, req.Unread, cursor, limit)
+
+	if err == ErrInvalidCursor {
+		writeFieldError(w, r, CodeInvalidCursor, "cursor")
+		return
+	} else if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, NewPage(notifications, info))
+}
+
+/**
+ unread notification counts of the authenticated user
+ */
+func UnreadNotifications(w http.ResponseWriter, r *http.Request) {
+	var resp UnreadCountResponse
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	counts, err := UnreadNotificationCounts(user)
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	resp.ByType = counts
+	for _, count := range counts {
+		resp.Unread += count
+	}
+
+	jsonResponse(w, r, resp)
+}
+
+/**
+ mark every notification of the authenticated user read
+ */
+func MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	_, err := MarkNotificationsRead(user)
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+/**
+ mark a notification of the authenticated user read or unread
+ */
+func MarkNotification(w http.ResponseWriter, r *http.Request) {
+	var notification Notification
+	var req MarkNotificationRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	if notification.Load(req.NotificationId) != nil || notification.UserId != user.Id {
+		writeFieldError(w, r, CodeNotificationNotFound, "notification_id")
+		return
+	}
+
+	err = notification.MarkRead(*req.Read)
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, notification)
+}
+
+/**
+ notification types of the authenticated user with whether they are on
+ */
+func GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	preferences, err := NotificationPreferences(user)
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, preferences)
+}
+
+/**
+ turn notification types of the authenticated user on or off, responds with all the types
+ */
+func SetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	var req NotificationPreferencesRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	var changes map[string]*bool = map[string]*bool{NotificationAnswer: req.Answer, NotificationVote: req.Vote, NotificationAccept: req.Accept, NotificationMention: req.Mention}
+	for notificationType, enabled := range changes {
+		if enabled == nil {
+			continue
+		}
+		err = SetNotificationPreference(user, notificationType, *enabled)
+		if err != nil {
+			writeError(w, r, CodeInternal)
+			return
+		}
+	}
+
+	preferences, err := NotificationPreferences(user)
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, preferences)
+}
+
+/**
+ follow a question to authenticated user request, its answers show up in the feed
+ */
+func FollowQuestion(w http.ResponseWriter, r *http.Request) {
+	follow, ok := questionFollow(w, r)
+	if ok {
+		saveFollow(w, r, follow)
+	}
+}
+
+func UnfollowQuestion(w http.ResponseWriter, r *http.Request) {
+	follow, ok := questionFollow(w, r)
+	if ok {
+		deleteFollow(w, r, follow)
+	}
+}
+
+/**
+ follow a tag to authenticated user request, the questions in it and their answers show up in the feed
+ */
+func FollowTag(w http.ResponseWriter, r *http.Request) {
+	follow, ok := tagFollow(w, r)
+	if ok {
+		saveFollow(w, r, follow)
+	}
+}
+
+func UnfollowTag(w http.ResponseWriter, r *http.Request) {
+	follow, ok := tagFollow(w, r)
+	if ok {
+		deleteFollow(w, r, follow)
+	}
+}
+
+/**
+ follow a user by email to authenticated user request, its questions and answers show up in the feed
+ */
+func FollowUser(w http.ResponseWriter, r *http.Request) {
+	follow, ok := userFollow(w, r)
+	if ok {
+		saveFollow(w, r, follow)
+	}
+}
+
+func UnfollowUser(w http.ResponseWriter, r *http.Request) {
+	follow, ok := userFollow(w, r)
+	if ok {
+		deleteFollow(w, r, follow)
+	}
+}
+
+/**
+ follow of the authenticated user on the question of the request, writes the error response when there is none
+ */
+func questionFollow(w http.ResponseWriter, r *http.Request) (Follow, bool) {
+	var question Question
+	var req QuestionRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return Follow{}, false
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return Follow{}, false
+	}
+
+	if question.Load(req.QuestionId) != nil {
+		writeFieldError(w, r, CodeQuestionNotFound, "question_id")
+		return Follow{}, false
+	}
+
+	return NewQuestionFollow(user, question), true
+}
+
+func tagFollow(w http.ResponseWriter, r *http.Request) (Follow, bool) {
+	var req TagRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return Follow{}, false
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return Follow{}, false
+	}
+
+	tags := NormalizeTags([]string{req.Tag})
+	if len(tags) == 0 {
+		writeRequestError(w, r, ValidationErrors{{Field: "tag", Rule: "required", Message: "tag is required"}})
+		return Follow{}, false
+	}
+
+	return NewTagFollow(user, tags[0]), true
+}
+
+func userFollow(w http.ResponseWriter, r *http.Request) (Follow, bool) {
+	var followee User
+	var req UserRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return Follow{}, false
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return Follow{}, false
+	}
+
+	if followee.LoadByEmail(req.Email) != nil || followee.DeletedAt != nil {
+		writeFieldError(w, r, CodeUserNotFound, "email")
+		return Follow{}, false
+	}
+
+	if followee.Id == user.Id {
+		writeRequestError(w, r, ValidationErrors{{Field: "email", Rule: "other", Message: "email must not be the own one"}})
+		return Follow{}, false
+	}
+
+	return NewUserFollow(user, followee), true
+}
+
+func saveFollow(w http.ResponseWriter, r *http.Request, follow Follow) {
+	err := follow.Save()
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, follow)
+}
+
+func deleteFollow(w http.ResponseWriter, r *http.Request, follow Follow) {
+	err := follow.Delete()
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+/**
+ list the questions, tags and users the authenticated user follows, latest first
+ */
+func ListFollows(w http.ResponseWriter, r *http.Request) {
+	var follow Follow
+	var req PageRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	cursor, limit := req.PageParams()
+	follows, info, err := follow.GetByUser(user, cursor, limit)
+
+	if err == ErrInvalidCursor {
+		writeFieldError(w, r, CodeInvalidCursor, "cursor")
+		return
+	} else if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, NewPage(follows, info))
+}
+
+/**
+ new questions and answers of everything the authenticated user follows, latest first
+ */
+func PersonalFeed(w http.ResponseWriter, r *http.Request) {
+	var req PageRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	cursor, limit := req.PageParams()
+	items, info, err := Feed(user, cursor, limit)
+
+	if err == ErrInvalidCursor {
+		writeFieldError(w, r, CodeInvalidCursor, "cursor")
+		return
+	} else if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, NewPage(items, info))
+}
+
+/**
+ how often the authenticated user gets the email digest
+ */
+func GetDigestSettings(w http.ResponseWriter, r *http.Request) {
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	frequency, err := DigestFrequency(user.Id)
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, DigestSettingsResponse{Frequency: frequency})
+}
+
+/**
+ set how often the authenticated user gets the email digest, daily, weekly or off
+ */
+func SetDigestSettings(w http.ResponseWriter, r *http.Request) {
+	var req DigestSettingsRequest
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	err = SetDigestFrequency(user.Id, req.Frequency)
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	jsonResponse(w, r, DigestSettingsResponse{Frequency: req.Frequency})
+}
+
+/**
+ confirmation page of the unsubscribe link of a digest, without login. nothing is changed until
+ the confirmation is posted, so link scanners of the mail servers do not unsubscribe anybody
+ */
+func ConfirmUnsubscribeDigests(w http.ResponseWriter, r *http.Request) {
+	_, ok := unsubscribeUserId(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := unsubscribePage.Execute(w, unsubscribePageData{Action: r.URL.RequestURI()})
+	if err != nil {
+		log.Println("rendering the unsubscribe page failed:", err)
+	}
+}
+
+/**
+ turn the email digest off by the token of the unsubscribe link of a digest, without login.
+ the token is read from the query only: the one-click unsubscribe of the mail clients and the
+ confirmation page post the link with a form body. a browser gets the page telling it is done
+ */
+func UnsubscribeDigests(w http.ResponseWriter, r *http.Request) {
+	userId, ok := unsubscribeUserId(w, r)
+	if !ok {
+		return
+	}
+
+	err := SetDigestFrequency(userId, DigestOff)
+	if err != nil {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = unsubscribePage.Execute(w, unsubscribePageData{Done: true})
+		if err != nil {
+			log.Println("rendering the unsubscribe page failed:", err)
+		}
+		return
+	}
+
+	jsonResponse(w, r, DigestSettingsResponse{Frequency: DigestOff})
+}
+
+/**
+user of the token in the query, writes the error and returns false when the token is missing or invalid
+ */
+func unsubscribeUserId(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	var token string = r.URL.Query().Get("token")
+
+	if token == "" {
+		writeRequestError(w, r, ValidationErrors{{Field: "token", Rule: "required", Message: "token is required"}})
+		return 0, false
+	}
+
+	userId, err := ParseUnsubscribeToken(token)
+	if err != nil {
+		writeFieldError(w, r, CodeInvalidUnsubscribe, "token")
+		return 0, false
+	}
+
+	return userId, true
+}
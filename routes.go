@@ -0,0 +1,117 @@
+package main
+
+/**
+routes of the api: the resources in every version group and, for the migration, the unversioned
+resource paths and the old verb style paths as deprecated aliases of them, the
+specification of the versioned routes at /openapi.json, the graphql endpoint and the event stream
+ */
+func NewAPIRouter() *Router {
+	var router *Router = NewRouter()
+
+	for _, version := range apiVersions {
+		registerResources(router.Group("/"+version.Name, WithVersion(version)))
+	}
+
+	registerResources(router.Group("", DeprecatedUnversioned))
+
+	registerLegacyRoutes(router)
+
+	router.Get("/openapi.json", ServeOpenAPI(router))
+	router.Post("/graphql", AuthUser(ServeGraphQL))
+	router.Get("/events", AuthUser(StreamEvents))
+
+	return router
+}
+
+func registerResources(router *RouteGroup) {
+	router.Post("/users", CreateUser)
+	router.Post("/sessions", LoginUser)
+	router.Get("/users/top", AuthUser(UsersTopFive))
+	router.Get("/users/deleted", AuthUser(ModeratorOnly(DeletedUsers)))
+	router.Delete("/users/{email}", AuthUser(DeleteUser))
+	router.Post("/users/{email}/restore", AuthUser(ModeratorOnly(UndeleteUser)))
+	router.Put("/users/{email}/follow", AuthUser(FollowUser))
+	router.Delete("/users/{email}/follow", AuthUser(UnfollowUser))
+
+	router.Get("/questions", AuthUser(ListQuestions))
+	router.Post("/questions", AuthUser(PostQuestion))
+	router.Get("/questions/deleted", AuthUser(ModeratorOnly(DeletedQuestions)))
+	router.Delete("/questions/{question_id}", AuthUser(DeleteQuestion))
+	router.Post("/questions/{question_id}/restore", AuthUser(ModeratorOnly(UndeleteQuestion)))
+	router.Get("/questions/{question_id}/similar", AuthUser(SimilarQuestions))
+	router.Put("/questions/{question_id}/duplicate", AuthUser(ModeratorOnly(MarkDuplicateQuestion)))
+	router.Put("/questions/{question_id}/state", AuthUser(ModeratorOnly(ChangeQuestionState)))
+	router.Post("/questions/{question_id}/close-votes", AuthUser(CloseVoteQuestion))
+	router.Post("/questions/{question_id}/reopen-votes", AuthUser(ReopenVoteQuestion))
+	router.Get("/questions/{question_id}/answers", AuthUser(QuestionAnswersByRate))
+	router.Post("/questions/{question_id}/answers", AuthUser(PostAnswer))
+	router.Put("/questions/{question_id}/follow", AuthUser(FollowQuestion))
+	router.Delete("/questions/{question_id}/follow", AuthUser(UnfollowQuestion))
+
+	router.Get("/answers/deleted", AuthUser(ModeratorOnly(DeletedAnswers)))
+	router.Delete("/answers/{answer_id}", AuthUser(DeleteAnswer))
+	router.Post("/answers/{answer_id}/restore", AuthUser(ModeratorOnly(UndeleteAnswer)))
+	router.Post("/answers/{answer_id}/votes", AuthUser(RateAnswer))
+	router.Post("/answers/{answer_id}/accept", AuthUser(AcceptAnswer))
+
+	router.Put("/tags/{tag}/follow", AuthUser(FollowTag))
+	router.Delete("/tags/{tag}/follow", AuthUser(UnfollowTag))
+
+	router.Get("/follows", AuthUser(ListFollows))
+	router.Get("/feed", AuthUser(PersonalFeed))
+
+	router.Get("/search", AuthUser(SearchPosts))
+	router.Post("/search/reindex", AuthUser(ModeratorOnly(ReindexSearch)))
+
+	router.Get("/cache/stats", AuthUser(ModeratorOnly(CacheStatistics)))
+
+	router.Get("/notifications", AuthUser(ListNotifications))
+	router.Get("/notifications/unread-count", AuthUser(UnreadNotifications))
+	router.Post("/notifications/read", AuthUser(MarkAllNotificationsRead))
+	router.Put("/notifications/{notification_id}", AuthUser(MarkNotification))
+	router.Get("/notifications/preferences", AuthUser(GetNotificationPreferences))
+	router.Put("/notifications/preferences", AuthUser(SetNotificationPreferences))
+
+	router.Get("/digests/settings", AuthUser(GetDigestSettings))
+	router.Put("/digests/settings", AuthUser(SetDigestSettings))
+	router.Get("/digests/unsubscribe", ConfirmUnsubscribeDigests)
+	router.Post("/digests/unsubscribe", UnsubscribeDigests)
+
+	router.Post("/webhooks", AuthUser(CreateWebhook))
+	router.Get("/webhooks", AuthUser(ListWebhooks))
+	router.Delete("/webhooks/{webhook_id}", AuthUser(DeleteWebhook))
+	router.Get("/webhooks/{webhook_id}/deliveries", AuthUser(WebhookDeliveries))
+	router.Post("/webhooks/{webhook_id}/deliveries/{delivery_id}/replay", AuthUser(ReplayWebhookDelivery))
+}
+
+/**
+verb style paths of the first releases, served as v1
+ */
+func registerLegacyRoutes(router *Router) {
+	router.Post("/user/create", Deprecated("/v1/users", CreateUser))
+	router.Post("/user/login", Deprecated("/v1/sessions", LoginUser))
+	router.Get("/user/list/top5", Deprecated("/v1/users/top", AuthUser(UsersTopFive)))
+	router.Post("/user/delete", Deprecated("/v1/users/{email}", AuthUser(DeleteUser)))
+	router.Post("/user/undelete", Deprecated("/v1/users/{email}/restore", AuthUser(ModeratorOnly(UndeleteUser))))
+	router.Get("/user/list/deleted", Deprecated("/v1/users/deleted", AuthUser(ModeratorOnly(DeletedUsers))))
+
+	router.Post("/question/new", Deprecated("/v1/questions", AuthUser(PostQuestion)))
+	router.Get("/question/list/byanswers", Deprecated("/v1/questions?sort=answers", AuthUser(QuestionsByAnswer)))
+	router.Get("/question/list", Deprecated("/v1/questions", AuthUser(ListQuestions)))
+	router.Get("/question/similar", Deprecated("/v1/questions/{question_id}/similar", AuthUser(SimilarQuestions)))
+	router.Post("/question/duplicate", Deprecated("/v1/questions/{question_id}/duplicate", AuthUser(ModeratorOnly(MarkDuplicateQuestion))))
+	router.Post("/question/vote/close", Deprecated("/v1/questions/{question_id}/close-votes", AuthUser(CloseVoteQuestion)))
+	router.Post("/question/vote/reopen", Deprecated("/v1/questions/{question_id}/reopen-votes", AuthUser(ReopenVoteQuestion)))
+	router.Post("/question/state", Deprecated("/v1/questions/{question_id}/state", AuthUser(ModeratorOnly(ChangeQuestionState))))
+	router.Post("/question/delete", Deprecated("/v1/questions/{question_id}", AuthUser(DeleteQuestion)))
+	router.Post("/question/undelete", Deprecated("/v1/questions/{question_id}/restore", AuthUser(ModeratorOnly(UndeleteQuestion))))
+	router.Get("/question/list/deleted", Deprecated("/v1/questions/deleted", AuthUser(ModeratorOnly(DeletedQuestions))))
+
+	router.Post("/answer/new", Deprecated("/v1/questions/{question_id}/answers", AuthUser(PostAnswer)))
+	router.Post("/answer/rate", Deprecated("/v1/answers/{answer_id}/votes", AuthUser(LegacyRateAnswer)))
+	router.Get("/answer/list/byrate", Deprecated("/v1/questions/{question_id}/answers", AuthUser(QuestionAnswersByRate)))
+	router.Post("/answer/accept", Deprecated("/v1/answers/{answer_id}/accept", AuthUser(AcceptAnswer)))
+	router.Post("/answer/delete", Deprecated("/v1/answers/{answer_id}", AuthUser(DeleteAnswer)))
+	router.Post("/answer/undelete", Deprecated("/v1/answers/{answer_id}/restore", AuthUser(ModeratorOnly(UndeleteAnswer))))
+	router.Get("/answer/list/deleted", Deprecated("/v1/answers/deleted", AuthUser(ModeratorOnly(DeletedAnswers))))
+}
@@ -0,0 +1,621 @@
+package main
+
+import (
+	"database/sql"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	SearchKindQuestion = "question"
+	SearchKindAnswer   = "answer"
+
+	// BM25 ranking parameters
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// minimal cosine similarity of likely duplicates and of similar questions
+	duplicateThreshold = 0.5
+	similarThreshold   = 0.1
+)
+
+/**
+full-text index over questions and answers, kept up to date by the domain events of their saves
+ */
+type SearchIndex interface {
+	IndexQuestion(q Question) error
+	IndexAnswer(a Answer) error
+	Remove(kind string, id int64) error
+	Search(query SearchQuery) ([]SearchHit, PageInfo, error)
+	SimilarQuestions(text string, excludeId int64, minScore float64, limit int) ([]SearchHit, error)
+	Reset() error
+}
+
+var SearchIdx SearchIndex = NewMemoryIndex()
+
+type SearchQuery struct {
+	Terms   []string
+	Phrases [][]string
+	Kind    string
+	Tags    []string
+	UserId  int64
+	Cursor  string
+	Limit   int
+}
+
+type SearchHit struct {
+	Kind       string    `json:"type"`
+	Id         int64     `json:"id"`
+	QuestionId int64     `json:"question_id"`
+	Score      float64   `json:"score"`
+	Question   *Question `json:"question,omitempty"`
+	Answer     *Answer   `json:"answer,omitempty"`
+}
+
+/**
+splits the search text into terms, quoted parts of the text become phrases
+ */
+func ParseSearchQuery(text string) SearchQuery {
+	var query SearchQuery
+
+	for k, part := range strings.Split(text, "\"") {
+		terms := Tokenize(part)
+		if k%2 == 1 && len(terms) > 1 {
+			query.Phrases = append(query.Phrases, terms)
+			continue
+		}
+		query.Terms = append(query.Terms, terms...)
+	}
+
+	return query
+}
+
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+type searchDocKey struct {
+	kind string
+	id   int64
+}
+
+type searchDoc struct {
+	key        searchDocKey
+	questionId int64
+	userId     int64
+	length     int
+	terms      []string
+}
+
+/**
+pure go inverted index living in the memory of the process, rebuilt from the database on start
+ */
+type MemoryIndex struct {
+	mutex        sync.RWMutex
+	docs         map[searchDocKey]*searchDoc
+	postings     map[string]map[searchDocKey][]int
+	questionTags map[int64][]string
+	totalLength  int
+}
+
+func NewMemoryIndex() *MemoryIndex {
+	var mi *MemoryIndex = &MemoryIndex{}
+	mi.Reset()
+	return mi
+}
+
+func (mi *MemoryIndex) Reset() error {
+	mi.mutex.Lock()
+	defer mi.mutex.Unlock()
+
+	mi.docs = make(map[searchDocKey]*searchDoc)
+	mi.postings = make(map[string]map[searchDocKey][]int)
+	mi.questionTags = make(map[int64][]string)
+	mi.totalLength = 0
+
+	return nil
+}
+
+func (mi *MemoryIndex) IndexQuestion(q Question) error {
+	mi.mutex.Lock()
+	defer mi.mutex.Unlock()
+
+	mi.questionTags[q.Id] = q.Tags
+	mi.add(&searchDoc{key: searchDocKey{SearchKindQuestion, q.Id}, questionId: q.Id, userId: q.UserId}, q.Question)
+
+	return nil
+}
+
+func (mi *MemoryIndex) IndexAnswer(a Answer) error {
+	mi.mutex.Lock()
+	defer mi.mutex.Unlock()
+
+	mi.add(&searchDoc{key: searchDocKey{SearchKindAnswer, a.Id}, questionId: a.QuestionId, userId: a.UserId}, a.Answer)
+
+	return nil
+}
+
+func (mi *MemoryIndex) Remove(kind string, id int64) error {
+	mi.mutex.Lock()
+	defer mi.mutex.Unlock()
+
+	mi.remove(searchDocKey{kind, id})
+	if kind == SearchKindQuestion {
+		delete(mi.questionTags, id)
+	}
+
+	return nil
+}
+
+func (mi *MemoryIndex) add(doc *searchDoc, text string) {
+	mi.remove(doc.key)
+
+	terms := Tokenize(text)
+	for position, term := range terms {
+		if mi.postings[term] == nil {
+			mi.postings[term] = make(map[searchDocKey][]int)
+		}
+		if _, ok := mi.postings[term][doc.key]; !ok {
+			doc.terms = append(doc.terms, term)
+		}
+		mi.postings[term][doc.key] = append(mi.postings[term][doc.key], position)
+	}
+
+	doc.length = len(terms)
+	mi.docs[doc.key] = doc
+	mi.totalLength += doc.length
+}
+
+func (mi *MemoryIndex) remove(key searchDocKey) {
+	doc, ok := mi.docs[key]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		delete(mi.postings[term], key)
+		if len(mi.postings[term]) == 0 {
+			delete(mi.postings, term)
+		}
+	}
+
+	mi.totalLength -= doc.length
+	delete(mi.docs, key)
+}
+
+/**
+returns the requested page of documents containing every term and phrase of the query,
+ordered by BM25 relevance, together with the count of all matching documents
+ */
+func (mi *MemoryIndex) Search(query SearchQuery) ([]SearchHit, PageInfo, error) {
+	mi.mutex.RLock()
+	defer mi.mutex.RUnlock()
+
+	var hits []SearchHit = []SearchHit{}
+	var terms []string = uniqueTerms(query)
+
+	if len(terms) == 0 || len(mi.docs) == 0 {
+		return pageHits(hits, query.Cursor, query.Limit)
+	}
+
+	var avgLength float64 = float64(mi.totalLength) / float64(len(mi.docs))
+
+	for key := range mi.postings[terms[0]] {
+		doc := mi.docs[key]
+		if !mi.matches(doc, query, terms) {
+			continue
+		}
+
+		var score float64
+		for _, term := range terms {
+			df := float64(len(mi.postings[term]))
+			tf := float64(len(mi.postings[term][key]))
+			idf := math.Log(1 + (float64(len(mi.docs))-df+0.5)/(df+0.5))
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(doc.length)/avgLength))
+		}
+
+		hits = append(hits, SearchHit{Kind: key.kind, Id: key.id, QuestionId: doc.questionId, Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		return hitLess(hits[i], hits[j])
+	})
+
+	return pageHits(hits, query.Cursor, query.Limit)
+}
+
+/**
+returns the questions most similar to the text by TF-IDF cosine similarity, leaving out
+the excluded question and the ones scoring below minScore
+ */
+func (mi *MemoryIndex) SimilarQuestions(text string, excludeId int64, minScore float64, limit int) ([]SearchHit, error) {
+	mi.mutex.RLock()
+	defer mi.mutex.RUnlock()
+
+	var hits []SearchHit = []SearchHit{}
+	var weights map[string]float64 = make(map[string]float64)
+	var dots map[int64]float64 = make(map[int64]float64)
+	var norm float64
+
+	for _, term := range Tokenize(text) {
+		weights[term] += 1
+	}
+
+	for term, tf := range weights {
+		weights[term] = tf * mi.idf(term)
+		norm += weights[term] * weights[term]
+
+		for key, positions := range mi.postings[term] {
+			if key.kind != SearchKindQuestion || key.id == excludeId {
+				continue
+			}
+			dots[key.id] += weights[term] * float64(len(positions)) * mi.idf(term)
+		}
+	}
+
+	if norm == 0 {
+		return hits, nil
+	}
+
+	for id, dot := range dots {
+		score := dot / (math.Sqrt(norm) * mi.norm(mi.docs[searchDocKey{SearchKindQuestion, id}]))
+		if score >= minScore {
+			hits = append(hits, SearchHit{Kind: SearchKindQuestion, Id: id, QuestionId: id, Score: score})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		return hitLess(hits[i], hits[j])
+	})
+
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits, nil
+}
+
+func (mi *MemoryIndex) idf(term string) float64 {
+	var df int = len(mi.postings[term])
+
+	if df == 0 {
+		return math.Log(1 + float64(len(mi.docs)))
+	}
+
+	return math.Log(1 + float64(len(mi.docs))/float64(df))
+}
+
+func (mi *MemoryIndex) norm(doc *searchDoc) float64 {
+	var sum float64
+
+	for _, term := range doc.terms {
+		weight := float64(len(mi.postings[term][doc.key])) * mi.idf(term)
+		sum += weight * weight
+	}
+
+	return math.Sqrt(sum)
+}
+
+func (mi *MemoryIndex) matches(doc *searchDoc, query SearchQuery, terms []string) bool {
+	if query.Kind != "" && query.Kind != doc.key.kind {
+		return false
+	}
+
+	if query.UserId != 0 && query.UserId != doc.userId {
+		return false
+	}
+
+	for _, term := range terms {
+		if _, ok := mi.postings[term][doc.key]; !ok {
+			return false
+		}
+	}
+
+	for _, tag := range query.Tags {
+		if !containsString(mi.questionTags[doc.questionId], tag) {
+			return false
+		}
+	}
+
+	for _, phrase := range query.Phrases {
+		if !mi.containsPhrase(doc.key, phrase) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (mi *MemoryIndex) containsPhrase(key searchDocKey, phrase []string) bool {
+	for _, start := range mi.postings[phrase[0]][key] {
+		var found bool = true
+		for offset, term := range phrase[1:] {
+			positions := mi.postings[term][key]
+			k := sort.SearchInts(positions, start+offset+1)
+			if k == len(positions) || positions[k] != start+offset+1 {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+
+	return false
+}
+
+func uniqueTerms(query SearchQuery) []string {
+	var terms []string
+	var seen map[string]bool = make(map[string]bool)
+
+	for _, term := range query.Terms {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	for _, phrase := range query.Phrases {
+		for _, term := range phrase {
+			if !seen[term] {
+				seen[term] = true
+				terms = append(terms, term)
+			}
+		}
+	}
+
+	return terms
+}
+
+/**
+reports whether hit a is ranked before hit b: higher score first, then newer id
+ */
+func hitLess(a SearchHit, b SearchHit) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	if a.Id != b.Id {
+		return a.Id > b.Id
+	}
+	return a.Kind < b.Kind
+}
+
+/**
+cuts the page after (or before) the cursor out of the ranked hits
+ */
+func pageHits(hits []SearchHit, token string, limit int) ([]SearchHit, PageInfo, error) {
+	var info PageInfo
+	var total int64 = int64(len(hits))
+	var start, end int = 0, len(hits)
+
+	info.Total = &total
+
+	if token != "" {
+		cursor, err := DecodeCursor(token)
+		if err != nil {
+			return []SearchHit{}, info, err
+		}
+
+		score, err := strconv.ParseFloat(cursor.Key, 64)
+		if err != nil {
+			return []SearchHit{}, info, ErrInvalidCursor
+		}
+
+		var at SearchHit = SearchHit{Kind: cursor.Kind, Id: cursor.Id, Score: score}
+		if cursor.Before {
+			end = sort.Search(len(hits), func(i int) bool { return !hitLess(hits[i], at) })
+			start = end - limit
+			if start < 0 {
+				start = 0
+			}
+		} else {
+			start = sort.Search(len(hits), func(i int) bool { return hitLess(at, hits[i]) })
+		}
+	}
+
+	if end > start+limit {
+		end = start + limit
+	}
+
+	if start < end && end < len(hits) {
+		info.Next = EncodeCursor(hitCursor(hits[end-1], false))
+	}
+
+	if start < end && start > 0 {
+		info.Prev = EncodeCursor(hitCursor(hits[start], true))
+	}
+
+	return hits[start:end], info, nil
+}
+
+func hitCursor(hit SearchHit, before bool) Cursor {
+	return Cursor{Key: strconv.FormatFloat(hit.Score, 'g', -1, 64), Id: hit.Id, Kind: hit.Kind, Before: before}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+/**
+loads the question or answer of every hit with its author, leaving out the hits which
+can not be loaded anymore (for example answers of deleted questions)
+ */
+func LoadSearchHits(hits []SearchHit) ([]SearchHit, error) {
+	var results []SearchHit = []SearchHit{}
+	var questionIds []int64
+	var answerIds []int64
+	var questionsById map[int64]*Question = make(map[int64]*Question)
+	var answersById map[int64]*Answer = make(map[int64]*Answer)
+
+	for _, hit := range hits {
+		questionIds = append(questionIds, hit.QuestionId)
+		if hit.Kind == SearchKindAnswer {
+			answerIds = append(answerIds, hit.Id)
+		}
+	}
+
+	questions, err := selectQuestions(uniqueIds(questionIds))
+	if err != nil {
+		return nil, err
+	}
+
+	answers, err := selectAnswers(answerIds)
+	if err != nil {
+		return nil, err
+	}
+
+	err = attachQuestionAuthors(questions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = attachQuestionTags(questions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = attachAnswerAuthors(answers)
+	if err != nil {
+		return nil, err
+	}
+
+	for k := range questions {
+		if questions[k].DeletedAt == nil {
+			questionsById[questions[k].Id] = &questions[k]
+		}
+	}
+
+	for k := range answers {
+		if answers[k].DeletedAt == nil {
+			answersById[answers[k].Id] = &answers[k]
+		}
+	}
+
+	for _, hit := range hits {
+		if questionsById[hit.QuestionId] == nil {
+			continue
+		}
+
+		if hit.Kind == SearchKindAnswer && answersById[hit.Id] == nil {
+			continue
+		} else if hit.Kind == SearchKindAnswer {
+			hit.Answer = answersById[hit.Id]
+		} else {
+			hit.Question = questionsById[hit.Id]
+		}
+
+		results = append(results, hit)
+	}
+
+	return results, nil
+}
+
+/**
+drops the search index and indexes every question and answer which is not deleted
+ */
+func RebuildSearchIndex() error {
+	var questions []Question
+	var answers []Answer
+	var tags []QuestionTag
+	var questionTags map[int64][]string = make(map[int64][]string)
+
+	err := SearchIdx.Reset()
+	if err != nil {
+		return err
+	}
+
+	_, err = DBMap.Select(&tags, "SELECT * FROM question_tag ORDER BY tag")
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		questionTags[tag.QuestionId] = append(questionTags[tag.QuestionId], tag.Tag)
+	}
+
+	_, err = DBMap.Select(&questions, "SELECT * FROM question WHERE deleted_at IS NULL")
+	if err != nil {
+		return err
+	}
+
+	for _, question := range questions {
+		question.Tags = questionTags[question.Id]
+		err = SearchIdx.IndexQuestion(question)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = DBMap.Select(&answers, "SELECT * FROM answer WHERE deleted_at IS NULL")
+	if err != nil {
+		return err
+	}
+
+	for _, answer := range answers {
+		err = SearchIdx.IndexAnswer(answer)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/**
+indexes the saved questions and answers, the soft deleted ones are removed from the index
+ */
+func subscribeSearchIndex(bus *EventBus) {
+	bus.Subscribe("search", func(e DomainEvent) error {
+		switch event := e.(type) {
+		case QuestionPosted:
+			return reindexQuestion(event.QuestionId)
+		case QuestionUpdated:
+			return reindexQuestion(event.QuestionId)
+		case AnswerPosted:
+			return reindexAnswer(event.AnswerId)
+		case AnswerUpdated:
+			return reindexAnswer(event.AnswerId)
+		}
+
+		return nil
+	}, EventQuestionPosted, EventQuestionUpdated, EventAnswerPosted, EventAnswerUpdated)
+}
+
+func reindexQuestion(id int64) error {
+	var question Question
+
+	err := question.LoadWithDeleted(id)
+	if err == sql.ErrNoRows || (err == nil && question.DeletedAt != nil) {
+		return SearchIdx.Remove(SearchKindQuestion, id)
+	} else if err != nil {
+		return err
+	}
+
+	return SearchIdx.IndexQuestion(question)
+}
+
+func reindexAnswer(id int64) error {
+	var answer Answer
+
+	err := answer.LoadWithDeleted(id)
+	if err == sql.ErrNoRows || (err == nil && answer.DeletedAt != nil) {
+		return SearchIdx.Remove(SearchKindAnswer, id)
+	} else if err != nil {
+		return err
+	}
+
+	return SearchIdx.IndexAnswer(answer)
+}
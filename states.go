@@ -0,0 +1,240 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	QuestionOpen     = "open"
+	QuestionClosed   = "closed"
+	QuestionLocked   = "locked"
+	QuestionArchived = "archived"
+
+	CloseReasonDuplicate    = "duplicate"
+	CloseReasonOffTopic     = "off-topic"
+	CloseReasonUnclear      = "unclear"
+	CloseReasonTooBroad     = "too-broad"
+	CloseReasonOpinionBased = "opinion-based"
+
+	QuestionVoteClose  = "close"
+	QuestionVoteReopen = "reopen"
+
+	// community votes needed to close or reopen a question
+	questionVoteThreshold = 3
+)
+
+var (
+	ErrInvalidTransition  = errors.New("question state can not be changed that way")
+	ErrInvalidCloseReason = errors.New("invalid close reason")
+	ErrMissingDuplicate   = errors.New("duplicate close reason requires the canonical question")
+)
+
+var questionTransitions map[string][]string = map[string][]string{
+	QuestionOpen:     {QuestionClosed, QuestionLocked, QuestionArchived},
+	QuestionClosed:   {QuestionOpen, QuestionClosed, QuestionLocked, QuestionArchived},
+	QuestionLocked:   {QuestionOpen, QuestionClosed, QuestionArchived},
+	QuestionArchived: {QuestionOpen},
+}
+
+var closeReasons []string = []string{CloseReasonDuplicate, CloseReasonOffTopic, CloseReasonUnclear, CloseReasonTooBroad, CloseReasonOpinionBased}
+
+/**
+questions saved before states were introduced have empty state, those are open
+ */
+func (q Question) CurrentState() string {
+	if q.State == "" {
+		return QuestionOpen
+	}
+
+	return q.State
+}
+
+/**
+moves the question to the given state if the state machine allows it, closing needs one of the
+close reasons and a duplicate close needs the canonical question set in DuplicateOfId.
+votes cast in the previous state are dropped
+ */
+func (q *Question) Transition(state string, reason string, by User) error {
+	return Transaction(func(uow *UnitOfWork) error {
+		return q.TransitionIn(uow, state, reason, by)
+	})
+}
+
+func (q *Question) TransitionIn(uow *UnitOfWork, state string, reason string, by User) error {
+	if !containsString(questionTransitions[q.CurrentState()], state) {
+		return ErrInvalidTransition
+	}
+
+	if state == QuestionClosed && !containsString(closeReasons, reason) {
+		return ErrInvalidCloseReason
+	}
+
+	if state == QuestionClosed && reason == CloseReasonDuplicate && q.DuplicateOfId == 0 {
+		return ErrMissingDuplicate
+	}
+
+	if state != QuestionClosed {
+		reason = ""
+	}
+
+	if reason != CloseReasonDuplicate {
+		q.DuplicateOfId = 0
+	}
+
+	var from string = q.CurrentState()
+	var now time.Time = time.Now()
+	q.State = state
+	q.CloseReason = reason
+	q.StateChangedAt = &now
+	q.StateChangedBy = by.Id
+
+	_, err := uow.Executor().Exec("DELETE FROM question_vote WHERE question_id = ?", q.Id)
+	if err != nil {
+		return err
+	}
+
+	err = q.SaveIn(uow)
+	if err != nil {
+		return err
+	}
+
+	return uow.Record(QuestionStateChanged{QuestionId: q.Id, From: from, To: state, ByUserId: by.Id})
+}
+
+/**
+error of writing a question which does not accept new answers and rates
+ */
+type QuestionStateError struct {
+	Message string
+}
+
+func (e QuestionStateError) Error() string {
+	return e.Message
+}
+
+/**
+returns a QuestionStateError describing why the question does not accept new answers and rates
+ */
+func (q Question) CheckWritable() error {
+	switch q.CurrentState() {
+	case QuestionClosed:
+		return QuestionStateError{fmt.Sprintf("question is closed as %s", q.CloseReason)}
+	case QuestionLocked:
+		return QuestionStateError{"question is locked"}
+	case QuestionArchived:
+		return QuestionStateError{"question is archived"}
+	}
+
+	return nil
+}
+
+type QuestionVote struct {
+	Id            int64     `db:"id, primarykey, autoincrement" json:"-"`
+	QuestionId    int64     `db:"question_id, notnull" json:"-"`
+	UserId        int64     `db:"user_id, notnull" json:"-"`
+	Kind          string    `db:"kind, size:16, notnull" json:"kind"`
+	Reason        string    `db:"reason, size:32" json:"reason,omitempty"`
+	DuplicateOfId int64     `db:"duplicate_of_id" json:"duplicate_of_id,omitempty"`
+	CreatedAt     time.Time `db:"created_at, notnull" json:"created_at"`
+}
+
+func (qv *QuestionVote) LoadByQuestionAndUser(question Question, user User, kind string) error {
+	err := DBMap.SelectOne(qv, "SELECT * FROM question_vote WHERE question_id = ? AND user_id = ? AND kind = ?", question.Id, user.Id, kind)
+
+	return err
+}
+
+func (qv *QuestionVote) Save() error {
+	return Transaction(qv.SaveIn)
+}
+
+func (qv *QuestionVote) SaveIn(uow *UnitOfWork) error {
+	var err error
+	if qv.Id == 0 {
+		err = uow.Executor().Insert(qv)
+	} else {
+		_, err = uow.Executor().Update(qv)
+	}
+
+	return err
+}
+
+func NewQuestionVote(kind string, reason string, duplicateOfId int64, user User, question Question) QuestionVote {
+	var qv QuestionVote = QuestionVote{QuestionId: question.Id, UserId: user.Id, Kind: kind, Reason: reason, DuplicateOfId: duplicateOfId, CreatedAt: time.Now()}
+	return qv
+}
+
+/**
+records the vote and, once questionVoteThreshold votes of its kind are collected, closes the
+question with the most voted reason or reopens it. reports whether the state has changed
+ */
+func (q *Question) AddVote(vote QuestionVote, by User) (bool, error) {
+	var changed bool
+
+	err := Transaction(func(uow *UnitOfWork) error {
+		var err error
+		changed, err = q.AddVoteIn(uow, vote, by)
+		return err
+	})
+
+	return changed, err
+}
+
+func (q *Question) AddVoteIn(uow *UnitOfWork, vote QuestionVote, by User) (bool, error) {
+	var votes []QuestionVote
+
+	err := q.LoadForUpdate(uow, q.Id)
+	if err != nil {
+		return false, err
+	}
+
+	if vote.Kind == QuestionVoteClose && q.CurrentState() != QuestionOpen {
+		return false, ErrInvalidTransition
+	}
+
+	if vote.Kind == QuestionVoteReopen && q.CurrentState() != QuestionClosed {
+		return false, ErrInvalidTransition
+	}
+
+	if vote.Kind == QuestionVoteClose && !containsString(closeReasons, vote.Reason) {
+		return false, ErrInvalidCloseReason
+	}
+
+	if vote.Kind == QuestionVoteClose && vote.Reason == CloseReasonDuplicate && vote.DuplicateOfId == 0 {
+		return false, ErrMissingDuplicate
+	}
+
+	err = vote.SaveIn(uow)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = uow.Executor().Select(&votes, "SELECT * FROM question_vote WHERE question_id = ? AND kind = ? ORDER BY id FOR UPDATE", q.Id, vote.Kind)
+	if err != nil {
+		return false, err
+	}
+
+	if len(votes) < questionVoteThreshold {
+		return false, nil
+	}
+
+	if vote.Kind == QuestionVoteReopen {
+		return true, q.TransitionIn(uow, QuestionOpen, "", by)
+	}
+
+	var reasonCounts map[string]int = make(map[string]int)
+	var reason string
+	for _, v := range votes {
+		reasonCounts[v.Reason]++
+		if reasonCounts[v.Reason] > reasonCounts[reason] {
+			reason = v.Reason
+		}
+		if v.Reason == CloseReasonDuplicate {
+			q.DuplicateOfId = v.DuplicateOfId
+		}
+	}
+
+	return true, q.TransitionIn(uow, QuestionClosed, reason, by)
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// recent events kept for the clients resuming by Last-Event-ID
+	eventHistorySize = 1000
+
+	// events queued per connection, a client falling further behind is disconnected
+	// and resumes from the history on reconnect
+	eventBufferSize = 64
+
+	heartbeatInterval = 15 * time.Second
+
+	// reconnect delay suggested to the clients, in milliseconds
+	eventRetryDelay = 3000
+)
+
+/**
+change streamed to the subscribers of its question, of its tags and of its recipients,
+Data is the changed model
+ */
+type StreamEvent struct {
+	Id         int64
+	Type       string
+	QuestionId int64
+	Tags       []string
+	Recipients []int64
+	Data       interface{}
+}
+
+/**
+topics a connection listens to, the events of the question, of the tag or the ones notifying the user.
+the events are queued in a buffer of eventBufferSize, closed by the hub when it overflows
+ */
+type Subscription struct {
+	QuestionId int64
+	Tag        string
+	UserId     int64
+	events     chan StreamEvent
+}
+
+func NewSubscription(questionId int64, tag string, userId int64) *Subscription {
+	return &Subscription{QuestionId: questionId, Tag: tag, UserId: userId, events: make(chan StreamEvent, eventBufferSize)}
+}
+
+func (s *Subscription) Matches(e StreamEvent) bool {
+	if s.QuestionId != 0 && s.QuestionId == e.QuestionId {
+		return true
+	}
+
+	if s.Tag != "" && containsString(e.Tags, s.Tag) {
+		return true
+	}
+
+	for _, id := range e.Recipients {
+		if s.UserId != 0 && s.UserId == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+/**
+fans the published events out to the subscriptions and keeps the last ones for resuming.
+the ids start at the creation time in nanoseconds, so the ids of an earlier process are older
+than the history and their clients get a reset instead of a silent gap
+ */
+type EventHub struct {
+	mu            sync.Mutex
+	nextId        int64
+	history       []StreamEvent
+	historySize   int
+	subscriptions map[*Subscription]bool
+}
+
+var Events *EventHub = NewEventHub(eventHistorySize)
+
+func NewEventHub(historySize int) *EventHub {
+	return &EventHub{nextId: time.Now().UnixNano(), historySize: historySize, subscriptions: make(map[*Subscription]bool)}
+}
+
+/**
+never blocks: a subscription with a full buffer is closed and dropped
+ */
+func (h *EventHub) Publish(e StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	e.Id = h.nextId
+	h.nextId++
+
+	h.history = append(h.history, e)
+	if len(h.history) > h.historySize {
+		h.history = h.history[len(h.history)-h.historySize:]
+	}
+
+	for sub := range h.subscriptions {
+		if !sub.Matches(e) {
+			continue
+		}
+
+		select {
+		case sub.events <- e:
+		default:
+			delete(h.subscriptions, sub)
+			close(sub.events)
+		}
+	}
+}
+
+/**
+registers the subscription and returns its events after lastId from the history, complete is false
+when the history does not reach back to lastId. lastId 0 starts from now
+ */
+func (h *EventHub) Subscribe(sub *Subscription, lastId int64) ([]StreamEvent, bool) {
+	var missed []StreamEvent
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.subscriptions[sub] = true
+
+	if lastId == 0 {
+		return nil, true
+	}
+
+	var firstId int64 = h.nextId
+	if len(h.history) > 0 {
+		firstId = h.history[0].Id
+	}
+
+	if lastId < firstId-1 || lastId >= h.nextId {
+		return nil, false
+	}
+
+	for _, e := range h.history {
+		if e.Id > lastId && sub.Matches(e) {
+			missed = append(missed, e)
+		}
+	}
+
+	return missed, true
+}
+
+func (h *EventHub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscriptions[sub] {
+		delete(h.subscriptions, sub)
+		close(sub.events)
+	}
+}
+
+/**
+streams the domain events to the subscriptions of their question, tags and notified users
+ */
+func subscribeEventStream(bus *EventBus) {
+	bus.SubscribeAsync("stream", func(e DomainEvent) error {
+		switch event := e.(type) {
+		case QuestionPosted:
+			return publishQuestionEvent(e.EventName(), event.QuestionId, event.UserId)
+		case QuestionUpdated:
+			return publishQuestionEvent(e.EventName(), event.QuestionId, 0)
+		case QuestionStateChanged:
+			return publishQuestionEvent(e.EventName(), event.QuestionId, event.ByUserId)
+		case AnswerPosted:
+			return publishAnswerEvent(e.EventName(), event.AnswerId, event.UserId, true)
+		case AnswerUpdated:
+			return publishAnswerEvent(e.EventName(), event.AnswerId, 0, false)
+		case AnswerRated:
+			return publishAnswerEvent(e.EventName(), event.AnswerId, event.UserId, false)
+		case AnswerAccepted:
+			return publishAnswerEvent(e.EventName(), event.AnswerId, event.ByUserId, false)
+		}
+
+		return nil
+	}, EventQuestionPosted, EventQuestionUpdated, EventQuestionStateChanged, EventAnswerPosted, EventAnswerUpdated, EventAnswerRated, EventAnswerAccepted)
+}
+
+/**
+publishes the question to its subscribers, its author is notified unless it made the change.
+a deleted question is published too, so the subscribers learn of the delete. byUserId is 0 for
+the updates, the one deleting the question made the change then
+ */
+func publishQuestionEvent(eventType string, questionId int64, byUserId int64) error {
+	var question Question
+
+	err := question.LoadWithDeleted(questionId)
+	if err != nil {
+		return err
+	}
+
+	if byUserId == 0 {
+		byUserId = question.DeletedBy
+	}
+
+	Events.Publish(StreamEvent{Type: eventType, QuestionId: question.Id, Tags: question.Tags, Recipients: recipients(byUserId, question.UserId), Data: question})
+
+	return nil
+}
+
+/**
+publishes the answer to the subscribers of its question, notifying the author of the question
+for a new answer and the author of the answer otherwise. deleted answers are published as the
+questions are by publishQuestionEvent
+ */
+func publishAnswerEvent(eventType string, answerId int64, byUserId int64, toQuestionAuthor bool) error {
+	var answer Answer
+	var question Question
+
+	err := answer.LoadWithDeleted(answerId)
+	if err == nil {
+		err = question.LoadWithDeleted(answer.QuestionId)
+	}
+	if err == nil {
+		err = answer.AddUserData()
+	}
+	if err != nil {
+		return err
+	}
+
+	if byUserId == 0 {
+		byUserId = answer.DeletedBy
+	}
+
+	var recipient int64 = answer.UserId
+	if toQuestionAuthor {
+		recipient = question.UserId
+	}
+
+	Events.Publish(StreamEvent{Type: eventType, QuestionId: question.Id, Tags: question.Tags, Recipients: recipients(byUserId, recipient), Data: answer})
+
+	return nil
+}
+
+/**
+users notified of a change, not the one who made it
+ */
+func recipients(byUserId int64, ids ...int64) []int64 {
+	var users []int64
+
+	for _, id := range ids {
+		if id != 0 && id != byUserId {
+			users = append(users, id)
+		}
+	}
+
+	return users
+}
+
+/**
+ server-sent events of a question, a tag and the notifications of the user to authenticated user request.
+ a reconnecting client sends the Last-Event-ID header and gets the events it missed, a reset event
+ tells it the missed events are no longer known and it has to reload. a heartbeat comment keeps the
+ connection open, a client too slow to read its events gets a dropped event and is disconnected
+ */
+func StreamEvents(w http.ResponseWriter, r *http.Request) {
+	var req StreamEventsRequest
+	var lastId int64
+
+	user, ok := AuthUserFrom(r)
+	if !ok {
+		writeError(w, r, CodeAccessForbidden)
+		return
+	}
+
+	err := decodeRequest(w, r, &req)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	if req.QuestionId == nil && req.Tag == "" && !req.Notifications {
+		writeRequestError(w, r, ValidationErrors{{Field: "question_id", Rule: "required", Message: "question_id, tag or notifications is required"}})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, CodeInternal)
+		return
+	}
+
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		lastId, err = strconv.ParseInt(header, 10, 64)
+		if err != nil {
+			lastId = -1
+		}
+	}
+
+	var sub *Subscription = NewSubscription(0, strings.ToLower(strings.TrimSpace(req.Tag)), 0)
+	if req.QuestionId != nil {
+		sub.QuestionId = *req.QuestionId
+	}
+	if req.Notifications {
+		sub.UserId = user.Id
+	}
+
+	missed, complete := Events.Subscribe(sub, lastId)
+	defer Events.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetryDelay)
+
+	if !complete {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+
+	for _, e := range missed {
+		if writeStreamEvent(w, r, e) != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	var ticker *time.Ticker = time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, open := <-sub.events:
+			if !open {
+				fmt.Fprint(w, "event: dropped\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+			err = writeStreamEvent(w, r, e)
+		case <-ticker.C:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+		}
+
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func writeStreamEvent(w http.ResponseWriter, r *http.Request, e StreamEvent) error {
+	data, err := json.Marshal(serialize(VersionFrom(r).Serializer, e.Data))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Id, e.Type, data)
+
+	return err
+}
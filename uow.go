@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"github.com/go-gorp/gorp"
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysql error number of a unique constraint violation
+const mysqlDuplicateEntry = 1062
+
+var ErrConflict = errors.New("entity has already been found")
+
+/**
+unit of work: the writes of a request run in one transaction, the side effects which must only
+happen once the writes are committed are queued by AfterCommit or, as domain events, by Record
+ */
+type UnitOfWork struct {
+	tx          *gorp.Transaction
+	afterCommit []func()
+}
+
+/**
+runs fn in a new unit of work, commits when fn succeeds and rolls back otherwise.
+unique constraint violations are returned as ErrConflict
+ */
+func Transaction(fn func(uow *UnitOfWork) error) error {
+	tx, err := DBMap.Begin()
+	if err != nil {
+		return err
+	}
+
+	var uow *UnitOfWork = &UnitOfWork{tx: tx}
+
+	err = fn(uow)
+	if err != nil {
+		tx.Rollback()
+		return translateError(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return translateError(err)
+	}
+
+	for _, f := range uow.afterCommit {
+		f()
+	}
+
+	return nil
+}
+
+func (uow *UnitOfWork) Executor() *gorp.Transaction {
+	return uow.tx
+}
+
+func (uow *UnitOfWork) AfterCommit(f func()) {
+	uow.afterCommit = append(uow.afterCommit, f)
+}
+
+/**
+records the event in the outbox in the transaction and publishes it once committed
+ */
+func (uow *UnitOfWork) Record(e DomainEvent) error {
+	oe, err := NewOutboxEvent(e)
+	if err != nil {
+		return err
+	}
+
+	err = uow.tx.Insert(&oe)
+	if err != nil {
+		return err
+	}
+
+	uow.AfterCommit(func() {
+		oe.dispatch(e, false)
+	})
+
+	return nil
+}
+
+func translateError(err error) error {
+	if me, ok := err.(*mysql.MySQLError); ok && me.Number == mysqlDuplicateEntry {
+		return ErrConflict
+	}
+
+	return err
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// request bodies above this size are refused before decoding
+const maxRequestBodySize = 1 << 20
+
+var (
+	ErrBodyTooLarge  = errors.New("request body is too large")
+	ErrMalformedBody = errors.New("request body is not a json object")
+)
+
+var emailPattern *regexp.Regexp = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+/**
+rule a field of a request failed, Field is the json path of the field
+ */
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type ValidationErrors []FieldError
+
+func (ve ValidationErrors) Error() string {
+	var messages []string
+	for _, fe := range ve {
+		messages = append(messages, fe.Field+": "+fe.Message)
+	}
+
+	return strings.Join(messages, ", ")
+}
+
+/**
+decodes the json body of the request into dst and validates it by the validate tags of its fields.
+the body is limited to maxRequestBodySize, unknown fields are refused and an empty body decodes
+as an empty object. the query string and the path parameters are bound over the body.
+returns ErrBodyTooLarge, ErrMalformedBody or ValidationErrors
+ */
+func decodeRequest(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	var decoder *json.Decoder = json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(dst)
+	if err == io.EOF {
+		err = nil
+	}
+
+	if err != nil {
+		return decodeError(err)
+	}
+
+	if decoder.More() {
+		return ErrMalformedBody
+	}
+
+	errs := bindParams(r, dst)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	errs = validateStruct(reflect.ValueOf(dst).Elem(), "")
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func decodeError(err error) error {
+	if strings.Contains(err.Error(), "request body too large") {
+		return ErrBodyTooLarge
+	}
+
+	if te, ok := err.(*json.UnmarshalTypeError); ok && te.Field != "" {
+		return ValidationErrors{{Field: te.Field, Rule: "type", Message: "must be " + jsonTypeName(te.Type)}}
+	}
+
+	if strings.HasPrefix(err.Error(), "json: unknown field ") {
+		var field string = strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return ValidationErrors{{Field: field, Rule: "unknown", Message: "is not a known field"}}
+	}
+
+	return ErrMalformedBody
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "a number"
+	case reflect.Slice:
+		return "an array"
+	case reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	}
+
+	return "an object"
+}
+
+/**
+sets the fields of dst named by the query string and then by the path parameters, so the resources
+can be requested without a body. repeated or comma separated values fill the arrays
+ */
+func bindParams(r *http.Request, dst interface{}) ValidationErrors {
+	var values map[string][]string = r.URL.Query()
+
+	for name, value := range PathParams(r) {
+		values[name] = []string{value}
+	}
+
+	return bindValues(reflect.ValueOf(dst).Elem(), values)
+}
+
+func bindValues(v reflect.Value, values map[string][]string) ValidationErrors {
+	var errs ValidationErrors = ValidationErrors{}
+	var t reflect.Type = v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		var field reflect.StructField = t.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			errs = append(errs, bindValues(v.Field(i), values)...)
+			continue
+		}
+
+		var name string = strings.Split(field.Tag.Get("json"), ",")[0]
+		if len(values[name]) == 0 {
+			continue
+		}
+
+		if setField(v.Field(i), values[name]) != nil {
+			errs = append(errs, FieldError{Field: name, Rule: "type", Message: "must be " + jsonTypeName(field.Type)})
+		}
+	}
+
+	return errs
+}
+
+func setField(f reflect.Value, values []string) error {
+	var value string = values[len(values)-1]
+
+	switch f.Kind() {
+	case reflect.Ptr:
+		var nv reflect.Value = reflect.New(f.Type().Elem())
+		err := setField(nv.Elem(), values)
+		if err != nil {
+			return err
+		}
+		f.Set(nv)
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		f.SetInt(i)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, v := range values {
+			items = append(items, strings.Split(v, ",")...)
+		}
+		f.Set(reflect.ValueOf(items))
+	}
+
+	return nil
+}
+
+/**
+writes the decoding error of the request: 413 for a too large body, 400 for a malformed one
+and 422 with the list of the field errors for invalid fields
+ */
+func writeRequestError(w http.ResponseWriter, r *http.Request, err error) {
+	if errs, ok := err.(ValidationErrors); ok {
+		writeProblem(w, r, CodeValidationFailed, Problem{Errors: errs})
+	} else if err == ErrBodyTooLarge {
+		writeError(w, r, CodeBodyTooLarge)
+	} else {
+		writeError(w, r, CodeMalformedBody)
+	}
+}
+
+/**
+validates the fields of the struct by their validate tags, embedded structs are validated as part of it.
+rules: required, min and max (length of strings and arrays, value of numbers), oneof (space separated
+values), email, date (dateFormat) and url (http or https). the rules after dive apply to each item of an array.
+nil pointers and empty values are only checked by required
+ */
+func validateStruct(v reflect.Value, prefix string) ValidationErrors {
+	var errs ValidationErrors = ValidationErrors{}
+	var t reflect.Type = v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		var field reflect.StructField = t.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			errs = append(errs, validateStruct(v.Field(i), prefix)...)
+			continue
+		}
+
+		var name string = strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+
+		var rules []string = strings.Split(field.Tag.Get("validate"), ",")
+		var items []string
+		for k, rule := range rules {
+			if rule == "dive" {
+				rules, items = rules[:k], rules[k+1:]
+				break
+			}
+		}
+
+		fe, ok := checkRules(v.Field(i), rules, prefix+name)
+		if !ok {
+			errs = append(errs, fe)
+			continue
+		}
+
+		if len(items) > 0 && v.Field(i).Kind() == reflect.Slice {
+			for k := 0; k < v.Field(i).Len(); k++ {
+				if fe, ok := checkRules(v.Field(i).Index(k), items, fmt.Sprintf("%s%s[%d]", prefix, name, k)); !ok {
+					errs = append(errs, fe)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+/**
+checks the rules in order, returns the error of the first failing one
+ */
+func checkRules(value reflect.Value, rules []string, field string) (FieldError, bool) {
+	for _, rule := range rules {
+		if rule == "" {
+			continue
+		}
+		if message, ok := checkRule(value, rule); !ok {
+			return FieldError{Field: field, Rule: strings.Split(rule, "=")[0], Message: message}, false
+		}
+	}
+
+	return FieldError{}, true
+}
+
+func checkRule(value reflect.Value, rule string) (string, bool) {
+	var name, param string = rule, ""
+	if i := strings.Index(rule, "="); i >= 0 {
+		name, param = rule[:i], rule[i+1:]
+	}
+
+	if name == "required" {
+		return "is required", !value.IsZero()
+	}
+
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "", true
+		}
+		value = value.Elem()
+	}
+
+	if value.IsZero() && value.Kind() == reflect.String {
+		return "", true
+	}
+
+	switch name {
+	case "min", "max":
+		bound, _ := strconv.ParseFloat(param, 64)
+		size, unit := measure(value)
+		if name == "min" && size < bound {
+			return fmt.Sprintf("must be at least %s%s", param, unit), false
+		}
+		if name == "max" && size > bound {
+			return fmt.Sprintf("must be at most %s%s", param, unit), false
+		}
+	case "oneof":
+		var values []string = strings.Split(param, " ")
+		return "must be one of " + strings.Join(values, ", "), containsString(values, fmt.Sprint(value.Interface()))
+	case "email":
+		return "must be an email address", emailPattern.MatchString(value.String())
+	case "date":
+		_, err := time.Parse(dateFormat, value.String())
+		return "must be a date as " + dateFormat, err == nil
+	case "url":
+		u, err := url.Parse(value.String())
+		return "must be an http or https url", err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+	}
+
+	return "", true
+}
+
+func measure(value reflect.Value) (float64, string) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(len([]rune(value.String()))), " characters"
+	case reflect.Slice:
+		return float64(value.Len()), " items"
+	case reflect.Int, reflect.Int64:
+		return float64(value.Int()), ""
+	case reflect.Float64:
+		return value.Float(), ""
+	}
+
+	return 0, ""
+}
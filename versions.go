@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+const versionKey contextKey = "api-version"
+
+// the unversioned paths are served as v1 until the sunset
+var (
+	legacyRoutesDeprecatedAt time.Time = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	legacyRoutesSunsetAt     time.Time = time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC)
+)
+
+/**
+json representation of the models in one version of the api, so the models can change
+without changing what the clients of a version get
+ */
+type Serializer interface {
+	User(u User) interface{}
+	Question(q Question) interface{}
+	Answer(a Answer) interface{}
+	SearchHit(h SearchHit) interface{}
+	FeedItem(i FeedItem) interface{}
+}
+
+/**
+version of the api mounted under /Name, WireTypes tell what the serializer turns the models into.
+during a migration the old and the new versions
+are both listed and mounted side by side, the old one gets DeprecatedAt and SunsetAt set
+so its responses carry the Deprecation and Sunset headers
+ */
+type APIVersion struct {
+	Name         string
+	Serializer   Serializer
+	WireTypes    map[reflect.Type]reflect.Type
+	DeprecatedAt *time.Time
+	SunsetAt     *time.Time
+}
+
+var apiVersions []APIVersion = []APIVersion{
+	{Name: "v1", Serializer: v1Serializer{}, WireTypes: v1WireTypes},
+}
+
+/**
+version of the request, the first version for the routes outside of the version groups
+ */
+func VersionFrom(r *http.Request) APIVersion {
+	version, ok := r.Context().Value(versionKey).(APIVersion)
+	if !ok {
+		return apiVersions[0]
+	}
+
+	return version
+}
+
+/**
+route wrapper of a version group: serves the routes in the version and marks the deprecated versions
+ */
+func WithVersion(version APIVersion) func(pattern string, h handler) handler {
+	return func(pattern string, h handler) handler {
+		return func(w http.ResponseWriter, r *http.Request) {
+			setDeprecationHeaders(w, version.DeprecatedAt, version.SunsetAt)
+			h(w, r.WithContext(context.WithValue(r.Context(), versionKey, version)))
+		}
+	}
+}
+
+/**
+marks a path kept for the migration, the response points to the path replacing it
+ */
+func Deprecated(successor string, h handler) handler {
+	return func(w http.ResponseWriter, r *http.Request) {
+		setDeprecationHeaders(w, &legacyRoutesDeprecatedAt, &legacyRoutesSunsetAt)
+		w.Header().Set("Link", "<"+successor+">; rel=\"successor-version\"")
+		h(w, r)
+	}
+}
+
+/**
+route wrapper of the unversioned resource paths, deprecated in favour of the same path in the latest version
+ */
+func DeprecatedUnversioned(pattern string, h handler) handler {
+	return Deprecated("/"+apiVersions[len(apiVersions)-1].Name+pattern, h)
+}
+
+/**
+Deprecation (RFC 9745) and Sunset (RFC 8594) headers
+ */
+func setDeprecationHeaders(w http.ResponseWriter, deprecatedAt *time.Time, sunsetAt *time.Time) {
+	if deprecatedAt != nil {
+		w.Header().Set("Deprecation", fmt.Sprintf("@%d", deprecatedAt.Unix()))
+	}
+
+	if sunsetAt != nil {
+		w.Header().Set("Sunset", sunsetAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+/**
+applies the serializer to the models in the response data, other values are left as they are
+ */
+func serialize(s Serializer, data interface{}) interface{} {
+	switch v := data.(type) {
+	case User:
+		return s.User(v)
+	case Question:
+		return s.Question(v)
+	case Answer:
+		return s.Answer(v)
+	case SearchHit:
+		return s.SearchHit(v)
+	case FeedItem:
+		return s.FeedItem(v)
+	case []User:
+		var items []interface{} = []interface{}{}
+		for _, item := range v {
+			items = append(items, s.User(item))
+		}
+		return items
+	case []Question:
+		var items []interface{} = []interface{}{}
+		for _, item := range v {
+			items = append(items, s.Question(item))
+		}
+		return items
+	case []Answer:
+		var items []interface{} = []interface{}{}
+		for _, item := range v {
+			items = append(items, s.Answer(item))
+		}
+		return items
+	case []SearchHit:
+		var items []interface{} = []interface{}{}
+		for _, item := range v {
+			items = append(items, s.SearchHit(item))
+		}
+		return items
+	case []FeedItem:
+		var items []interface{} = []interface{}{}
+		for _, item := range v {
+			items = append(items, s.FeedItem(item))
+		}
+		return items
+	case Page:
+		v.Items = serialize(s, v.Items)
+		return v
+	case PostQuestionResponse:
+		return map[string]interface{}{"id": v.Id, "duplicates": serialize(s, v.Duplicates)}
+	case map[string]interface{}:
+		var serialized map[string]interface{} = make(map[string]interface{})
+		for key, value := range v {
+			serialized[key] = serialize(s, value)
+		}
+		return serialized
+	}
+
+	return data
+}
+
+type v1Serializer struct{}
+
+var v1WireTypes map[reflect.Type]reflect.Type = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(User{}):      reflect.TypeOf(v1User{}),
+	reflect.TypeOf(Question{}):  reflect.TypeOf(v1Question{}),
+	reflect.TypeOf(Answer{}):    reflect.TypeOf(v1Answer{}),
+	reflect.TypeOf(SearchHit{}): reflect.TypeOf(v1SearchHit{}),
+	reflect.TypeOf(FeedItem{}):  reflect.TypeOf(v1FeedItem{}),
+}
+
+type v1User struct {
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	AnswerCount int64      `json:"answer_count"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+type v1Question struct {
+	Id               int64      `json:"id"`
+	Question         string     `json:"question"`
+	DuplicateOfId    int64      `json:"duplicate_of_id,omitempty"`
+	AcceptedAnswerId int64      `json:"accepted_answer_id,omitempty"`
+	Views            int64      `json:"views"`
+	AnswerCount      int64      `json:"answer_count"`
+	Score            int64      `json:"score"`
+	CreatedAt        time.Time  `json:"created_at"`
+	LastActivityAt   time.Time  `json:"last_activity_at"`
+	State            string     `json:"state"`
+	CloseReason      string     `json:"close_reason,omitempty"`
+	StateChangedAt   *time.Time `json:"state_changed_at,omitempty"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+	User             *v1User    `json:"user"`
+	Answers          []v1Answer `json:"answers"`
+	Tags             []string   `json:"tags"`
+}
+
+// v1 answers carry the id under the key "Id", as they always did
+type v1Answer struct {
+	Id        int64      `json:"Id"`
+	Answer    string     `json:"answer"`
+	Score     int64      `json:"score"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	User      *v1User    `json:"user"`
+}
+
+type v1SearchHit struct {
+	Kind       string      `json:"type"`
+	Id         int64       `json:"id"`
+	QuestionId int64       `json:"question_id"`
+	Score      float64     `json:"score"`
+	Question   *v1Question `json:"question,omitempty"`
+	Answer     *v1Answer   `json:"answer,omitempty"`
+}
+
+type v1FeedItem struct {
+	Kind       string      `json:"type"`
+	Id         int64       `json:"id"`
+	QuestionId int64       `json:"question_id"`
+	CreatedAt  time.Time   `json:"created_at"`
+	Question   *v1Question `json:"question,omitempty"`
+	Answer     *v1Answer   `json:"answer,omitempty"`
+}
+
+func (s v1Serializer) User(u User) interface{} {
+	return v1User{Name: u.Name, Email: u.Email, AnswerCount: u.AnswerCount, DeletedAt: u.DeletedAt}
+}
+
+func (s v1Serializer) Question(q Question) interface{} {
+	var v v1Question = v1Question{Id: q.Id, Question: q.Question, DuplicateOfId: q.DuplicateOfId, AcceptedAnswerId: q.AcceptedAnswerId,
+		Views: q.Views, AnswerCount: q.AnswerCount, Score: q.Score, CreatedAt: q.CreatedAt, LastActivityAt: q.LastActivityAt,
+		State: q.State, CloseReason: q.CloseReason, StateChangedAt: q.StateChangedAt, DeletedAt: q.DeletedAt, Tags: q.Tags}
+
+	if q.User != nil {
+		user := s.User(*q.User).(v1User)
+		v.User = &user
+	}
+
+	if q.Answers != nil {
+		v.Answers = []v1Answer{}
+		for _, answer := range q.Answers {
+			v.Answers = append(v.Answers, s.Answer(answer).(v1Answer))
+		}
+	}
+
+	return v
+}
+
+func (s v1Serializer) Answer(a Answer) interface{} {
+	var v v1Answer = v1Answer{Id: a.Id, Answer: a.Answer, Score: a.Score, CreatedAt: a.CreatedAt, DeletedAt: a.DeletedAt}
+
+	if a.User != nil {
+		user := s.User(*a.User).(v1User)
+		v.User = &user
+	}
+
+	return v
+}
+
+func (s v1Serializer) SearchHit(h SearchHit) interface{} {
+	var v v1SearchHit = v1SearchHit{Kind: h.Kind, Id: h.Id, QuestionId: h.QuestionId, Score: h.Score}
+
+	if h.Question != nil {
+		question := s.Question(*h.Question).(v1Question)
+		v.Question = &question
+	}
+
+	if h.Answer != nil {
+		answer := s.Answer(*h.Answer).(v1Answer)
+		v.Answer = &answer
+	}
+
+	return v
+}
+
+func (s v1Serializer) FeedItem(i FeedItem) interface{} {
+	var v v1FeedItem = v1FeedItem{Kind: i.Kind, Id: i.Id, QuestionId: i.QuestionId, CreatedAt: i.CreatedAt}
+
+	if i.Question != nil {
+		question := s.Question(*i.Question).(v1Question)
+		v.Question = &question
+	}
+
+	if i.Answer != nil {
+		answer := s.Answer(*i.Answer).(v1Answer)
+		v.Answer = &answer
+	}
+
+	return v
+}
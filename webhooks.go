@@ -0,0 +1,586 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+
+	webhookTimeout     = 10 * time.Second
+	webhookMaxAttempts = 8
+
+	// delay of the first retry, doubled by every further one
+	webhookRetryDelay = 30 * time.Second
+
+	// due retries are looked for this often
+	webhookRetryInterval = 15 * time.Second
+
+	// body of the receiver response kept in the delivery log
+	webhookResponseLimit = 255
+)
+
+// events a webhook can be registered for
+var webhookEvents []string = []string{EventQuestionPosted, EventQuestionStateChanged, EventAnswerPosted, EventAnswerRated, EventAnswerAccepted}
+
+var ErrWebhookAddress = errors.New("webhook address is not public")
+
+// networks besides the loopback, private and link-local ones the webhooks may not be posted to
+var webhookBlockedNetworks []*net.IPNet = parseNetworks("0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15")
+
+// whether a webhook may be posted to the address, replaced by the tests posting to local receivers
+var webhookAddressAllowed func(ip net.IP) bool = isPublicAddress
+
+/**
+client posting the deliveries. every connection is checked against webhookAddressAllowed after the
+name has been resolved, so a name resolving to an internal address later is refused as well, and
+redirects are not followed since they could lead anywhere
+ */
+var webhookClient *http.Client = &http.Client{
+	Timeout: webhookTimeout,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: webhookTimeout, Control: checkWebhookDial}).DialContext,
+		TLSHandshakeTimeout: webhookTimeout,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	var networks []*net.IPNet
+
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks
+}
+
+/**
+false for the loopback, private, link-local, multicast and unspecified addresses, 169.254.169.254
+of the cloud metadata services included
+ */
+func isPublicAddress(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+
+	for _, network := range webhookBlockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func checkWebhookDial(network string, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	var ip net.IP = net.ParseIP(host)
+	if ip == nil || !webhookAddressAllowed(ip) {
+		return fmt.Errorf("%w: %s", ErrWebhookAddress, host)
+	}
+
+	return nil
+}
+
+/**
+resolves the host of the url and refuses it when one of its addresses is not allowed
+ */
+func CheckWebhookURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if !webhookAddressAllowed(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrWebhookAddress, u.Hostname(), addr.IP)
+		}
+	}
+
+	return nil
+}
+
+/**
+endpoint the events are posted to, Events and Tags are comma separated. a webhook gets the events
+named in Events on questions having one of Tags, on every question when Tags is empty
+ */
+type Webhook struct {
+	Id        int64     `db:"id, primarykey, autoincrement" json:"id"`
+	UserId    int64     `db:"user_id, notnull" json:"-"`
+	Url       string    `db:"url, size:2048, notnull" json:"url"`
+	Secret    string    `db:"secret, size:64, notnull" json:"-"`
+	Events    string    `db:"events, size:255, notnull" json:"-"`
+	Tags      string    `db:"tags, size:1024" json:"-"`
+	CreatedAt time.Time `db:"created_at, notnull" json:"created_at"`
+}
+
+func (wh *Webhook) Load(id int64) error {
+	err := DBMap.SelectOne(wh, "SELECT * FROM webhook WHERE id = ?", id)
+
+	return err
+}
+
+func (wh Webhook) GetByUser(user User) ([]Webhook, error) {
+	var webhooks []Webhook = []Webhook{}
+	_, err := DBMap.Select(&webhooks, "SELECT * FROM webhook WHERE user_id = ? ORDER BY id", user.Id)
+
+	return webhooks, err
+}
+
+func (wh *Webhook) Save() error {
+	return Transaction(wh.SaveIn)
+}
+
+func (wh *Webhook) SaveIn(uow *UnitOfWork) error {
+	if wh.Id == 0 {
+		return uow.Executor().Insert(wh)
+	}
+
+	_, err := uow.Executor().Update(wh)
+
+	return err
+}
+
+/**
+deletes the webhook with its delivery log
+ */
+func (wh *Webhook) Delete() error {
+	return Transaction(func(uow *UnitOfWork) error {
+		_, err := uow.Executor().Exec("DELETE FROM webhook_delivery WHERE webhook_id = ?", wh.Id)
+		if err != nil {
+			return err
+		}
+
+		_, err = uow.Executor().Delete(wh)
+		return err
+	})
+}
+
+func (wh Webhook) EventList() []string {
+	return splitList(wh.Events)
+}
+
+func (wh Webhook) TagList() []string {
+	return splitList(wh.Tags)
+}
+
+func (wh Webhook) Matches(event string, tags []string) bool {
+	if !containsString(wh.EventList(), event) {
+		return false
+	}
+
+	if wh.Tags == "" {
+		return true
+	}
+
+	for _, tag := range wh.TagList() {
+		if containsString(tags, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/**
+webhook of the user with a generated secret unless one is given
+ */
+func NewWebhook(user User, url string, events []string, tags []string, secret string) Webhook {
+	if secret == "" {
+		var b []byte = make([]byte, 32)
+		rand.Read(b)
+		secret = hex.EncodeToString(b)
+	}
+
+	return Webhook{UserId: user.Id, Url: url, Secret: secret, Events: strings.Join(events, ","), Tags: strings.Join(NormalizeTags(tags), ","), CreatedAt: time.Now()}
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+
+	return strings.Split(s, ",")
+}
+
+/**
+hex HMAC-SHA256 of the timestamp and the body joined by a dot, sent as sha256=<signature>
+in the X-Questions-Signature header. receivers recompute it with the secret of the webhook
+and should refuse old timestamps to stop replays
+ */
+func SignWebhook(secret string, timestamp string, body []byte) string {
+	var mac hash.Hash = hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+/**
+one event posted to one webhook with the outcome of its last attempt. a pending delivery is attempted
+again at NextAttemptAt until it succeeds or webhookMaxAttempts are used up
+ */
+type WebhookDelivery struct {
+	Id            int64      `db:"id, primarykey, autoincrement" json:"id"`
+	WebhookId     int64      `db:"webhook_id, notnull" json:"webhook_id"`
+	Event         string     `db:"event, size:64, notnull" json:"event"`
+	Payload       string     `db:"payload, size:65535, notnull" json:"payload"`
+	Status        string     `db:"status, size:16, notnull" json:"status"`
+	Attempts      int64      `db:"attempts, notnull" json:"attempts"`
+	StatusCode    int64      `db:"status_code" json:"status_code,omitempty"`
+	Response      string     `db:"response, size:255" json:"response,omitempty"`
+	Error         string     `db:"error, size:255" json:"error,omitempty"`
+	NextAttemptAt *time.Time `db:"next_attempt_at" json:"next_attempt_at,omitempty"`
+	LastAttemptAt *time.Time `db:"last_attempt_at" json:"last_attempt_at,omitempty"`
+	CreatedAt     time.Time  `db:"created_at, notnull" json:"created_at"`
+}
+
+func (wd *WebhookDelivery) Load(id int64) error {
+	err := DBMap.SelectOne(wd, "SELECT * FROM webhook_delivery WHERE id = ?", id)
+
+	return err
+}
+
+func (wd WebhookDelivery) GetByWebhook(webhook Webhook, cursor string, limit int) ([]WebhookDelivery, PageInfo, error) {
+	var deliveries []WebhookDelivery = []WebhookDelivery{}
+
+	ids, info, err := keysetQuery{Table: "webhook_delivery", SortExpr: "webhook_delivery.id", SortKind: sortKindInt, Conditions: []string{"webhook_id = ?"}, Args: []interface{}{webhook.Id}, Desc: true, Cursor: cursor, Limit: limit, Count: true}.Ids()
+	if err != nil || len(ids) == 0 {
+		return deliveries, info, err
+	}
+
+	placeholders, args := inIds(ids)
+	_, err = DBMap.Select(&deliveries, fmt.Sprintf("SELECT * FROM webhook_delivery WHERE id IN (%s) ORDER BY FIELD(id, %s)", placeholders, placeholders), append(args, args...)...)
+
+	return deliveries, info, err
+}
+
+func (wd *WebhookDelivery) Save() error {
+	return Transaction(wd.SaveIn)
+}
+
+func (wd *WebhookDelivery) SaveIn(uow *UnitOfWork) error {
+	if wd.Id == 0 {
+		return uow.Executor().Insert(wd)
+	}
+
+	_, err := uow.Executor().Update(wd)
+
+	return err
+}
+
+/**
+pending delivery due right away, the column has second precision so a rounded up time would not be due yet
+ */
+func NewWebhookDelivery(webhook Webhook, event string, payload string) WebhookDelivery {
+	var now time.Time = time.Now()
+	var due time.Time = now.Truncate(time.Second)
+	return WebhookDelivery{WebhookId: webhook.Id, Event: event, Payload: payload, Status: DeliveryPending, NextAttemptAt: &due, CreatedAt: now}
+}
+
+/**
+takes the due delivery for one attempt by moving NextAttemptAt past the attempt timeout,
+false when it is not due or another worker has taken it
+ */
+func (wd *WebhookDelivery) claim() (bool, error) {
+	var now time.Time = time.Now()
+	var lease time.Time = now.Add(2 * webhookTimeout)
+
+	result, err := DBMap.Exec("UPDATE webhook_delivery SET next_attempt_at = ? WHERE id = ? AND status = ? AND next_attempt_at <= ?", lease, wd.Id, DeliveryPending, now)
+	if err != nil {
+		return false, err
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil || claimed == 0 {
+		return false, err
+	}
+
+	wd.NextAttemptAt = &lease
+
+	return true, nil
+}
+
+/**
+posts the payload to the webhook and logs the outcome
+ */
+func (wd *WebhookDelivery) Attempt(webhook Webhook) error {
+	wd.post(webhook)
+
+	return wd.Save()
+}
+
+/**
+posts the payload to the webhook and sets the outcome: a 2xx response succeeds it, anything else
+(a redirect included) schedules the next attempt after webhookRetryDelay doubled per attempt,
+or fails it for good
+ */
+func (wd *WebhookDelivery) post(webhook Webhook) {
+	var now time.Time = time.Now()
+	var timestamp string = strconv.FormatInt(now.Unix(), 10)
+
+	wd.Attempts++
+	wd.LastAttemptAt = &now
+	wd.StatusCode = 0
+	wd.Response = ""
+	wd.Error = ""
+
+	req, err := http.NewRequest(http.MethodPost, webhook.Url, bytes.NewReader([]byte(wd.Payload)))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "questions-webhooks")
+		req.Header.Set("X-Questions-Event", wd.Event)
+		req.Header.Set("X-Questions-Delivery", strconv.FormatInt(wd.Id, 10))
+		req.Header.Set("X-Questions-Timestamp", timestamp)
+		req.Header.Set("X-Questions-Signature", "sha256="+SignWebhook(webhook.Secret, timestamp, []byte(wd.Payload)))
+
+		var resp *http.Response
+		resp, err = webhookClient.Do(req)
+		if err == nil {
+			body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+			resp.Body.Close()
+			wd.StatusCode = int64(resp.StatusCode)
+			wd.Response = truncate(string(body), webhookResponseLimit)
+		}
+	}
+
+	if err == nil && wd.StatusCode >= 200 && wd.StatusCode < 300 {
+		wd.Status = DeliverySucceeded
+		wd.NextAttemptAt = nil
+		return
+	}
+
+	if err != nil {
+		wd.Error = truncate(err.Error(), 255)
+	} else {
+		wd.Error = fmt.Sprintf("receiver responded %d", wd.StatusCode)
+	}
+
+	if wd.Attempts >= webhookMaxAttempts {
+		wd.Status = DeliveryFailed
+		wd.NextAttemptAt = nil
+	} else {
+		var next time.Time = now.Add(webhookRetryDelay << uint(wd.Attempts-1))
+		wd.Status = DeliveryPending
+		wd.NextAttemptAt = &next
+	}
+}
+
+/**
+text of the receiver storable in the utf8 columns of at most length bytes: invalid bytes are replaced,
+the characters of 4 bytes the columns can not hold are dropped and the text is cut between characters
+ */
+func truncate(s string, length int) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToValidUTF8(s, "\uFFFD") {
+		if utf8.RuneLen(r) > 3 {
+			continue
+		}
+		if b.Len()+utf8.RuneLen(r) > length {
+			break
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+/**
+delivers the delivery if it can be claimed
+ */
+func deliverWebhook(delivery WebhookDelivery) error {
+	var webhook Webhook
+
+	claimed, err := delivery.claim()
+	if err != nil || !claimed {
+		return err
+	}
+
+	err = webhook.Load(delivery.WebhookId)
+	if err != nil {
+		return err
+	}
+
+	return delivery.Attempt(webhook)
+}
+
+/**
+attempts the pending deliveries whose retry is due, every interval
+ */
+func retryWebhooksPeriodically(interval time.Duration) {
+	for range time.Tick(interval) {
+		var due []WebhookDelivery
+
+		_, err := DBMap.Select(&due, "SELECT * FROM webhook_delivery WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT 100", DeliveryPending, time.Now())
+		if err != nil {
+			log.Println("loading due webhook deliveries failed:", err)
+			continue
+		}
+
+		for _, delivery := range due {
+			err = deliverWebhook(delivery)
+			if err != nil {
+				log.Printf("webhook delivery %d failed: %v", delivery.Id, err)
+			}
+		}
+	}
+}
+
+/**
+new delivery of the payload of an earlier one, attempted right away, the earlier one stays in the log
+ */
+func ReplayDelivery(webhook Webhook, delivery WebhookDelivery) (WebhookDelivery, error) {
+	var replay WebhookDelivery = NewWebhookDelivery(webhook, delivery.Event, delivery.Payload)
+
+	err := replay.Save()
+	if err != nil {
+		return replay, err
+	}
+
+	claimed, err := replay.claim()
+	if err != nil || !claimed {
+		return replay, err
+	}
+
+	err = replay.Attempt(webhook)
+
+	return replay, err
+}
+
+/**
+body posted to the webhooks: the event with the question and the answer it is about,
+serialized as in the latest api version
+ */
+type webhookPayload struct {
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Question   interface{} `json:"question"`
+	Answer     interface{} `json:"answer,omitempty"`
+}
+
+/**
+queues a delivery of the event to every webhook registered for it and attempts them
+ */
+func subscribeWebhooks(bus *EventBus) {
+	bus.SubscribeAsync("webhooks", func(e DomainEvent) error {
+		var questionId, answerId int64
+
+		switch event := e.(type) {
+		case QuestionPosted:
+			questionId = event.QuestionId
+		case QuestionStateChanged:
+			questionId = event.QuestionId
+		case AnswerPosted:
+			questionId, answerId = event.QuestionId, event.AnswerId
+		case AnswerRated:
+			questionId, answerId = event.QuestionId, event.AnswerId
+		case AnswerAccepted:
+			questionId, answerId = event.QuestionId, event.AnswerId
+		}
+
+		return enqueueWebhookDeliveries(e.EventName(), questionId, answerId)
+	}, webhookEvents...)
+}
+
+func enqueueWebhookDeliveries(event string, questionId int64, answerId int64) error {
+	var webhooks []Webhook
+	var question Question
+	var serializer Serializer = apiVersions[len(apiVersions)-1].Serializer
+
+	err := question.Load(questionId)
+	if err != nil {
+		return err
+	}
+
+	_, err = DBMap.Select(&webhooks, "SELECT * FROM webhook WHERE events LIKE ?", "%"+event+"%")
+	if err != nil {
+		return err
+	}
+
+	var matching []Webhook
+	for _, webhook := range webhooks {
+		if webhook.Matches(event, question.Tags) {
+			matching = append(matching, webhook)
+		}
+	}
+
+	if len(matching) == 0 {
+		return nil
+	}
+
+	question.AddUserData()
+	var payload webhookPayload = webhookPayload{Event: event, OccurredAt: time.Now(), Question: serializer.Question(question)}
+
+	if answerId != 0 {
+		var answer Answer
+		err = answer.Load(answerId)
+		if err != nil {
+			return err
+		}
+		answer.AddUserData()
+		payload.Answer = serializer.Answer(answer)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range matching {
+		var delivery WebhookDelivery = NewWebhookDelivery(webhook, event, string(data))
+		err = delivery.Save()
+		if err != nil {
+			return err
+		}
+
+		go func(delivery WebhookDelivery) {
+			err := deliverWebhook(delivery)
+			if err != nil {
+				log.Printf("webhook delivery %d failed: %v", delivery.Id, err)
+			}
+		}(delivery)
+	}
+
+	return nil
+}
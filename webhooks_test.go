@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+/**
+receiver of the webhook posts answering with the queued statuses, 200 once they are used up
+ */
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (rc *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, string(body))
+
+	var status int = http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+
+	if status == http.StatusFound {
+		http.Redirect(w, r, "/elsewhere", status)
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write([]byte(strings.Repeat("x", 2*webhookResponseLimit)))
+}
+
+func (rc *webhookReceiver) received() ([]*http.Request, []string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return append([]*http.Request{}, rc.requests...), append([]string{}, rc.bodies...)
+}
+
+/**
+receiver on the loopback, which the webhooks are allowed to post to for the test
+ */
+func newWebhookReceiver(t *testing.T, statuses ...int) (*webhookReceiver, Webhook) {
+	var receiver *webhookReceiver = &webhookReceiver{statuses: statuses}
+	var server *httptest.Server = httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	var allowed func(ip net.IP) bool = webhookAddressAllowed
+	webhookAddressAllowed = func(ip net.IP) bool { return true }
+	t.Cleanup(func() { webhookAddressAllowed = allowed })
+
+	return receiver, Webhook{Id: 1, Url: server.URL + "/hook", Secret: "0123456789abcdef", Events: EventAnswerPosted}
+}
+
+func TestDeliveryIsSigned(t *testing.T) {
+	receiver, webhook := newWebhookReceiver(t)
+	var delivery WebhookDelivery = NewWebhookDelivery(webhook, EventAnswerPosted, `{"event":"answer.posted"}`)
+	delivery.Id = 7
+
+	delivery.post(webhook)
+
+	requests, bodies := receiver.received()
+	if len(requests) != 1 {
+		t.Fatalf("received %d requests, want 1", len(requests))
+	}
+
+	var mac = hmac.New(sha256.New, []byte(webhook.Secret))
+	mac.Write([]byte(requests[0].Header.Get("X-Questions-Timestamp") + "." + bodies[0]))
+	var expected string = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if requests[0].Header.Get("X-Questions-Signature") != expected {
+		t.Errorf("signature %s, want %s", requests[0].Header.Get("X-Questions-Signature"), expected)
+	}
+	if requests[0].Header.Get("X-Questions-Event") != EventAnswerPosted || requests[0].Header.Get("X-Questions-Delivery") != "7" {
+		t.Errorf("headers %v", requests[0].Header)
+	}
+	if bodies[0] != delivery.Payload {
+		t.Errorf("body %s, want %s", bodies[0], delivery.Payload)
+	}
+	if delivery.Status != DeliverySucceeded || delivery.NextAttemptAt != nil || delivery.Attempts != 1 || delivery.StatusCode != http.StatusOK {
+		t.Errorf("delivery %+v, want succeeded at the first attempt", delivery)
+	}
+}
+
+func TestFailedDeliveriesBackOff(t *testing.T) {
+	var statuses []int
+	for i := 0; i < webhookMaxAttempts; i++ {
+		statuses = append(statuses, http.StatusInternalServerError)
+	}
+
+	receiver, webhook := newWebhookReceiver(t, statuses...)
+	var delivery WebhookDelivery = NewWebhookDelivery(webhook, EventAnswerPosted, "{}")
+
+	for attempt := 1; attempt < webhookMaxAttempts; attempt++ {
+		delivery.post(webhook)
+
+		if delivery.Status != DeliveryPending || delivery.NextAttemptAt == nil {
+			t.Fatalf("attempt %d: delivery %+v, want pending", attempt, delivery)
+		}
+
+		var delay time.Duration = delivery.NextAttemptAt.Sub(*delivery.LastAttemptAt)
+		if delay != webhookRetryDelay<<uint(attempt-1) {
+			t.Fatalf("attempt %d: retried after %v, want %v", attempt, delay, webhookRetryDelay<<uint(attempt-1))
+		}
+		if len(delivery.Response) != webhookResponseLimit || delivery.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("attempt %d: logged %d %d bytes", attempt, delivery.StatusCode, len(delivery.Response))
+		}
+	}
+
+	delivery.post(webhook)
+
+	if delivery.Status != DeliveryFailed || delivery.NextAttemptAt != nil || delivery.Attempts != webhookMaxAttempts {
+		t.Fatalf("delivery %+v, want failed after %d attempts", delivery, webhookMaxAttempts)
+	}
+	if requests, _ := receiver.received(); len(requests) != webhookMaxAttempts {
+		t.Fatalf("received %d requests, want %d", len(requests), webhookMaxAttempts)
+	}
+}
+
+func TestResponsesAreStorable(t *testing.T) {
+	var cases map[string]string = map[string]string{
+		"plain":                        "plain",
+		"bin\xff\xfeary":               "bin\uFFFDary",
+		"emoji \U0001F600 dropped":     "emoji  dropped",
+		strings.Repeat("x", 254) + "é": strings.Repeat("x", 254),
+		strings.Repeat("€", 100):       strings.Repeat("€", 85),
+	}
+
+	for text, expected := range cases {
+		var stored string = truncate(text, webhookResponseLimit)
+		if stored != expected || !utf8.ValidString(stored) || len(stored) > webhookResponseLimit {
+			t.Errorf("%q: stored %q, want %q", text, stored, expected)
+		}
+	}
+}
+
+func TestRedirectsAreNotFollowed(t *testing.T) {
+	receiver, webhook := newWebhookReceiver(t, http.StatusFound)
+	var delivery WebhookDelivery = NewWebhookDelivery(webhook, EventAnswerPosted, "{}")
+
+	delivery.post(webhook)
+
+	if requests, _ := receiver.received(); len(requests) != 1 {
+		t.Fatalf("received %d requests, want the redirect not to be followed", len(requests))
+	}
+	if delivery.Status != DeliveryPending || delivery.StatusCode != http.StatusFound {
+		t.Fatalf("delivery %+v, want a retry of the redirected attempt", delivery)
+	}
+}
+
+func TestInternalAddressesAreRefused(t *testing.T) {
+	for _, u := range []string{"http://127.0.0.1/hook", "http://localhost:8080/", "http://10.1.2.3/", "http://172.16.0.1/", "http://192.168.1.1/", "http://169.254.169.254/latest/meta-data/", "http://[::1]/", "http://[fe80::1]/", "http://0.0.0.0/", "http://100.64.0.1/"} {
+		err := CheckWebhookURL(context.Background(), u)
+		if !errors.Is(err, ErrWebhookAddress) {
+			t.Errorf("%s: got %v, want %v", u, err, ErrWebhookAddress)
+		}
+	}
+
+	err := CheckWebhookURL(context.Background(), "https://93.184.216.34/hook")
+	if err != nil {
+		t.Errorf("public address refused: %v", err)
+	}
+}
+
+func TestDialToInternalAddressIsRefused(t *testing.T) {
+	var receiver *webhookReceiver = &webhookReceiver{}
+	var server *httptest.Server = httptest.NewServer(receiver)
+	defer server.Close()
+
+	// registered while the name resolved to a public address, the receiver is on the loopback now
+	var webhook Webhook = Webhook{Id: 1, Url: server.URL, Secret: "0123456789abcdef"}
+	var delivery WebhookDelivery = NewWebhookDelivery(webhook, EventAnswerPosted, "{}")
+
+	delivery.post(webhook)
+
+	if requests, _ := receiver.received(); len(requests) != 0 {
+		t.Fatalf("received %d requests on the loopback", len(requests))
+	}
+	if delivery.Status != DeliveryPending || !strings.Contains(delivery.Error, ErrWebhookAddress.Error()) {
+		t.Fatalf("delivery %+v, want refused", delivery)
+	}
+}
+
+func TestReplayPostsTheSamePayloadAgain(t *testing.T) {
+	useTestDatabase(t)
+	truncateTables(t, "webhook_delivery", "webhook")
+
+	receiver, webhook := newWebhookReceiver(t, http.StatusInternalServerError)
+	webhook.Id = 0
+	if err := webhook.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	var original WebhookDelivery = NewWebhookDelivery(webhook, EventAnswerPosted, `{"event":"answer.posted"}`)
+	if err := original.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := deliverWebhook(original); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := ReplayDelivery(webhook, original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if replay.Id == original.Id || replay.Status != DeliverySucceeded || replay.Attempts != 1 {
+		t.Fatalf("replay %+v, want a new succeeded delivery", replay)
+	}
+
+	if err := original.Load(original.Id); err != nil {
+		t.Fatal(err)
+	}
+	if original.Status != DeliveryPending || original.Attempts != 1 {
+		t.Fatalf("original %+v, want it left as it was", original)
+	}
+
+	requests, bodies := receiver.received()
+	if len(requests) != 2 || bodies[0] != bodies[1] || requests[0].Header.Get("X-Questions-Delivery") == requests[1].Header.Get("X-Questions-Delivery") {
+		t.Fatalf("received %q, want the payload twice under two deliveries", bodies)
+	}
+}
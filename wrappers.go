@@ -1,42 +1,80 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"time"
 	"encoding/json"
-	"io/ioutil"
-	"errors"
 )
 
 type handler func(w http.ResponseWriter, r *http.Request)
 
-func GetOnly(h handler) handler {
+type contextKey string
+
+const (
+	authUserKey contextKey = "auth-user"
+	sessionKey  contextKey = "session"
+)
+
+/**
+access token the request has been authorized by
+ */
+type Session struct {
+	AccessToken string
+	ExpiresAt   time.Time
+}
+
+/**
+authorizes the request by its access token and puts the user and the session into the request context,
+the handlers read them by AuthUserFrom and SessionFrom instead of loading the user again
+ */
+func AuthUser(h handler) handler {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == "GET" {
-			h(w, r)
+		ctx, ok := authorizeToken(r.Context(), r.Header.Get("access-token"))
+		if !ok {
+			writeError(w, r, CodeAccessForbidden)
 			return
 		}
-		http.Error(w, getErrorByStatusCode(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		h(w, r.WithContext(ctx))
 	}
 }
 
-func PostOnly(h handler) handler {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == "POST" {
-			h(w, r)
-			return
-		}
-		http.Error(w, getErrorByStatusCode(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+/**
+puts the user of the access token and the session into the context, not ok for an unknown or expired token
+ */
+func authorizeToken(ctx context.Context, at string) (context.Context, bool) {
+	var user User
+
+	if len(at) != 64 || user.LoadCachedByAccessToken(at) != nil || user.TokenExpiration.Unix() < time.Now().Unix() {
+		return ctx, false
 	}
+
+	ctx = context.WithValue(ctx, authUserKey, user)
+	ctx = context.WithValue(ctx, sessionKey, Session{AccessToken: at, ExpiresAt: user.TokenExpiration})
+
+	return ctx, true
 }
 
-func AuthUser(h handler) handler {
+/**
+user authorized by AuthUser, not ok outside of it
+ */
+func AuthUserFrom(r *http.Request) (User, bool) {
+	user, ok := r.Context().Value(authUserKey).(User)
+	return user, ok
+}
+
+func SessionFrom(r *http.Request) (Session, bool) {
+	session, ok := r.Context().Value(sessionKey).(Session)
+	return session, ok
+}
+
+func ModeratorOnly(h handler) handler {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var at string = r.Header.Get("access-token")
-		var user User
+		user, ok := AuthUserFrom(r)
 
-		if len(at) != 64 || user.LoadByAccessToken(at) != nil || user.TokenExpiration.Unix() < time.Now().Unix() {
-			http.Error(w, getErrorByStatusCode(http.StatusForbidden), http.StatusForbidden)
+		if !ok || !user.Moderator {
+			writeError(w, r, CodeModeratorRequired)
 			return
 		}
 
@@ -44,63 +82,33 @@ func AuthUser(h handler) handler {
 	}
 }
 
-func getErrorByStatusCode(statusCode int) string {
-	switch statusCode {
-	case http.StatusMethodNotAllowed:
-		return "method is not allowed"
-	case http.StatusConflict:
-		return "entity has already been found"
-		break
-	case http.StatusInternalServerError:
-		return "internal error"
-		break
-	case http.StatusExpectationFailed:
-		return "expectations failed"
-		break
-	case http.StatusForbidden:
-		return "access forbidden"
-		break
+func writeQuestionStateError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case ErrInvalidTransition:
+		writeError(w, r, CodeInvalidTransition)
+	case ErrConflict:
+		writeError(w, r, CodeVoteExists)
+	case ErrInvalidCloseReason:
+		writeFieldError(w, r, CodeInvalidCloseReason, "reason")
+	case ErrMissingDuplicate:
+		writeFieldError(w, r, CodeMissingDuplicate, "duplicate_of")
+	default:
+		writeError(w, r, CodeInternal)
 	}
-
-	return "error message has not been specified"
 }
 
-func jsonResponse(w http.ResponseWriter, data interface{}) {
+/**
+writes the data as json in the representation of the version of the request
+ */
+func jsonResponse(w http.ResponseWriter, r *http.Request, data interface{}) {
 	var err error
 	var byteResp []byte
-	byteResp,err = json.Marshal(data)
+	byteResp,err = json.Marshal(serialize(VersionFrom(r).Serializer, data))
 	if err != nil{
-		http.Error(w, getErrorByStatusCode(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, r, CodeInternal)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 	w.Write(byteResp)
 }
-
-func getJsonData(r *http.Request) (interface{}, error) {
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		return nil, errors.New("missing request body")
-	}
-
-	var data interface{}
-
-	err = json.Unmarshal(body, &data)
-
-	if err != nil {
-		return nil, errors.New("bad request format")
-	}
-
-	return data, nil
-}
-
-func getAuthUser(r *http.Request) (User,error){
-	var at string = r.Header.Get("access-token")
-	var user User
-	var err error
-
-	err = user.LoadByAccessToken(at)
-
-	return user,err
-}